@@ -2,13 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
@@ -16,97 +16,359 @@ import (
 
 	"backend/config"
 	"backend/internal/api"
+	"backend/internal/auth"
+	"backend/internal/events"
+	"backend/internal/health"
+	"backend/internal/logging"
+	"backend/internal/mailer"
+	"backend/internal/metrics"
+	"backend/internal/oidc"
 	"backend/internal/store"
+	"backend/internal/telegram"
+	"backend/internal/tlscert"
+	"backend/internal/tracing"
+	"backend/internal/webhook"
+	"backend/migrations"
 )
 
-// mask скрывает большую часть пароля в логах
-func mask(s string) string {
-	if s == "" {
-		return ""
-	}
-	if len(s) <= 2 {
-		return "*"
-	}
-	return s[:2] + strings.Repeat("*", len(s)-2)
+// logDBConfig печатает итоговые параметры подключения к БД с маскированным паролем
+func logDBConfig(db config.DBConfig) {
+	slog.Info("DB config",
+		"host", db.Host, "port", db.Port, "user", db.User, "name", db.Name, "password", config.Mask(db.Password))
 }
 
-// logDBEnv печатает ключевые переменные окружения БД с маскированным паролем
-func logDBEnv() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	name := os.Getenv("DB_NAME")
-	pass := os.Getenv("DB_PASSWORD")
-
-	log.Printf("DB env -> DB_HOST=%q DB_PORT=%q DB_USER=%q DB_NAME=%q DB_PASSWORD=%q",
-		host, port, user, name, mask(pass))
+// fatal пишет ошибку в лог и завершает процесс
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
 
-// validateCfg — минимальная валидация критичных полей
+// validateCfg — проверка конфига при старте: сначала значения по отдельности,
+// затем то, что зависит от других пакетов и режима Gin
 func validateCfg(cfg *config.Config) error {
 	if cfg == nil {
 		return fmt.Errorf("nil config")
 	}
-	if cfg.Port == "" {
-		return fmt.Errorf("empty API port")
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
-	if cfg.DBHost == "" || cfg.DBPort == "" || cfg.DBUser == "" || cfg.DBName == "" {
-		return fmt.Errorf("incomplete DB config")
+	if cfg.Auth.JWTSecret == config.DefaultJWTSecret && gin.Mode() == gin.ReleaseMode {
+		return fmt.Errorf("default JWT_SECRET is not allowed in release mode")
 	}
-	if cfg.JWTSecret == "" {
-		return fmt.Errorf("empty JWT secret")
+	if cfg.Auth.JWTAlgorithm != auth.AlgRS256 && cfg.Auth.JWTAlgorithm != auth.AlgEdDSA {
+		return fmt.Errorf("auth.jwt_algorithm must be %s or %s", auth.AlgRS256, auth.AlgEdDSA)
+	}
+	if cfg.Auth.JWTKeyOverlap < auth.TokenTTL {
+		return fmt.Errorf("auth.jwt_key_overlap must be at least the token lifetime (%s)", auth.TokenTTL)
+	}
+	if _, err := api.NewCORSPolicy(cfg, gin.Mode() == gin.ReleaseMode); err != nil {
+		return fmt.Errorf("cors: %w", err)
+	}
+	if cfg.Auth.OIDC.Issuer != "" {
+		roles, err := oidc.ParseGroupRoles(cfg.Auth.OIDC.GroupRoles)
+		if err != nil {
+			return fmt.Errorf("auth.oidc.group_roles: %w", err)
+		}
+		if len(roles) == 0 {
+			return fmt.Errorf("auth.oidc.group_roles is required with auth.oidc.issuer")
+		}
 	}
 	return nil
 }
 
+// printConfig — режим `server config print [-config файл] [-set ключ=значение]`:
+// печатает итоговый конфиг с замаскированными секретами и проверяет его.
+func printConfig(args []string) int {
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := validateCfg(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid config:", err)
+		return 1
+	}
+	return 0
+}
+
+// healthcheck — режим `server healthcheck` для docker healthcheck:
+// в distroless-образе нет curl, поэтому бинарник сам опрашивает /healthz.
+// При включённом TLS сертификат выписан на внешнее имя, а не на 127.0.0.1, поэтому не проверяется.
+func healthcheck(port string, useTLS bool) int {
+	client := &http.Client{Timeout: 3 * time.Second}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Get(scheme + "://127.0.0.1:" + port + "/healthz")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, "healthz:", resp.Status)
+		return 1
+	}
+	return 0
+}
+
+// migrate — режим `server migrate [-config файл] [-set ключ=значение]`: применяет
+// новые миграции и завершается (для запуска с DB_MIGRATE=false, например отдельным шагом деплоя).
+func migrate(args []string) int {
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	logging.Setup(cfg.Log.Level)
+	s, err := store.NewStore(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "err", err)
+		return 1
+	}
+	defer s.Close()
+	applied, err := s.Migrate(context.Background(), migrations.FS)
+	if err != nil {
+		slog.Error("Migration failed", "err", err)
+		return 1
+	}
+	slog.Info("Database is up to date", "applied", len(applied), "version", store.SchemaVersion)
+	return 0
+}
+
 func main() {
-	// Конфиг: читает переменные окружения и при наличии .env — подхватывает
-	cfg := config.Load()
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		// Порт берётся из того же конфига (CONFIG_FILE, PORT), что и у сервера
+		cfg, err := config.Load(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(healthcheck(cfg.HTTP.Port, cfg.HTTP.TLSCertFile != ""))
+	}
+
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		os.Exit(printConfig(os.Args[3:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrate(os.Args[2:]))
+	}
 
-	// Логи ENV для БД (после Load, чтобы видеть итоговые значения)
-	logDBEnv()
+	// Конфиг: файл (-config), переменные окружения (и .env, если есть), флаги -set
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+
+	// JSON-логи; уровень из конфига
+	logging.Setup(cfg.Log.Level)
+
+	// Параметры БД (после Load, чтобы видеть итоговые значения)
+	logDBConfig(cfg.DB)
 
 	// Валидация конфига
 	if err := validateCfg(cfg); err != nil {
-		log.Fatalf("Invalid config: %v", err)
+		fatal("Invalid config", err)
 	}
 
+	// Трассировка (включается OTEL_EXPORTER_OTLP_ENDPOINT)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Log.OTLPEndpoint)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Tracing shutdown error", "err", err)
+		}
+	}()
+
 	// Режим Gin (используем GIN_MODE из окружения; по умолчанию debug)
 	if os.Getenv("GIN_MODE") == "" {
 		gin.SetMode(gin.DebugMode)
 	}
-	log.Printf("Gin mode: %s", gin.Mode())
+	slog.Info("Gin mode", "mode", gin.Mode())
 
 	// Подключение к БД
 	s, err := store.NewStore(cfg)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer func() {
 		if cerr := s.Close(); cerr != nil {
-			log.Printf("DB close error: %v", cerr)
+			slog.Error("DB close error", "err", cerr)
+		}
+	}()
+
+	// Миграции: реплики применяют их по очереди (advisory lock), остальные видят схему готовой
+	if cfg.DB.Migrate {
+		if _, err := s.Migrate(context.Background(), migrations.FS); err != nil {
+			fatal("Failed to apply migrations", err)
+		}
+	}
+
+	// Метрики пула соединений и бизнес-показатели для /metrics
+	metrics.RegisterDB(s.GetDB(), cfg.DB.Name)
+	metrics.RegisterGauge("traffic_lights_active", "Traffic lights in active status.", 2*time.Second,
+		func(ctx context.Context) (float64, error) {
+			n, err := s.CountActiveTrafficLights(ctx)
+			return float64(n), err
+		})
+
+	// Проверки готовности для /readyz
+	hc := health.New(2 * time.Second)
+	hc.AddCheck("database", s.Ping)
+	hc.AddCheck("migrations", func(ctx context.Context) error {
+		v, err := s.MigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if v < store.SchemaVersion {
+			return fmt.Errorf("schema version %d, expected %d", v, store.SchemaVersion)
 		}
+		return nil
+	})
+
+	// Ключи подписи JWT: первый ключ создаётся при первом запуске, дальше — ротация по расписанию
+	keys, err := auth.NewKeySet(context.Background(), s, cfg.Auth.JWTAlgorithm, cfg.Auth.JWTKeyRotation, cfg.Auth.JWTKeyOverlap)
+	if err != nil {
+		fatal("Failed to load JWT signing keys", err)
+	}
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	keysWorker := hc.Worker("jwt_keys")
+	go func() {
+		keysWorker.Running()
+		keys.Run(bgCtx, cfg.Scheduler.JWTKeyRefresh)
+		keysWorker.Stopped(nil)
+	}()
+
+	// Удаление закончившихся сессий и использованных токенов старше сроков хранения
+	cleanupWorker := hc.Worker("cleanup")
+	go func() {
+		cleanupWorker.Running()
+		s.RunCleanup(bgCtx, cfg.Storage, cfg.Scheduler.CleanupInterval)
+		cleanupWorker.Stopped(nil)
 	}()
 
+	// События об изменениях для /api/events: store публикует их после записи;
+	// с EVENTS_PG_NOTIFY — через NOTIFY, и в шину их передаёт LISTEN на каждой реплике
+	bus := events.NewBus(cfg.Events.History)
+	s.SetPublisher(bus)
+	if cfg.Events.PGNotify {
+		listenerWorker := hc.Worker("events_listener")
+		go func() {
+			listenerWorker.Stopped(s.ListenEvents(bgCtx, listenerWorker.Running))
+		}()
+	}
+
+	// Доставка вебхуков партнёрам: очередь в БД, поэтому работает на каждой реплике
+	webhooksWorker := hc.Worker("webhooks")
+	go func() {
+		webhooksWorker.Running()
+		webhook.NewDispatcher(s, cfg.Webhooks).Run(bgCtx, cfg.Scheduler.WebhookPoll)
+		webhooksWorker.Stopped(nil)
+	}()
+
+	// Telegram-бот для жителей: команды через long polling, уведомления — из шины событий.
+	// Telegram отдаёт обновления только одному получателю, поэтому бот работает на одной реплике —
+	// держателе advisory-блокировки; остальные ждут в резерве (воркер при этом тоже Running)
+	if cfg.Telegram.Token != "" {
+		client := telegram.NewAPIClient(cfg.Telegram.APIURL, cfg.Telegram.Token, cfg.Telegram.PollTimeout)
+		bot := telegram.NewBot(client, s, bus, cfg.HTTP.AppURL, cfg.Telegram.PollTimeout)
+		telegramWorker := hc.Worker("telegram")
+		go func() {
+			telegramWorker.Running()
+			s.RunExclusive(bgCtx, "telegram", store.TelegramBotLock, cfg.Telegram.LockRetry, bot.Run)
+			telegramWorker.Stopped(nil)
+		}()
+	}
+
+	// Почта для ссылок сброса пароля и приглашений
+	mail, err := mailer.New(mailer.Config{
+		Driver:   cfg.Mail.Driver,
+		Host:     cfg.Mail.SMTPHost,
+		Port:     cfg.Mail.SMTPPort,
+		Username: cfg.Mail.SMTPUsername,
+		Password: cfg.Mail.SMTPPassword,
+		From:     cfg.Mail.From,
+	})
+	if err != nil {
+		fatal("Invalid mail config", err)
+	}
+
 	// Роутер
-	r := gin.Default()
-	api.RegisterRoutes(r, s, cfg)
+	// Логирование запросов делает api.RequestLogger, поэтому без gin.Logger()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	api.RegisterRoutes(r, s, cfg, hc, keys, mail, bus)
 
 	// HTTP-сервер с таймаутами
 	srv := &http.Server{
-		Addr:           ":" + cfg.Port,
+		Addr:           ":" + cfg.HTTP.Port,
 		Handler:        r,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   30 * time.Second,
-		IdleTimeout:    60 * time.Second,
+		ReadTimeout:    cfg.HTTP.ReadTimeout,
+		WriteTimeout:   cfg.HTTP.WriteTimeout,
+		IdleTimeout:    cfg.HTTP.IdleTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
+	// Открытые потоки /api/events закрываются сразу, иначе Shutdown ждал бы их до таймаута
+	srv.RegisterOnShutdown(bus.Close)
+
+	// HTTPS и HTTP/2, если заданы сертификат и ключ; замена файлов подхватывается без перезапуска
+	if cfg.HTTP.TLSCertFile != "" {
+		certs, err := tlscert.New(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile)
+		if err != nil {
+			fatal("Failed to load TLS certificate", err)
+		}
+		srv.TLSConfig = certs.TLSConfig()
+		metrics.RegisterGauge("tls_cert_expiry_seconds", "Seconds until the served TLS certificate expires.", time.Second,
+			func(ctx context.Context) (float64, error) {
+				return time.Until(certs.NotAfter()).Seconds(), nil
+			})
+		tlsWorker := hc.Worker("tls_reload")
+		go func() {
+			tlsWorker.Running()
+			certs.Run(bgCtx, cfg.Scheduler.TLSReload)
+			tlsWorker.Stopped(nil)
+		}()
+	}
+
+	// Метрики Prometheus — на отдельном адресе, который не публикуется наружу
+	var metricsSrv *http.Server
+	if cfg.HTTP.MetricsAddr != "" {
+		metricsSrv = &http.Server{
+			Addr:              cfg.HTTP.MetricsAddr,
+			Handler:           metrics.Handler(),
+			ReadHeaderTimeout: cfg.HTTP.ReadTimeout,
+		}
+		go func() {
+			slog.Info("Metrics server starting", "addr", cfg.HTTP.MetricsAddr)
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal("Failed to start metrics server", err)
+			}
+		}()
+	}
 
 	// Грейсфул-шатдаун
 	go func() {
-		log.Printf("Server starting on port %s", cfg.Port)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal("Failed to start server:", err)
+		slog.Info("Server starting", "port", cfg.HTTP.Port, "tls", srv.TLSConfig != nil)
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Failed to start server", err)
 		}
 	}()
 
@@ -115,11 +377,21 @@ func main() {
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
 
-	log.Println("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Сначала /readyz отвечает 503, чтобы балансировщик перестал слать трафик
+	slog.Info("Draining before shutdown", "delay", cfg.HTTP.DrainDelay.String())
+	hc.Drain()
+	time.Sleep(cfg.HTTP.DrainDelay)
+
+	slog.Info("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+		slog.Error("Server shutdown error", "err", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			slog.Error("Metrics server shutdown error", "err", err)
+		}
 	}
-	log.Println("Server stopped gracefully")
+	slog.Info("Server stopped gracefully")
 }
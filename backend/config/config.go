@@ -2,50 +2,324 @@ package config
 
 import (
 	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
+// DefaultJWTSecret — значение JWT_SECRET по умолчанию; в release-режиме сервис с ним не стартует.
+const DefaultJWTSecret = "your-default-secret-key-change-in-production"
+
+// Config — итоговая конфигурация. Источники по возрастанию приоритета: значения по умолчанию,
+// файл YAML/TOML (-config или CONFIG_FILE), переменные окружения, флаги -set.
+// Тег yaml — ключ в файле и во флаге -set (секция.поле), env — переменная окружения,
+// secret — значение маскируется в `config print` и может читаться из файла (<ENV>_FILE).
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	JWTSecret  string
-	Port       string
-}
-
-// Load читает переменные окружения. Если .env присутствует рядом с бинарником/проектом — подхватывает.
-// В Docker рекомендуется полагаться на переменные из docker-compose.
-func Load() *Config {
-	// .env опционален: в Docker чаще всего не нужен
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
+	DB        DBConfig        `yaml:"db"`
+	HTTP      HTTPConfig      `yaml:"http"`
+	Log       LogConfig       `yaml:"log"`
+	Auth      AuthConfig      `yaml:"auth"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Mail      MailConfig      `yaml:"mail"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Events    EventsConfig    `yaml:"events"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Telegram  TelegramConfig  `yaml:"telegram"`
+}
 
-	cfg := &Config{
-		// В контейнере DB_HOST почти всегда "postgres" — имя сервиса в docker-compose
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5435"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "password"),
-		DBName:     getEnv("DB_NAME", "smolathon_db"),
-		JWTSecret:  getEnv("JWT_SECRET", "your-default-secret-key-change-in-production"),
-		Port:       getEnv("PORT", "8080"),
-	}
+// DBConfig — подключение к PostgreSQL, пул соединений и таймаут одного запроса.
+type DBConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	// SSLMode — disable, require, verify-ca или verify-full; SSLRootCert — CA сервера БД (PEM)
+	// для verify-ca/verify-full (с require при заданном CA проверка идёт как verify-ca).
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	SSLRootCert     string        `yaml:"sslrootcert" env:"DB_SSLROOTCERT"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	QueryTimeout    time.Duration `yaml:"query_timeout" env:"DB_QUERY_TIMEOUT"`
+	// Migrate — применять новые миграции при старте; без него — командой `server migrate`.
+	Migrate bool `yaml:"migrate" env:"DB_MIGRATE"`
+}
 
-	if cfg.JWTSecret == "your-default-secret-key-change-in-production" {
-		log.Println("WARNING: Using default JWT secret. Set JWT_SECRET environment variable in production!")
-	}
+// HTTPConfig — HTTP-сервер API.
+type HTTPConfig struct {
+	Port            string        `yaml:"port" env:"PORT"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	// DrainDelay — сколько /readyz отвечает 503 перед остановкой HTTP-сервера,
+	// чтобы балансировщик успел снять трафик.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+
+	// Лимит запросов к публичному API с одного IP (token bucket); 0 — без лимита.
+	RateLimitRPS   float64 `yaml:"rate_limit_rps" env:"RATE_LIMIT_RPS"`
+	RateLimitBurst int     `yaml:"rate_limit_burst" env:"RATE_LIMIT_BURST"`
+	// TrustedProxies — адреса и подсети (CIDR) балансировщиков, которым можно верить
+	// в X-Forwarded-For / X-Real-IP. Пусто — IP клиента берётся из соединения, заголовки
+	// игнорируются: иначе их подделкой обходятся лимиты по IP и блокировка входа.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	// MetricsAddr — отдельный адрес (host:port) для /metrics, чтобы счётчики входов,
+	// пул БД и трафик по маршрутам не были видны с публичного порта; пусто — /metrics выключен.
+	MetricsAddr string `yaml:"metrics_addr" env:"METRICS_ADDR"`
+
+	// AppURL — адрес фронтенда для ссылок в письмах (сброс пароля, приглашение).
+	AppURL string `yaml:"app_url" env:"APP_URL"`
+
+	// CacheTTL — сколько публичные данные (новости, услуги, статистика...) живут в кэше
+	// процесса, если их раньше не изменили через API; 0 — без кэша.
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"CACHE_TTL"`
+
+	// TLS: сертификат и ключ (PEM); оба пустые — сервер слушает обычный HTTP
+	// (например, за балансировщиком, который сам терминирует TLS).
+	TLSCertFile string `yaml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" env:"TLS_KEY_FILE"`
+	// HSTSMaxAge — Strict-Transport-Security для ответов по HTTPS; 0 — заголовок не отправляется.
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age" env:"HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" env:"HSTS_INCLUDE_SUBDOMAINS"`
+}
+
+// LogConfig — логи и трассировка.
+type LogConfig struct {
+	// Level — уровень JSON-логов: debug, info, warn, error.
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// OTLPEndpoint — адрес OTLP/HTTP-коллектора (host:port); пусто — трассировка выключена.
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+}
+
+// AuthConfig — токены, защита входа, пароли и SSO.
+type AuthConfig struct {
+	// JWTSecret подписывает служебные cookie; сами JWT подписываются ключами из БД.
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	// Подпись JWT: алгоритм (RS256 или EdDSA), срок жизни ключа до ротации и сколько
+	// заменённый ключ ещё принимается при проверке (не меньше срока жизни токена).
+	JWTAlgorithm   string        `yaml:"jwt_algorithm" env:"JWT_ALG"`
+	JWTKeyRotation time.Duration `yaml:"jwt_key_rotation" env:"JWT_KEY_ROTATION"`
+	JWTKeyOverlap  time.Duration `yaml:"jwt_key_overlap" env:"JWT_KEY_OVERLAP"`
+
+	// Защита входа: задержка после неудач (по IP и email) и блокировка учётной записи в БД.
+	LoginBackoffBase time.Duration `yaml:"login_backoff_base" env:"LOGIN_BACKOFF_BASE"`
+	LoginBackoffMax  time.Duration `yaml:"login_backoff_max" env:"LOGIN_BACKOFF_MAX"`
+	LoginMaxFailures int           `yaml:"login_max_failures" env:"LOGIN_MAX_FAILURES"`
+	LoginLockout     time.Duration `yaml:"login_lockout" env:"LOGIN_LOCKOUT"`
+	LoginLockoutMax  time.Duration `yaml:"login_lockout_max" env:"LOGIN_LOCKOUT_MAX"`
+
+	// Пароли: минимальная длина и сроки жизни ссылок сброса пароля и приглашения.
+	PasswordMinLength int           `yaml:"password_min_length" env:"PASSWORD_MIN_LENGTH"`
+	PasswordResetTTL  time.Duration `yaml:"password_reset_ttl" env:"PASSWORD_RESET_TTL"`
+	InviteTTL         time.Duration `yaml:"invite_ttl" env:"INVITE_TTL"`
+
+	OIDC OIDCConfig `yaml:"oidc"`
+}
+
+// OIDCConfig — вход сотрудников через OIDC-провайдер (authorization code + PKCE);
+// пустой Issuer — выключен.
+type OIDCConfig struct {
+	Issuer       string `yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID     string `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"`
+	RedirectURL  string `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	// GroupsClaim — claim ID-токена со списком групп.
+	GroupsClaim string `yaml:"groups_claim" env:"OIDC_GROUPS_CLAIM"`
+	// GroupRoles — соответствие групп ролям: "group=role,group2=role2";
+	// при нескольких совпадениях побеждает первая пара.
+	GroupRoles string `yaml:"group_roles" env:"OIDC_GROUP_ROLES"`
+	// SuccessURL — страница фронтенда, куда отправить браузер с токеном во фрагменте URL;
+	// пусто — callback отвечает JSON, как /api/auth/login.
+	SuccessURL string `yaml:"success_url" env:"OIDC_SUCCESS_URL"`
+}
+
+// CORSConfig — разрешённые источники (точные, с маской поддомена "https://*.example.ru" или "*"),
+// методы и заголовки. Пустой AllowedOrigins: в debug — localhost-адреса фронтенда,
+// в release — кросс-доменные запросы запрещены.
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// MailConfig — почта: Driver "log" (письма только в лог, для разработки) или "smtp".
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     string `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+}
+
+// StorageConfig — сколько хранятся записи, которые больше не действуют.
+type StorageConfig struct {
+	// SessionRetention — истёкшие и отозванные сессии (история входов).
+	SessionRetention time.Duration `yaml:"session_retention" env:"SESSION_RETENTION"`
+	// TokenRetention — использованные и истёкшие токены из писем.
+	TokenRetention time.Duration `yaml:"token_retention" env:"TOKEN_RETENTION"`
+	// WebhookDeliveryRetention — журнал доставленных и окончательно не доставленных вебхуков.
+	WebhookDeliveryRetention time.Duration `yaml:"webhook_delivery_retention" env:"WEBHOOK_DELIVERY_RETENTION"`
+}
+
+// SchedulerConfig — периодические фоновые задачи.
+type SchedulerConfig struct {
+	// JWTKeyRefresh — как часто ключи подписи перечитываются из БД и проверяется срок ротации.
+	JWTKeyRefresh time.Duration `yaml:"jwt_key_refresh" env:"JWT_KEY_REFRESH"`
+	// CleanupInterval — как часто удаляются записи старше сроков из StorageConfig.
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"CLEANUP_INTERVAL"`
+	// TLSReload — как часто проверять, не заменены ли файлы сертификата и ключа.
+	TLSReload time.Duration `yaml:"tls_reload" env:"TLS_RELOAD_INTERVAL"`
+	// WebhookPoll — как часто проверять очередь доставок вебхуков.
+	WebhookPoll time.Duration `yaml:"webhook_poll" env:"WEBHOOK_POLL_INTERVAL"`
+}
+
+// EventsConfig — поток событий /api/events.
+type EventsConfig struct {
+	// History — сколько последних событий хранится для продолжения потока по Last-Event-ID.
+	History int `yaml:"history" env:"EVENTS_HISTORY"`
+	// Heartbeat — интервал пустых комментариев в потоке, чтобы прокси не закрывали соединение без данных.
+	Heartbeat time.Duration `yaml:"heartbeat" env:"EVENTS_HEARTBEAT"`
+	// PGNotify — рассылать события через PostgreSQL LISTEN/NOTIFY, чтобы их видели клиенты всех реплик;
+	// без него каждая реплика рассылает только свои изменения.
+	PGNotify bool `yaml:"pg_notify" env:"EVENTS_PG_NOTIFY"`
+}
+
+// WebhooksConfig — доставка вебхуков: таймаут запроса к партнёру и повторы.
+// Задержка перед n-й повторной попыткой — BackoffBase * 2^(n-1), но не больше BackoffMax;
+// после MaxAttempts неудач доставка помечается failed (её можно повторить вручную).
+type WebhooksConfig struct {
+	Timeout     time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	MaxAttempts int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase time.Duration `yaml:"backoff_base" env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env:"WEBHOOK_BACKOFF_MAX"`
+}
 
-	return cfg
+// TelegramConfig — бот уведомлений для жителей; пустой Token — бот выключен.
+// Токен можно задавать на всех репликах: Telegram отдаёт обновления одному получателю,
+// поэтому бот работает только на реплике, взявшей advisory-блокировку в БД, а остальные
+// раз в LockRetry пытаются её взять и подхватывают бота, если держатель остановился.
+type TelegramConfig struct {
+	Token string `yaml:"token" env:"TELEGRAM_BOT_TOKEN" secret:"true"`
+	// APIURL — адрес Bot API: свой сервер Bot API или локальная заглушка для проверки.
+	APIURL string `yaml:"api_url" env:"TELEGRAM_API_URL"`
+	// PollTimeout — сколько Telegram держит запрос getUpdates, если новых сообщений нет.
+	PollTimeout time.Duration `yaml:"poll_timeout" env:"TELEGRAM_POLL_TIMEOUT"`
+	// LockRetry — как часто резервная реплика пытается стать ботом и как часто
+	// действующая проверяет, что соединение с блокировкой живо.
+	LockRetry time.Duration `yaml:"lock_retry" env:"TELEGRAM_LOCK_RETRY"`
 }
 
-func getEnv(key, defaultVal string) string {
-	if v, ok := os.LookupEnv(key); ok && v != "" {
-		return v
+// Default — значения по умолчанию (для локального запуска).
+func Default() *Config {
+	return &Config{
+		DB: DBConfig{
+			// В контейнере DB_HOST почти всегда "postgres" — имя сервиса в docker-compose
+			Host:            "localhost",
+			Port:            "5435",
+			User:            "postgres",
+			Password:        "password",
+			Name:            "smolathon_db",
+			SSLMode:         "disable",
+			MaxOpenConns:    10,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			QueryTimeout:    5 * time.Second,
+			Migrate:         true,
+		},
+		HTTP: HTTPConfig{
+			Port:            "8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			DrainDelay:      5 * time.Second,
+			RateLimitRPS:    10,
+			RateLimitBurst:  20,
+			MetricsAddr:     ":9090",
+			AppURL:          "http://localhost:3000",
+			HSTSMaxAge:      180 * 24 * time.Hour,
+			CacheTTL:        30 * time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Auth: AuthConfig{
+			JWTSecret:         DefaultJWTSecret,
+			JWTAlgorithm:      "RS256",
+			JWTKeyRotation:    30 * 24 * time.Hour,
+			JWTKeyOverlap:     48 * time.Hour,
+			LoginBackoffBase:  time.Second,
+			LoginBackoffMax:   time.Minute,
+			LoginMaxFailures:  5,
+			LoginLockout:      15 * time.Minute,
+			LoginLockoutMax:   24 * time.Hour,
+			PasswordMinLength: 10,
+			PasswordResetTTL:  time.Hour,
+			InviteTTL:         72 * time.Hour,
+			OIDC: OIDCConfig{
+				GroupsClaim: "groups",
+			},
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{
+				"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With",
+				"If-Match", "If-None-Match", "X-Request-ID", "traceparent", "tracestate", "Last-Event-ID",
+			},
+			ExposedHeaders:   []string{"ETag", "X-Request-ID", "Retry-After"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			SMTPHost: "localhost",
+			SMTPPort: "1025",
+			From:     "ЦОДД Смоленск <noreply@localhost>",
+		},
+		Storage: StorageConfig{
+			SessionRetention:         30 * 24 * time.Hour,
+			TokenRetention:           30 * 24 * time.Hour,
+			WebhookDeliveryRetention: 30 * 24 * time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			JWTKeyRefresh:   5 * time.Minute,
+			CleanupInterval: time.Hour,
+			TLSReload:       30 * time.Second,
+			WebhookPoll:     5 * time.Second,
+		},
+		Events: EventsConfig{
+			History:   1000,
+			Heartbeat: 25 * time.Second,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:     10 * time.Second,
+			MaxAttempts: 8,
+			BackoffBase: 30 * time.Second,
+			BackoffMax:  6 * time.Hour,
+		},
+		Telegram: TelegramConfig{
+			APIURL:      "https://api.telegram.org",
+			PollTimeout: 30 * time.Second,
+			LockRetry:   15 * time.Second,
+		},
+	}
+}
+
+// Load собирает конфигурацию из всех источников; args — аргументы командной строки
+// (-config путь, -set ключ=значение). .env рядом с бинарником/проектом подхватывается, если есть;
+// в Docker рекомендуется полагаться на переменные из docker-compose.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	if err := cfg.load(args); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.JWTSecret == DefaultJWTSecret {
+		log.Println("WARNING: Using default JWT secret. Set JWT_SECRET environment variable in production!")
 	}
-	return defaultVal
+	return cfg, nil
 }
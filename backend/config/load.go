@@ -0,0 +1,293 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// secretFileSuffix — суффикс ключа и переменной, значение которых читается из файла
+// (Docker secrets): DB_PASSWORD_FILE=/run/secrets/db_password, db.password_file: ...
+const secretFileSuffix = "_file"
+
+// field — одно поле конфигурации.
+type field struct {
+	key    string // "db.password"
+	env    string // "DB_PASSWORD"
+	secret bool
+	value  reflect.Value
+}
+
+// fields — все поля cfg в порядке объявления.
+func (c *Config) fields() []field {
+	var res []field
+	walk(reflect.ValueOf(c).Elem(), "", &res)
+	return res
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func walk(v reflect.Value, prefix string, res *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := prefix + sf.Tag.Get("yaml")
+		if sf.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), key+".", res)
+			continue
+		}
+		*res = append(*res, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+}
+
+func (c *Config) load(args []string) error {
+	fs := flag.NewFlagSet("backend", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	var sets []string
+	fs.Func("set", "override a config key: -set db.host=postgres (repeatable)", func(s string) error {
+		sets = append(sets, s)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	// .env опционален: в Docker чаще всего не нужен
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	index := make(map[string]field)
+	for _, f := range c.fields() {
+		index[f.key] = f
+	}
+
+	if *configFile != "" {
+		if err := loadFile(*configFile, index); err != nil {
+			return err
+		}
+	}
+	for _, f := range c.fields() {
+		if err := loadEnv(f); err != nil {
+			return err
+		}
+	}
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("-set %q: expected key=value", s)
+		}
+		if err := setKey(index, key, value); err != nil {
+			return fmt.Errorf("-set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// loadFile читает YAML (.yaml, .yml) или TOML (.toml). Неизвестные ключи — ошибка,
+// чтобы опечатка не превращалась в молча применённое значение по умолчанию.
+func loadFile(path string, index map[string]field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	raw := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := applyMap(raw, "", index); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyMap(m map[string]any, prefix string, index map[string]field) error {
+	for k, v := range m {
+		key := prefix + k
+		if sub, ok := v.(map[string]any); ok {
+			if err := applyMap(sub, key+".", index); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := setKey(index, key, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setKey задаёт поле по ключу; для секретов ключ с суффиксом _file задаёт путь к файлу.
+func setKey(index map[string]field, key string, raw any) error {
+	if f, ok := index[key]; ok {
+		return setValue(f.value, raw)
+	}
+	if base, ok := strings.CutSuffix(key, secretFileSuffix); ok {
+		if f, ok := index[base]; ok && f.secret {
+			secret, err := readSecret(fmt.Sprint(raw))
+			if err != nil {
+				return err
+			}
+			return setValue(f.value, secret)
+		}
+	}
+	return fmt.Errorf("unknown key")
+}
+
+// loadEnv применяет переменную окружения поля или <ENV>_FILE для секретов.
+// Пустая переменная считается незаданной.
+func loadEnv(f field) error {
+	if f.env == "" {
+		return nil
+	}
+	value := os.Getenv(f.env)
+	if f.secret {
+		fileEnv := f.env + strings.ToUpper(secretFileSuffix)
+		if path := os.Getenv(fileEnv); path != "" {
+			if value != "" {
+				return fmt.Errorf("both %s and %s are set", f.env, fileEnv)
+			}
+			secret, err := readSecret(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fileEnv, err)
+			}
+			value = secret
+		}
+	}
+	if value == "" {
+		return nil
+	}
+	if err := setValue(f.value, value); err != nil {
+		return fmt.Errorf("%s=%q: %w", f.env, value, err)
+	}
+	return nil
+}
+
+// readSecret читает секрет из файла, отбрасывая завершающий перевод строки.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// setValue разбирает значение из файла, переменной или флага в поле нужного типа.
+// Списки принимаются как массив или строка через запятую, длительности — как "30s", "1h".
+func setValue(v reflect.Value, raw any) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(fmt.Sprint(raw))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprint(raw))
+	case reflect.Int:
+		n, err := strconv.Atoi(fmt.Sprint(raw))
+		if err != nil {
+			return fmt.Errorf("not an integer")
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(fmt.Sprint(raw), 64)
+		if err != nil {
+			return fmt.Errorf("not a number")
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(fmt.Sprint(raw))
+		if err != nil {
+			return fmt.Errorf("not a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		switch raw := raw.(type) {
+		case []any:
+			for _, item := range raw {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			items = splitList(fmt.Sprint(raw))
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList разбирает список через запятую; пустые элементы отбрасываются.
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Mask скрывает большую часть секрета: для логов и `config print`.
+func Mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return s[:2] + strings.Repeat("*", 6)
+}
+
+// Masked — копия конфигурации, в которой секреты заменены на Mask.
+func (c *Config) Masked() *Config {
+	masked := *c
+	for _, f := range masked.fields() {
+		if f.secret {
+			f.value.SetString(Mask(f.value.String()))
+		}
+	}
+	return &masked
+}
+
+// Print пишет итоговую конфигурацию в YAML с замаскированными секретами.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Masked()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Validate проверяет значения, которые можно проверить без других пакетов,
+// и возвращает все найденные ошибки сразу.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.DB.Host != "" && c.DB.User != "" && c.DB.Name != "", "db: host, user and name are required")
+	check(validPort(c.DB.Port), "db.port: invalid port %q", c.DB.Port)
+	check(c.DB.MaxOpenConns >= 1, "db.max_open_conns must be positive")
+	check(c.DB.MaxIdleConns >= 0 && c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns must be between 0 and db.max_open_conns")
+	switch c.DB.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		check(false, "db.sslmode must be disable, require, verify-ca or verify-full")
+	}
+	check(c.DB.SSLRootCert == "" || c.DB.SSLMode != "disable", "db.sslrootcert requires db.sslmode other than disable")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(c.DB.QueryTimeout >= 0, "db.query_timeout must not be negative")
+
+	check(validPort(c.HTTP.Port), "http.port: invalid port %q", c.HTTP.Port)
+	check(c.HTTP.ReadTimeout > 0 && c.HTTP.WriteTimeout > 0 && c.HTTP.IdleTimeout > 0,
+		"http: read_timeout, write_timeout and idle_timeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.DrainDelay >= 0, "http.drain_delay must not be negative")
+	check(c.HTTP.RateLimitRPS >= 0 && c.HTTP.RateLimitBurst >= 0, "http: rate_limit_rps and rate_limit_burst must not be negative")
+	for _, p := range c.HTTP.TrustedProxies {
+		_, _, err := net.ParseCIDR(p)
+		check(err == nil || net.ParseIP(p) != nil, "http.trusted_proxies: %q is neither an IP nor a CIDR", p)
+	}
+	if c.HTTP.MetricsAddr != "" {
+		_, port, err := net.SplitHostPort(c.HTTP.MetricsAddr)
+		check(err == nil && validPort(port), "http.metrics_addr: expected host:port, got %q", c.HTTP.MetricsAddr)
+		check(port != c.HTTP.Port, "http.metrics_addr must not use the API port %s", c.HTTP.Port)
+	}
+	check(validURL(c.HTTP.AppURL), "http.app_url: invalid URL %q", c.HTTP.AppURL)
+	check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""), "http: tls_cert_file and tls_key_file must be set together")
+	check(c.HTTP.CacheTTL >= 0, "http.cache_ttl must not be negative")
+	check(c.HTTP.HSTSMaxAge >= 0, "http.hsts_max_age must not be negative")
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level must be debug, info, warn or error")
+	}
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret is required")
+	check(c.Auth.JWTKeyRotation > 0, "auth.jwt_key_rotation must be positive")
+	check(c.Auth.LoginBackoffBase >= 0 && c.Auth.LoginBackoffMax >= c.Auth.LoginBackoffBase,
+		"auth: login_backoff_max must not be less than login_backoff_base")
+	check(c.Auth.LoginMaxFailures >= 1, "auth.login_max_failures must be positive")
+	check(c.Auth.LoginLockout > 0 && c.Auth.LoginLockoutMax >= c.Auth.LoginLockout,
+		"auth: login_lockout must be positive and not exceed login_lockout_max")
+	check(c.Auth.PasswordMinLength >= 8, "auth.password_min_length must be at least 8")
+	check(c.Auth.PasswordResetTTL > 0 && c.Auth.InviteTTL > 0, "auth: password_reset_ttl and invite_ttl must be positive")
+	if o := c.Auth.OIDC; o.Issuer != "" {
+		check(validURL(o.Issuer), "auth.oidc.issuer: invalid URL %q", o.Issuer)
+		check(o.ClientID != "" && o.RedirectURL != "", "auth.oidc: client_id and redirect_url are required with issuer")
+		check(o.RedirectURL == "" || validURL(o.RedirectURL), "auth.oidc.redirect_url: invalid URL %q", o.RedirectURL)
+		check(o.GroupsClaim != "", "auth.oidc.groups_claim is required with issuer")
+	}
+
+	check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+	check(len(c.CORS.AllowedMethods) > 0, "cors.allowed_methods must not be empty")
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		check(c.Mail.SMTPHost != "", "mail.smtp_host is required with the smtp driver")
+		check(validPort(c.Mail.SMTPPort), "mail.smtp_port: invalid port %q", c.Mail.SMTPPort)
+	default:
+		check(false, "mail.driver must be log or smtp")
+	}
+	check(c.Mail.From != "", "mail.from is required")
+
+	check(c.Storage.SessionRetention >= 0 && c.Storage.TokenRetention >= 0 && c.Storage.WebhookDeliveryRetention >= 0,
+		"storage: retention must not be negative")
+	check(c.Scheduler.JWTKeyRefresh > 0, "scheduler.jwt_key_refresh must be positive")
+	check(c.Scheduler.CleanupInterval > 0, "scheduler.cleanup_interval must be positive")
+	check(c.Scheduler.TLSReload > 0, "scheduler.tls_reload must be positive")
+	check(c.Scheduler.WebhookPoll > 0, "scheduler.webhook_poll must be positive")
+
+	check(c.Events.History >= 0, "events.history must not be negative")
+	check(c.Events.Heartbeat > 0, "events.heartbeat must be positive")
+
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.MaxAttempts >= 1, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.BackoffBase > 0 && c.Webhooks.BackoffMax >= c.Webhooks.BackoffBase,
+		"webhooks: backoff_base must be positive and not exceed backoff_max")
+
+	if c.Telegram.Token != "" {
+		check(validURL(c.Telegram.APIURL), "telegram.api_url: invalid URL %q", c.Telegram.APIURL)
+		check(c.Telegram.PollTimeout >= time.Second && c.Telegram.PollTimeout <= time.Minute,
+			"telegram.poll_timeout must be between 1s and 1m")
+		check(c.Telegram.LockRetry >= time.Second, "telegram.lock_retry must be at least 1s")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestMetricsAddrValidation(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{":9090", false},
+		{"127.0.0.1:9090", false},
+		{"", false},
+		{"9090", true},
+		{":8080", true}, // порт API
+		{":0", true},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.HTTP.MetricsAddr = tt.addr
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("metrics_addr %q: err = %v, wantErr %v", tt.addr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTrustedProxiesValidation(t *testing.T) {
+	tests := []struct {
+		proxies []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"10.0.0.0/8", "192.168.1.10", "::1"}, false},
+		{[]string{"10.0.0.0/33"}, true},
+		{[]string{"proxy.local"}, true},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.HTTP.TrustedProxies = tt.proxies
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("trusted_proxies %v: err = %v, wantErr %v", tt.proxies, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/store"
+)
+
+// etag формирует ETag из версии записи.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseETag разбирает значение If-Match вида "3" или W/"3".
+func parseETag(s string) (int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "W/")
+	return strconv.Atoi(strings.Trim(s, `"`))
+}
+
+// requireVersion возвращает версию, которую клиент ожидает перезаписать.
+// Источник — заголовок If-Match, а если его нет — поле version из тела.
+// Без обоих отвечает 428, чтобы слепые перезаписи были невозможны.
+func requireVersion(c *gin.Context, bodyVersion *int) (int, bool) {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		v, err := parseETag(ifMatch)
+		if err != nil {
+			c.Error(apperr.BadRequest("Invalid If-Match header"))
+			return 0, false
+		}
+		return v, true
+	}
+	if bodyVersion != nil {
+		return *bodyVersion, true
+	}
+	c.Error(apperr.New(apperr.KindPreconditionRequired, "If-Match header or version field required"))
+	return 0, false
+}
+
+// respondUpdateError передаёт ошибку Patch* в ErrorHandler.
+// При конфликте версий добавляет актуальное состояние записи (и его ETag),
+// чтобы админка могла показать пользователю окно слияния.
+func respondUpdateError(c *gin.Context, err error, failed string, current func() (interface{}, int, error)) {
+	if errors.Is(err, store.ErrVersionConflict) {
+		if cur, version, cerr := current(); cerr == nil {
+			c.Header("ETag", etag(version))
+			err = store.ErrVersionConflict.With("current", cur)
+		}
+	}
+	c.Error(apperr.Wrap(err, failed))
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/config"
+)
+
+// devOrigins — адреса фронтенда при локальной разработке; разрешены в debug,
+// если CORS_ALLOWED_ORIGINS не задан.
+var devOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://localhost:8080",
+	"http://127.0.0.1:3000",
+	"http://127.0.0.1:5173",
+	"http://127.0.0.1:8080",
+}
+
+// originPattern — разрешённый источник: точный "https://app.example.ru"
+// или с маской поддомена "https://*.example.ru" (сам example.ru под маску не попадает).
+type originPattern struct {
+	exact  string
+	prefix string // "https://" для маски
+	suffix string // ".example.ru[:port]" для маски
+}
+
+func (p originPattern) match(origin string) bool {
+	if p.exact != "" {
+		return origin == p.exact
+	}
+	if len(origin) <= len(p.prefix)+len(p.suffix) ||
+		!strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	sub := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	if sub[0] == '.' || sub[len(sub)-1] == '.' {
+		return false
+	}
+	for _, r := range sub {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func parseOriginPattern(s string) (originPattern, error) {
+	s = strings.ToLower(strings.TrimRight(s, "/"))
+	scheme, host, ok := strings.Cut(s, "://")
+	if !ok || (scheme != "http" && scheme != "https") || host == "" || strings.ContainsAny(host, "/?#@") {
+		return originPattern{}, fmt.Errorf("invalid origin %q: expected scheme://host[:port]", s)
+	}
+	if !strings.Contains(host, "*") {
+		return originPattern{exact: s}, nil
+	}
+	rest, ok := strings.CutPrefix(host, "*.")
+	if !ok || rest == "" || strings.Contains(rest, "*") {
+		return originPattern{}, fmt.Errorf("invalid origin %q: wildcard is only allowed as the leftmost label (*.example.ru)", s)
+	}
+	return originPattern{prefix: scheme + "://", suffix: "." + rest}, nil
+}
+
+// CORSPolicy — правила CORS из конфигурации.
+type CORSPolicy struct {
+	anyOrigin   bool // "*": любой источник, но без credentials
+	origins     []originPattern
+	credentials bool
+
+	methods map[string]bool
+	headers map[string]bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// NewCORSPolicy собирает политику из cfg. В release разрешены только явно заданные
+// источники и "*" запрещён; в debug без настроек разрешены localhost-адреса фронтенда.
+func NewCORSPolicy(cfg *config.Config, release bool) (*CORSPolicy, error) {
+	origins := cfg.CORS.AllowedOrigins
+	if len(origins) == 0 && !release {
+		origins = devOrigins
+	}
+
+	p := &CORSPolicy{
+		credentials:   cfg.CORS.AllowCredentials,
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		exposeHeaders: strings.Join(cfg.CORS.ExposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(cfg.CORS.MaxAge.Seconds())),
+	}
+	for _, o := range origins {
+		if o == "*" {
+			if release {
+				return nil, fmt.Errorf("wildcard origin \"*\" is not allowed in release mode")
+			}
+			p.anyOrigin = true
+			continue
+		}
+		pattern, err := parseOriginPattern(o)
+		if err != nil {
+			return nil, err
+		}
+		p.origins = append(p.origins, pattern)
+	}
+	if cfg.CORS.MaxAge < 0 {
+		return nil, fmt.Errorf("negative max age")
+	}
+
+	methods := make([]string, 0, len(cfg.CORS.AllowedMethods))
+	for _, m := range cfg.CORS.AllowedMethods {
+		m = strings.ToUpper(m)
+		if !p.methods[m] {
+			p.methods[m] = true
+			methods = append(methods, m)
+		}
+	}
+	for _, h := range cfg.CORS.AllowedHeaders {
+		p.headers[strings.ToLower(h)] = true
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+	p.allowHeaders = strings.Join(cfg.CORS.AllowedHeaders, ", ")
+	return p, nil
+}
+
+// allowOrigin — значение Access-Control-Allow-Origin для origin или "", если он не разрешён.
+func (p *CORSPolicy) allowOrigin(origin string) string {
+	lower := strings.ToLower(origin)
+	for _, o := range p.origins {
+		if o.match(lower) {
+			return origin
+		}
+	}
+	if p.anyOrigin {
+		return "*"
+	}
+	return ""
+}
+
+// allowRequestHeaders проверяет список из Access-Control-Request-Headers.
+func (p *CORSPolicy) allowRequestHeaders(list string) bool {
+	for _, h := range strings.Split(list, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" && !p.headers[h] {
+			return false
+		}
+	}
+	return true
+}
+
+// CORS применяет политику. Запросы без Origin и с неразрешённого источника проходят
+// без CORS-заголовков (браузер сам не отдаст ответ странице); preflight с неразрешённого
+// источника или с неразрешённым методом/заголовком получает 403, разрешённый — 204.
+func CORS(p *CORSPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed := p.allowOrigin(origin)
+		if allowed == "" {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		h.Set("Access-Control-Allow-Origin", allowed)
+		// С "*" браузер credentials не примет, поэтому заголовок только для явных источников.
+		if p.credentials && allowed != "*" {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		if !p.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] ||
+			!p.allowRequestHeaders(c.GetHeader("Access-Control-Request-Headers")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		h.Set("Access-Control-Allow-Methods", p.allowMethods)
+		if p.allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+		}
+		h.Set("Access-Control-Max-Age", p.maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
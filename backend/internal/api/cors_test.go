@@ -0,0 +1,211 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/config"
+)
+
+func corsConfig(origins ...string) *config.Config {
+	cfg := config.Default()
+	cfg.CORS = config.CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	return cfg
+}
+
+// corsRouter — gin с политикой и одним маршрутом /ok для всех методов.
+func corsRouter(t *testing.T, cfg *config.Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	p, err := NewCORSPolicy(cfg, true)
+	if err != nil {
+		t.Fatalf("NewCORSPolicy: %v", err)
+	}
+	r := gin.New()
+	r.Use(CORS(p))
+	r.Any("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := corsRouter(t, corsConfig("https://app.example.ru", "https://*.smolensk.example"))
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+		allowed string // ожидаемый Access-Control-Allow-Origin
+	}{
+		{"exact origin", "https://app.example.ru", "POST", "content-type", http.StatusNoContent, "https://app.example.ru"},
+		{"origin case-insensitive", "https://APP.example.ru", "GET", "", http.StatusNoContent, "https://APP.example.ru"},
+		{"wildcard subdomain", "https://news.smolensk.example", "PUT", "Authorization, Content-Type", http.StatusNoContent, "https://news.smolensk.example"},
+		{"nested subdomain", "https://a.b.smolensk.example", "GET", "", http.StatusNoContent, "https://a.b.smolensk.example"},
+		{"wildcard excludes apex", "https://smolensk.example", "GET", "", http.StatusForbidden, ""},
+		{"wildcard other scheme", "http://news.smolensk.example", "GET", "", http.StatusForbidden, ""},
+		{"suffix lookalike", "https://evilsmolensk.example", "GET", "", http.StatusForbidden, ""},
+		{"disallowed origin", "https://evil.example", "GET", "", http.StatusForbidden, ""},
+		{"disallowed method", "https://app.example.ru", "DELETE", "", http.StatusForbidden, "https://app.example.ru"},
+		{"disallowed header", "https://app.example.ru", "POST", "Content-Type, X-Debug", http.StatusForbidden, "https://app.example.ru"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/ok", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.allowed)
+			}
+			vary := w.Header().Values("Vary")
+			for _, v := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+				if !slices.Contains(vary, v) {
+					t.Errorf("Vary %v lacks %q", vary, v)
+				}
+			}
+			if tt.status != http.StatusNoContent {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+					t.Errorf("rejected preflight has Allow-Methods %q", got)
+				}
+				return
+			}
+			h := w.Header()
+			if got := h.Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT" {
+				t.Errorf("Allow-Methods = %q", got)
+			}
+			if got := h.Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+				t.Errorf("Allow-Headers = %q", got)
+			}
+			if got := h.Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Max-Age = %q, want 600", got)
+			}
+			if got := h.Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Errorf("Allow-Credentials = %q, want true", got)
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	r := corsRouter(t, corsConfig("https://app.example.ru"))
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed string
+	}{
+		{"allowed", "https://app.example.ru", "https://app.example.ru"},
+		{"disallowed", "https://evil.example", ""},
+		{"no origin", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Запрос без preflight всегда доходит до обработчика: не отдать ответ решает браузер.
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			h := w.Header()
+			if got := h.Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.allowed)
+			}
+			wantVary := tt.origin != ""
+			if got := slices.Contains(h.Values("Vary"), "Origin"); got != wantVary {
+				t.Errorf("Vary: Origin present = %v, want %v", got, wantVary)
+			}
+			if slices.Contains(h.Values("Vary"), "Access-Control-Request-Method") {
+				t.Error("simple request varies on Access-Control-Request-Method")
+			}
+			wantExpose := ""
+			if tt.allowed != "" {
+				wantExpose = "ETag"
+			}
+			if got := h.Get("Access-Control-Expose-Headers"); got != wantExpose {
+				t.Errorf("Expose-Headers = %q, want %q", got, wantExpose)
+			}
+		})
+	}
+}
+
+func TestCORSAnyOriginWithoutCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, err := NewCORSPolicy(corsConfig("*"), false)
+	if err != nil {
+		t.Fatalf("NewCORSPolicy: %v", err)
+	}
+	r := gin.New()
+	r.Use(CORS(p))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		req := httptest.NewRequest(method, "/ok", nil)
+		req.Header.Set("Origin", "https://anyone.example")
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s: Allow-Origin = %q, want *", method, got)
+		}
+		// С "*" браузер отвергает ответ с credentials, поэтому заголовка быть не должно.
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("%s: Allow-Credentials = %q with wildcard origin", method, got)
+		}
+	}
+}
+
+func TestNewCORSPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		release bool
+		wantErr bool
+	}{
+		{"wildcard in release", []string{"*"}, true, true},
+		{"wildcard in debug", []string{"*"}, false, false},
+		{"explicit in release", []string{"https://app.example.ru"}, true, false},
+		{"subdomain mask", []string{"https://*.example.ru"}, true, false},
+		{"mask not leftmost", []string{"https://app.*.ru"}, true, true},
+		{"bare mask", []string{"https://*"}, true, true},
+		{"no scheme", []string{"app.example.ru"}, true, true},
+		{"path", []string{"https://app.example.ru/admin"}, true, true},
+		{"dev defaults", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCORSPolicy(corsConfig(tt.origins...), tt.release)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/logging"
+	"backend/internal/tracing"
+)
+
+const (
+	problemContentType        = "application/problem+json"
+	statusClientClosedRequest = 499
+)
+
+// ErrorHandler рендерит последнюю ошибку из c.Errors в формате RFC 7807.
+// Хендлеры и мидлвары только вызывают c.Error(...) и выходят — статус и тело выбираются здесь.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		var e *apperr.Error
+		if !errors.As(err, &e) {
+			e = apperr.Internal(err, "Internal server error")
+		}
+		// Клиент ушёл — отвечать уже некому, и это не сбой сервера.
+		// 499 (как в nginx) нужен только для логов и метрик.
+		if errors.Is(err, context.Canceled) {
+			c.AbortWithStatus(statusClientClosedRequest)
+			return
+		}
+		if e.Kind == apperr.KindInternal || e.Kind == apperr.KindTimeout {
+			logging.FromContext(c.Request.Context()).Error("request failed",
+				"method", c.Request.Method, "path", c.Request.URL.Path, "err", err)
+			tracing.RecordError(c.Request.Context(), err)
+		}
+
+		status := e.Status()
+		problem := gin.H{
+			"type":     "about:blank",
+			"title":    http.StatusText(status),
+			"status":   status,
+			"detail":   e.Message,
+			"instance": c.Request.URL.Path,
+			"code":     e.Kind,
+		}
+		for k, v := range e.Extra {
+			problem[k] = v
+		}
+
+		c.Header("Content-Type", problemContentType)
+		c.AbortWithStatusJSON(status, problem)
+	}
+}
+
+// routeNotFound отвечает problem+json на неизвестные маршруты вместо текстового "404 page not found".
+func routeNotFound(c *gin.Context) {
+	c.Error(apperr.NotFound("Route not found"))
+}
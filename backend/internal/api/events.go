@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/events"
+	"backend/internal/logging"
+)
+
+// eventReset — событие потока: часть событий потеряна (история их уже не хранит,
+// сервер перезапускался или клиент не успевал читать), данные нужно перечитать через API.
+const eventReset = "reset"
+
+// Events — поток событий об изменениях данных (Server-Sent Events).
+// Имя SSE-события — тип (news.created, stats.updated, ...), data — событие целиком в JSON.
+// При переподключении EventSource сам присылает Last-Event-ID (или ?last_event_id=),
+// и клиент получает пропущенные события. ?types=news.created,stats.updated или news.* —
+// только нужные типы.
+func (h *Handler) Events(c *gin.Context) {
+	var after int64
+	v := c.GetHeader("Last-Event-ID")
+	if v == "" {
+		v = c.Query("last_event_id")
+	}
+	if v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			c.Error(apperr.BadRequest("Invalid Last-Event-ID"))
+			return
+		}
+		after = id
+	}
+	match := eventFilter(c.Query("types"))
+
+	sub, missed, complete := h.events.Subscribe(after)
+	defer h.events.Unsubscribe(sub)
+
+	// Поток живёт дольше WriteTimeout сервера — снимаем дедлайн записи для этого соединения.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.FromContext(c.Request.Context()).Debug("events: write deadline not cleared", "err", err)
+	}
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+	c.Status(http.StatusOK)
+
+	if !complete {
+		c.Render(-1, sse.Event{Event: eventReset, Data: "{}"})
+	}
+	for _, e := range missed {
+		if match(e.Type) {
+			renderEvent(c, e)
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.cfg.Events.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Шина отключила подписчика: клиент переподключится с последним ID.
+				return
+			}
+			if match(e.Type) {
+				renderEvent(c, e)
+				c.Writer.Flush()
+			}
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func renderEvent(c *gin.Context, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	c.Render(-1, sse.Event{Id: strconv.FormatInt(e.ID, 10), Event: e.Type, Data: string(data)})
+}
+
+// eventFilter разбирает ?types= (типы, маски "ресурс.*", см. events.Match); пусто — все события.
+func eventFilter(param string) func(string) bool {
+	var patterns []string
+	for _, t := range strings.Split(param, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			patterns = append(patterns, t)
+		}
+	}
+	return func(typ string) bool {
+		if len(patterns) == 0 {
+			return true
+		}
+		for _, p := range patterns {
+			if events.Match(p, typ) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/cache"
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/validation"
+)
+
+// feedbackPerHour — сколько обращений в час принимается с одного IP (сверх общего лимита API).
+const feedbackPerHour = 5
+
+// publicFeedbackSize — сколько последних одобренных обращений показывается на сайте.
+const publicFeedbackSize = 100
+
+// Очередь модерации: размер страницы.
+const (
+	defaultFeedbackLimit = 50
+	maxFeedbackLimit     = 500
+)
+
+// ticketAlphabet — символы номеров обращений и заказов без похожих друг на друга (0/O, 1/I).
+const ticketAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// newTicket — случайный номер вида FB-7K3QX9M2 (prefix — "FB"): его сообщают автору,
+// по нему проверяется статус.
+func newTicket(prefix string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = ticketAlphabet[int(b[i])%len(ticketAlphabet)]
+	}
+	return prefix + "-" + string(b), nil
+}
+
+// SubmitFeedback принимает обращение жителя. Заполненное поле-ловушка website означает бота:
+// ему отвечают как обычно, но обращение не сохраняется.
+func (h *Handler) SubmitFeedback(c *gin.Context) {
+	var req models.CreateFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	f := &models.Feedback{
+		Category: strings.TrimSpace(req.Category),
+		Location: strings.TrimSpace(req.Location),
+		Text:     strings.TrimSpace(req.Text),
+		Contact:  strings.TrimSpace(req.Contact),
+	}
+	ticket, err := newTicket("FB")
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to create feedback"))
+		return
+	}
+	f.Ticket = ticket
+
+	if req.Website != "" {
+		logging.FromContext(ctx).Info("Feedback honeypot triggered", "ip", c.ClientIP())
+		now := time.Now()
+		f.Status, f.CreatedAt, f.UpdatedAt = models.FeedbackNew, now, now
+		c.JSON(http.StatusCreated, gin.H{"feedback": f.TicketStatus()})
+		return
+	}
+	if !validate(c, validation.Feedback(f)) {
+		return
+	}
+	if ok, wait := h.feedbackLimit.Allow(c.ClientIP()); !ok {
+		tooManyRequests(c, wait, "Too many feedback submissions")
+		return
+	}
+
+	if err := h.store.CreateFeedback(ctx, f); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to create feedback"))
+		return
+	}
+	logging.FromContext(ctx).Info("Feedback submitted", "feedback_id", f.ID, "ticket", f.Ticket, "category", f.Category)
+	c.JSON(http.StatusCreated, gin.H{"feedback": f.TicketStatus()})
+}
+
+// GetPublicFeedback — одобренные обращения с ответами ЦОДД.
+func (h *Handler) GetPublicFeedback(c *gin.Context) {
+	list, err := cache.Load(c.Request.Context(), h.cache, cacheFeedback, func(ctx context.Context) ([]models.Feedback, error) {
+		return h.store.GetFeedback(ctx, models.FeedbackApproved, publicFeedbackSize, 0)
+	})
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get feedback"))
+		return
+	}
+
+	public := make([]models.PublicFeedback, len(list))
+	for i := range list {
+		public[i] = list[i].Public()
+	}
+	last := lastUpdated(list, func(x *models.Feedback) time.Time { return x.UpdatedAt })
+	respondCached(c, listETag(len(list), last), last, gin.H{"feedback": public})
+}
+
+// GetFeedbackStatus — статус обращения по номеру (для автора).
+func (h *Handler) GetFeedbackStatus(c *gin.Context) {
+	ticket := strings.ToUpper(strings.TrimSpace(c.Param("ticket")))
+	f, err := h.store.GetFeedbackByTicket(c.Request.Context(), ticket)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get feedback"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"feedback": f.TicketStatus()})
+}
+
+// GetFeedbackQueue — обращения для модерации: ?status=new (по умолчанию), approved, rejected или all;
+// страницы — ?limit= и ?offset=.
+func (h *Handler) GetFeedbackQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.FeedbackNew)
+	switch status {
+	case models.FeedbackNew, models.FeedbackApproved, models.FeedbackRejected:
+	case "all":
+		status = ""
+	default:
+		c.Error(apperr.BadRequest("status must be new, approved, rejected or all"))
+		return
+	}
+	limit, offset, err := pageParams(c, defaultFeedbackLimit, maxFeedbackLimit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	list, err := h.store.GetFeedback(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get feedback"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"feedback": list, "limit": limit, "offset": offset})
+}
+
+// GetFeedbackByID — обращение целиком, с контактом автора.
+func (h *Handler) GetFeedbackByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	f, err := h.store.GetFeedbackByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get feedback"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"feedback": f})
+}
+
+// ApproveFeedback публикует обращение (вместе с ответом, если он уже есть).
+func (h *Handler) ApproveFeedback(c *gin.Context) {
+	h.moderateFeedback(c, models.FeedbackApproved, "")
+}
+
+// RejectFeedback отклоняет обращение или снимает его с публикации; причину видит автор.
+func (h *Handler) RejectFeedback(c *gin.Context) {
+	var req models.RejectFeedbackRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperr.BadRequest("Invalid request body"))
+			return
+		}
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if !validate(c, validation.FeedbackRejection(reason)) {
+		return
+	}
+	h.moderateFeedback(c, models.FeedbackRejected, reason)
+}
+
+func (h *Handler) moderateFeedback(c *gin.Context, status, reason string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	ctx := c.Request.Context()
+	f, err := h.store.ModerateFeedback(ctx, id, status, reason, c.GetInt("user_id"))
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to moderate feedback"))
+		return
+	}
+	h.cache.Invalidate(cacheFeedback)
+
+	logging.FromContext(ctx).Info("Feedback moderated", "feedback_id", id, "status", status, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"feedback": f})
+}
+
+// RespondFeedback сохраняет ответ ЦОДД: автор видит его по номеру обращения,
+// а у одобренного обращения он публикуется на сайте.
+func (h *Handler) RespondFeedback(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	var req models.RespondFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	response := strings.TrimSpace(req.Response)
+	if !validate(c, validation.FeedbackResponse(response)) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	f, err := h.store.RespondFeedback(ctx, id, response, c.GetInt("user_id"))
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to respond to feedback"))
+		return
+	}
+	h.cache.Invalidate(cacheFeedback)
+
+	logging.FromContext(ctx).Info("Feedback answered", "feedback_id", id, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"feedback": f})
+}
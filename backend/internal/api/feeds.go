@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/cache"
+	"backend/internal/models"
+)
+
+// feedSize — сколько последних новостей попадает в ленту.
+const feedSize = 50
+
+const (
+	feedTitle  = "ЦОДД Смоленск — новости"
+	feedAuthor = "ЦОДД Смоленск"
+)
+
+// Форматы лент: расширение в адресе и Content-Type.
+const (
+	feedRSS  = "rss"
+	feedAtom = "atom"
+)
+
+var feedContentTypes = map[string]string{
+	feedRSS:  "application/rss+xml; charset=utf-8",
+	feedAtom: "application/atom+xml; charset=utf-8",
+}
+
+// NewsRSS — /feeds/news.rss, все новости.
+func (h *Handler) NewsRSS(c *gin.Context) { h.newsFeed(c, "", feedRSS) }
+
+// NewsAtom — /feeds/news.atom, все новости.
+func (h *Handler) NewsAtom(c *gin.Context) { h.newsFeed(c, "", feedAtom) }
+
+// NewsTagFeed — лента новостей одного тега: /feeds/news/<тег>.rss или .atom
+// (тег без учёта регистра, в адресе — в URL-кодировке).
+func (h *Handler) NewsTagFeed(c *gin.Context) {
+	file := c.Param("file")
+	for format := range feedContentTypes {
+		if tag, ok := strings.CutSuffix(file, "."+format); ok && tag != "" {
+			h.newsFeed(c, tag, format)
+			return
+		}
+	}
+	c.Error(apperr.NotFound("Feed not found"))
+}
+
+func (h *Handler) newsFeed(c *gin.Context, tag, format string) {
+	news, err := cache.Load(c.Request.Context(), h.cache, cacheNews, h.store.GetNews)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get news"))
+		return
+	}
+	if tag != "" {
+		var tagged []models.News
+		for _, n := range news {
+			if strings.EqualFold(n.Tag, tag) {
+				tagged = append(tagged, n)
+			}
+		}
+		if len(tagged) == 0 {
+			c.Error(apperr.NotFound("Tag not found"))
+			return
+		}
+		tag, news = tagged[0].Tag, tagged
+	}
+
+	last := lastUpdated(news, func(x *models.News) time.Time { return x.UpdatedAt })
+	if checkCached(c, feedETag(format, len(news), last), last) {
+		return
+	}
+	if len(news) > feedSize {
+		news = news[:feedSize]
+	}
+
+	appURL := strings.TrimRight(h.cfg.HTTP.AppURL, "/")
+	var doc interface{}
+	if format == feedAtom {
+		doc = atomNewsFeed(appURL, requestURL(c), tag, news, last)
+	} else {
+		doc = rssNewsFeed(appURL, tag, news, last)
+	}
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to render feed"))
+		return
+	}
+	c.Data(http.StatusOK, feedContentTypes[format], append([]byte(xml.Header), body...))
+}
+
+// feedETag — ETag ленты: тот же список в RSS и Atom — разные представления,
+// поэтому формат входит в тег, иначе кэш мог бы отдать RSS на запрос Atom.
+func feedETag(format string, n int, last time.Time) string {
+	return `W/"` + format + "-" + strings.TrimPrefix(listETag(n, last), `W/"`)
+}
+
+// requestURL — абсолютный адрес текущего запроса (для rel="self"); схема учитывает
+// TLS на прокси перед сервером.
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.RequestURI()
+}
+
+// newsLink — постоянная ссылка на новость на сайте; она же guid/id записи ленты.
+func newsLink(appURL string, id int) string {
+	return appURL + "/news/" + strconv.Itoa(id)
+}
+
+func feedName(tag string) string {
+	if tag == "" {
+		return feedTitle
+	}
+	return feedTitle + " — " + tag
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Category    string  `xml:"category,omitempty"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func rssNewsFeed(appURL, tag string, news []models.News, last time.Time) rssFeed {
+	ch := rssChannel{
+		Title:       feedName(tag),
+		Link:        appURL + "/news",
+		Description: "Новости Центра организации дорожного движения Смоленска",
+		Language:    "ru",
+	}
+	if !last.IsZero() {
+		ch.LastBuildDate = last.UTC().Format(time.RFC1123Z)
+	}
+	for _, n := range news {
+		link := newsLink(appURL, n.ID)
+		ch.Items = append(ch.Items, rssItem{
+			Title:       n.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     n.Date.UTC().Format(time.RFC1123Z),
+			Category:    n.Tag,
+			Description: n.Content,
+		})
+	}
+	return rssFeed{Version: "2.0", Channel: ch}
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title     string        `xml:"title"`
+	ID        string        `xml:"id"`
+	Link      atomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Category  *atomCategory `xml:"category"`
+	Content   atomText      `xml:"content"`
+}
+
+// atomNewsFeed собирает ленту Atom; self — адрес самой ленты. <updated> обязателен,
+// поэтому для пустой ленты это момент генерации.
+func atomNewsFeed(appURL, self, tag string, news []models.News, last time.Time) atomFeed {
+	id := appURL + "/news"
+	if tag != "" {
+		id += "?tag=" + url.QueryEscape(tag)
+	}
+	if last.IsZero() {
+		last = time.Now()
+	}
+	feed := atomFeed{
+		Title:   feedName(tag),
+		ID:      id,
+		Updated: last.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "alternate", Href: appURL + "/news"},
+			{Rel: "self", Href: self},
+		},
+		Author: atomAuthor{Name: feedAuthor},
+	}
+	for _, n := range news {
+		link := newsLink(appURL, n.ID)
+		e := atomEntry{
+			Title:     n.Title,
+			ID:        link,
+			Link:      atomLink{Href: link},
+			Published: n.Date.UTC().Format(time.RFC3339),
+			Updated:   n.UpdatedAt.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "text", Value: n.Content},
+		}
+		if n.Tag != "" {
+			e.Category = &atomCategory{Term: n.Tag}
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	return feed
+}
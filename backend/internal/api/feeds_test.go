@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/models"
+)
+
+func TestAtomNewsFeedEmpty(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	feed := atomNewsFeed("https://codd.example", "https://api.codd.example/feeds/news.atom", "", nil, time.Time{})
+
+	updated, err := time.Parse(time.RFC3339, feed.Updated)
+	if err != nil {
+		t.Fatalf("updated %q: %v", feed.Updated, err)
+	}
+	if updated.Before(before) {
+		t.Errorf("updated = %s, want generation time for an empty feed", feed.Updated)
+	}
+}
+
+func TestAtomNewsFeedLinks(t *testing.T) {
+	last := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	news := []models.News{{ID: 7, Title: "Ремонт моста", Content: "Текст", Tag: "Дороги", Date: last, UpdatedAt: last}}
+	self := "https://api.codd.example/feeds/news/%D0%94%D0%BE%D1%80%D0%BE%D0%B3%D0%B8.atom"
+	body, err := xml.Marshal(atomNewsFeed("https://codd.example", self, "Дороги", news, last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(body)
+	for _, want := range []string{
+		`<link rel="alternate" href="https://codd.example/news"></link>`,
+		`<link rel="self" href="` + self + `"></link>`,
+		`<updated>2026-03-01T10:00:00Z</updated>`,
+		`<link href="https://codd.example/news/7"></link>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("feed lacks %s\n%s", want, out)
+		}
+	}
+}
+
+func TestFeedETag(t *testing.T) {
+	last := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	rss, atom := feedETag(feedRSS, 3, last), feedETag(feedAtom, 3, last)
+	if rss == atom {
+		t.Fatalf("RSS and Atom share ETag %s", rss)
+	}
+	if !strings.HasPrefix(rss, `W/"rss-`) || !strings.HasPrefix(atom, `W/"atom-`) {
+		t.Errorf("ETags = %s, %s", rss, atom)
+	}
+	if feedETag(feedRSS, 3, last) != rss || feedETag(feedRSS, 4, last) == rss {
+		t.Error("ETag must depend only on format, size and last update")
+	}
+}
+
+func TestRequestURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		proto string
+		want  string
+	}{
+		{"plain", "", "http://api.codd.example/feeds/news.atom?x=1"},
+		{"behind TLS proxy", "https", "https://api.codd.example/feeds/news.atom?x=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "http://api.codd.example/feeds/news.atom?x=1", nil)
+			if tt.proto != "" {
+				c.Request.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if got := requestURL(c); got != tt.want {
+				t.Errorf("requestURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,162 +1,366 @@
 package api
 
 import (
-    "log"
+    "context"
     "net/http"
     "strconv"
+    "strings"
+    "time"
 
     "github.com/gin-gonic/gin"
 
     "backend/config"
+    "backend/internal/apperr"
     "backend/internal/auth"
+    "backend/internal/cache"
+    "backend/internal/events"
+    "backend/internal/logging"
+    "backend/internal/mailer"
+    "backend/internal/metrics"
     "backend/internal/models"
+    "backend/internal/oidc"
+    "backend/internal/ratelimit"
+    "backend/internal/rbac"
     "backend/internal/store"
+    "backend/internal/validation"
 )
 
+var errInvalidCredentials = apperr.Unauthorized("Invalid credentials")
+
+// permissionsTTL — как долго матрица прав кэшируется между изменениями через API.
+const permissionsTTL = 30 * time.Second
+
+// resetEmailsPerHour — сколько писем сброса пароля можно запросить на один email за час.
+const resetEmailsPerHour = 3
+
 type Handler struct {
     store *store.Store
     cfg   *config.Config
+    keys  *auth.KeySet
+    mail  mailer.Mailer
+
+    loginBackoff *ratelimit.Backoff
+    lockout      ratelimit.LockoutPolicy
+    perms        *rbac.Cache
+    resetLimit   *ratelimit.Limiter
+    // feedbackLimit — обращения жителей с одного IP.
+    feedbackLimit *ratelimit.Limiter
+    // orderLimit — заказы услуг с одного IP.
+    orderLimit *ratelimit.Limiter
+
+    // cache — публичные данные между изменениями через API (http.cache_ttl).
+    cache *cache.Cache
+
+    // oidc — вход через OIDC-провайдер; nil, если он не настроен.
+    oidc *oidc.Provider
+
+    // events — шина событий для потока /api/events.
+    events *events.Bus
 }
 
-func NewHandler(store *store.Store, cfg *config.Config) *Handler {
-    return &Handler{store: store, cfg: cfg}
+func NewHandler(store *store.Store, cfg *config.Config, keys *auth.KeySet, mail mailer.Mailer, bus *events.Bus) *Handler {
+    h := &Handler{
+        store:        store,
+        cfg:          cfg,
+        keys:         keys,
+        mail:         mail,
+        events:       bus,
+        loginBackoff: ratelimit.NewBackoff(cfg.Auth.LoginBackoffBase, cfg.Auth.LoginBackoffMax),
+        lockout: ratelimit.LockoutPolicy{
+            MaxFailures: cfg.Auth.LoginMaxFailures,
+            Duration:    cfg.Auth.LoginLockout,
+            Max:         cfg.Auth.LoginLockoutMax,
+        },
+        perms:      rbac.NewCache(store.GetRoleMatrix, permissionsTTL),
+        resetLimit: ratelimit.NewLimiter(resetEmailsPerHour/3600.0, resetEmailsPerHour),
+        feedbackLimit: ratelimit.NewLimiter(feedbackPerHour/3600.0, feedbackPerHour),
+        orderLimit: ratelimit.NewLimiter(ordersPerHour/3600.0, ordersPerHour),
+        cache:      cache.New(cfg.HTTP.CacheTTL),
+    }
+    if cfg.Auth.OIDC.Issuer != "" {
+        // Формат соответствия групп проверяется при старте (validateCfg).
+        groupRoles, _ := oidc.ParseGroupRoles(cfg.Auth.OIDC.GroupRoles)
+        h.oidc = oidc.New(oidc.Config{
+            Issuer:       cfg.Auth.OIDC.Issuer,
+            ClientID:     cfg.Auth.OIDC.ClientID,
+            ClientSecret: cfg.Auth.OIDC.ClientSecret,
+            RedirectURL:  cfg.Auth.OIDC.RedirectURL,
+            GroupsClaim:  cfg.Auth.OIDC.GroupsClaim,
+            GroupRoles:   groupRoles,
+        })
+    }
+    return h
 }
 
 // AdminLogin - логин для админа
 func (h *Handler) AdminLogin(c *gin.Context) {
     var req models.AdminLoginRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        c.Error(apperr.BadRequest("Invalid request body"))
         return
     }
 
-    user, err := h.store.GetUserByEmail(req.Email)
-    if err != nil {
-        log.Printf("Admin login failed for %s: user not found", req.Email)
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    user := h.authenticate(c, "admin", req.Email, req.Password)
+    if user == nil {
         return
     }
 
     if user.Role != "admin" {
-        log.Printf("Admin login failed for %s: not admin role (role=%s)", req.Email, user.Role)
-        c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+        logging.FromContext(c.Request.Context()).Warn("Admin login failed: not admin role", "user_id", user.ID, "role", user.Role)
+        countLogin("admin", "forbidden")
+        c.Error(apperr.Forbidden("Admin access required"))
         return
     }
 
-    if user.Password != req.Password {
-        log.Printf("Admin login failed for %s: password mismatch", req.Email)
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    h.respondLogin(c, "admin", user)
+}
+
+// EditorLogin - логин для редактора
+func (h *Handler) EditorLogin(c *gin.Context) {
+    var req models.EditorLoginRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.Error(apperr.BadRequest("Invalid request body"))
         return
     }
 
-    token, err := auth.GenerateToken(*user, h.cfg.JWTSecret)
-    if err != nil {
-        log.Printf("Failed to generate token for admin %s: %v", req.Email, err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+    user := h.authenticate(c, "editor", req.Email, req.Password)
+    if user == nil {
         return
     }
 
-    log.Printf("Admin login successful for %s", req.Email)
-
-    user.Password = ""
+    if user.Role != "editor" && user.Role != "admin" {
+        logging.FromContext(c.Request.Context()).Warn("Editor login failed: insufficient permissions", "user_id", user.ID, "role", user.Role)
+        countLogin("editor", "forbidden")
+        c.Error(apperr.Forbidden("Editor access required"))
+        return
+    }
 
-    c.JSON(http.StatusOK, models.LoginResponse{
-        Token: token,
-        User:  *user,
-    })
+    h.respondLogin(c, "editor", user)
 }
 
-// EditorLogin - логин для редактора
-func (h *Handler) EditorLogin(c *gin.Context) {
-    var req models.EditorLoginRequest
+// Обычный логин
+func (h *Handler) Login(c *gin.Context) {
+    var req models.LoginRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        c.Error(apperr.BadRequest("Invalid request body"))
         return
     }
 
-    user, err := h.store.GetUserByEmail(req.Email)
-    if err != nil {
-        log.Printf("Editor login failed for %s: user not found", req.Email)
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    user := h.authenticate(c, "user", req.Email, req.Password)
+    if user == nil {
         return
     }
 
-    if user.Role != "editor" && user.Role != "admin" {
-        log.Printf("Editor login failed for %s: insufficient permissions (role=%s)", req.Email, user.Role)
-        c.JSON(http.StatusForbidden, gin.H{"error": "Editor access required"})
+    h.respondLogin(c, "user", user)
+}
+
+// authenticate проверяет email и пароль с защитой от перебора: экспоненциальная задержка
+// по IP и по email (в памяти) и блокировка учётной записи в БД после нескольких неудач подряд.
+// При отказе кладёт ошибку в c и возвращает nil.
+func (h *Handler) authenticate(c *gin.Context, endpoint, email, password string) *models.User {
+    ctx := c.Request.Context()
+    logger := logging.FromContext(ctx)
+    ipKey := "ip:" + c.ClientIP()
+    accountKey := "email:" + strings.ToLower(email)
+
+    if wait := max(h.loginBackoff.Wait(ipKey), h.loginBackoff.Wait(accountKey)); wait > 0 {
+        countLogin(endpoint, "throttled")
+        tooManyRequests(c, wait, "Too many login attempts, try again later")
+        return nil
+    }
+
+    user, err := h.store.GetUserByEmail(ctx, email)
+    if err != nil && !apperr.IsNotFound(err) {
+        countLogin(endpoint, "error")
+        c.Error(apperr.Wrap(err, "Failed to get user"))
+        return nil
+    }
+
+    if user != nil && user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+        logger.Warn("Login rejected: account locked", "endpoint", endpoint, "user_id", user.ID)
+        countLogin(endpoint, "locked")
+        tooManyRequests(c, time.Until(*user.LockedUntil), "Too many login attempts, try again later")
+        return nil
+    }
+
+    if user == nil {
+        auth.CheckDummyPassword(password)
+    }
+    if user == nil || !auth.CheckPassword(user.Password, password) {
+        h.loginBackoff.Fail(ipKey)
+        h.loginBackoff.Fail(accountKey)
+        if user != nil {
+            h.recordLoginFailure(c, user)
+        }
+        logger.Warn("Login failed: invalid credentials", "endpoint", endpoint)
+        countLogin(endpoint, "invalid_credentials")
+        c.Error(errInvalidCredentials)
+        return nil
+    }
+
+    h.loginBackoff.Reset(accountKey)
+    if auth.NeedsRehash(user.Password) {
+        // Пароль из seed-данных в открытом виде — заменяем на bcrypt при первом входе.
+        if hash, err := auth.HashPassword(password); err == nil {
+            _ = h.store.UpdateUserPassword(ctx, user.ID, hash)
+        }
+    }
+    if user.FailedLogins > 0 || user.LockedUntil != nil {
+        // Ошибка уже залогирована в store; вход от неё не зависит.
+        _ = h.store.ClearLockout(ctx, user.ID)
+    }
+    return user
+}
+
+// recordLoginFailure увеличивает счётчик неудач в БД и при превышении порога блокирует учётную запись.
+func (h *Handler) recordLoginFailure(c *gin.Context, user *models.User) {
+    ctx := c.Request.Context()
+    failures, err := h.store.RecordLoginFailure(ctx, user.ID)
+    if err != nil {
         return
     }
+    if d := h.lockout.LockFor(failures); d > 0 {
+        until := time.Now().Add(d)
+        if err := h.store.LockUser(ctx, user.ID, until); err == nil {
+            logging.FromContext(ctx).Warn("Account locked", "user_id", user.ID, "failures", failures, "until", until)
+        }
+    }
+}
 
-    if user.Password != req.Password {
-        log.Printf("Editor login failed for %s: password mismatch", req.Email)
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+// respondLogin завершает первый шаг логина: если у пользователя включена 2FA
+// (или она обязательна для его роли), вместо JWT выдаётся токен второго шага.
+func (h *Handler) respondLogin(c *gin.Context, endpoint string, user *models.User) {
+    purpose := ""
+    if user.TOTPEnabled {
+        purpose = auth.PurposeMFAVerify
+    } else {
+        required, err := h.store.MFARequired(c.Request.Context(), user.Role)
+        if err != nil {
+            countLogin(endpoint, "error")
+            c.Error(apperr.Wrap(err, "Failed to check MFA policy"))
+            return
+        }
+        if required {
+            purpose = auth.PurposeMFAEnroll
+        }
+    }
+    if purpose == "" {
+        h.issueToken(c, endpoint, user)
+        return
+    }
+
+    token, err := h.keys.GenerateMFAToken(*user, purpose, endpoint)
+    if err != nil {
+        countLogin(endpoint, "error")
+        c.Error(apperr.Wrap(err, "Failed to generate token"))
         return
     }
+    countLogin(endpoint, "mfa_challenge")
+    c.JSON(http.StatusOK, models.MFAChallengeResponse{
+        MFARequired:        true,
+        EnrollmentRequired: purpose == auth.PurposeMFAEnroll,
+        MFAToken:           token,
+    })
+}
+
+// issueToken выдаёт JWT пользователю, прошедшему все шаги логина.
+func (h *Handler) issueToken(c *gin.Context, endpoint string, user *models.User) {
+    if resp := h.loginResponse(c, endpoint, user); resp != nil {
+        c.JSON(http.StatusOK, resp)
+    }
+}
 
-    token, err := auth.GenerateToken(*user, h.cfg.JWTSecret)
+// loginResponse выпускает JWT и собирает ответ успешного логина.
+// При ошибке кладёт её в c и возвращает nil.
+func (h *Handler) loginResponse(c *gin.Context, endpoint string, user *models.User) *models.LoginResponse {
+    sessionID, err := h.createSession(c, user)
     if err != nil {
-        log.Printf("Failed to generate token for editor %s: %v", req.Email, err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-        return
+        countLogin(endpoint, "error")
+        c.Error(apperr.Wrap(err, "Failed to create session"))
+        return nil
     }
 
-    log.Printf("Editor login successful for %s", req.Email)
+    token, err := h.keys.GenerateToken(*user, sessionID)
+    if err != nil {
+        countLogin(endpoint, "error")
+        c.Error(apperr.Wrap(err, "Failed to generate token"))
+        return nil
+    }
+
+    perms, err := h.perms.Permissions(c.Request.Context(), user.Role)
+    if err != nil {
+        countLogin(endpoint, "error")
+        c.Error(apperr.Wrap(err, "Failed to get permissions"))
+        return nil
+    }
 
+    logging.FromContext(c.Request.Context()).Info("Login successful", "endpoint", endpoint, "user_id", user.ID, "session_id", sessionID)
+    countLogin(endpoint, "success")
     user.Password = ""
 
-    c.JSON(http.StatusOK, models.LoginResponse{
-        Token: token,
-        User:  *user,
-    })
+    return &models.LoginResponse{
+        Token:       token,
+        User:        *user,
+        Permissions: perms,
+    }
 }
 
-// Обычный логин
-func (h *Handler) Login(c *gin.Context) {
-    var req models.LoginRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+// UnlockUser — админ снимает блокировку входа и обнуляет счётчик неудач.
+func (h *Handler) UnlockUser(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    user, err := h.store.GetUserByEmail(req.Email)
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    if err := h.store.ClearLockout(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to unlock user"))
         return
     }
 
-    if user.Password != req.Password {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    logging.FromContext(c.Request.Context()).Info("User unlocked", "user_id", id, "by", c.GetInt("user_id"))
+    c.JSON(http.StatusOK, gin.H{"message": "User unlocked"})
+}
+
+// countLogin учитывает попытку входа в метрике logins_total.
+func countLogin(endpoint, result string) {
+    metrics.Logins.WithLabelValues(endpoint, result).Inc()
+}
+
+// Fines
+func (h *Handler) GetFines(c *gin.Context) {
+    fines, err := cache.Load(c.Request.Context(), h.cache, cacheFines, h.store.GetFines)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get fines"))
         return
     }
 
-    token, err := auth.GenerateToken(*user, h.cfg.JWTSecret)
+    last := lastUpdated(fines, func(x *models.Fine) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(fines), last), last, gin.H{"fines": fines})
+}
+
+func (h *Handler) GetFineByID(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    user.Password = ""
-
-    c.JSON(http.StatusOK, models.LoginResponse{
-        Token: token,
-        User:  *user,
+    fine, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheFines, id), func(ctx context.Context) (*models.Fine, error) {
+        return h.store.GetFineByID(ctx, id)
     })
-}
-
-// Fines
-func (h *Handler) GetFines(c *gin.Context) {
-    fines, err := h.store.GetFines()
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get fines"})
+        c.Error(apperr.Wrap(err, "Failed to get fine"))
         return
     }
-    c.JSON(http.StatusOK, gin.H{"fines": fines})
+
+    respondCached(c, etag(fine.Version), fine.UpdatedAt, gin.H{"fine": fine})
 }
 
 func (h *Handler) CreateFine(c *gin.Context) {
     var req models.CreateFineRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -168,10 +372,15 @@ func (h *Handler) CreateFine(c *gin.Context) {
         CollectedAmountTotal: req.CollectedAmountTotal,
     }
 
-    if err := h.store.CreateFine(fine); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create fine"})
+    if !validate(c, validation.Fine(fine)) {
+        return
+    }
+
+    if err := h.store.CreateFine(c.Request.Context(), fine); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create fine"))
         return
     }
+    h.cache.Invalidate(cacheFines, cacheStats)
 
     c.JSON(http.StatusCreated, gin.H{"fine": fine})
 }
@@ -179,72 +388,90 @@ func (h *Handler) CreateFine(c *gin.Context) {
 func (h *Handler) UpdateFine(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
     var req models.UpdateFineRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if !bindMergePatch(c, &req) {
         return
     }
 
-    fine := &models.Fine{
-        Date:                 req.Date,
-        ViolationsTotal:      req.ViolationsTotal,
-        OrdersTotal:          req.OrdersTotal,
-        FinesAmountTotal:     req.FinesAmountTotal,
-        CollectedAmountTotal: req.CollectedAmountTotal,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetFineByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get fine"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.Fine(cur)) {
+        return
     }
 
-    if err := h.store.UpdateFine(id, fine); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fine"})
+    newVersion, err := h.store.PatchFine(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update fine", func() (interface{}, int, error) {
+            cur, err := h.store.GetFineByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheFines, cacheStats)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Fine updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Fine updated successfully", "version": newVersion})
 }
 
 func (h *Handler) DeleteFine(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteFine(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fine"})
+    if err := h.store.DeleteFine(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete fine"))
         return
     }
+    h.cache.Invalidate(cacheFines, cacheStats)
 
     c.Status(http.StatusNoContent)
 }
 
 // Evacuations
 func (h *Handler) GetEvacuations(c *gin.Context) {
-    evacuations, err := h.store.GetEvacuations()
+    evacuations, err := cache.Load(c.Request.Context(), h.cache, cacheEvacuations, h.store.GetEvacuations)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evacuations"})
+        c.Error(apperr.Wrap(err, "Failed to get evacuations"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"evacuations": evacuations})
+    last := lastUpdated(evacuations, func(x *models.Evacuation) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(evacuations), last), last, gin.H{"evacuations": evacuations})
 }
 
 func (h *Handler) GetEvacuationRoutes(c *gin.Context) {
-    routes, err := h.store.GetEvacuationRoutes()
+    routes, err := cache.Load(c.Request.Context(), h.cache, cacheEvacuationRoutes, h.store.GetEvacuationRoutes)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get evacuation routes"})
+        c.Error(apperr.Wrap(err, "Failed to get evacuation routes"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"evacuation_routes": routes})
+    last := lastUpdated(routes, func(x *models.EvacuationRoute) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(routes), last), last, gin.H{"evacuation_routes": routes})
 }
 
 func (h *Handler) CreateEvacuation(c *gin.Context) {
     var req models.CreateEvacuationRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -256,18 +483,23 @@ func (h *Handler) CreateEvacuation(c *gin.Context) {
         FineLotIncome:    req.FineLotIncome,
     }
 
-    if err := h.store.CreateEvacuation(evacuation); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create evacuation"})
+    if !validate(c, validation.Evacuation(evacuation)) {
         return
     }
 
+    if err := h.store.CreateEvacuation(c.Request.Context(), evacuation); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create evacuation"))
+        return
+    }
+    h.cache.Invalidate(cacheEvacuations, cacheStats)
+
     c.JSON(http.StatusCreated, gin.H{"evacuation": evacuation})
 }
 
 func (h *Handler) CreateEvacuationRoute(c *gin.Context) {
     var req models.CreateEvacuationRouteRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -277,29 +509,53 @@ func (h *Handler) CreateEvacuationRoute(c *gin.Context) {
         Route: req.Route,
     }
 
-    if err := h.store.CreateEvacuationRoute(route); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create evacuation route"})
+    if !validate(c, validation.EvacuationRoute(route)) {
+        return
+    }
+
+    if err := h.store.CreateEvacuationRoute(c.Request.Context(), route); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create evacuation route"))
         return
     }
+    h.cache.Invalidate(cacheEvacuationRoutes)
 
     c.JSON(http.StatusCreated, gin.H{"evacuation_route": route})
 }
 
 // Traffic lights
 func (h *Handler) GetTrafficLights(c *gin.Context) {
-    lights, err := h.store.GetTrafficLights()
+    lights, err := cache.Load(c.Request.Context(), h.cache, cacheTrafficLights, h.store.GetTrafficLights)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get traffic lights"))
+        return
+    }
+
+    last := lastUpdated(lights, func(x *models.TrafficLight) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(lights), last), last, gin.H{"traffic_lights": lights})
+}
+
+func (h *Handler) GetTrafficLightByID(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.Error(apperr.BadRequest("Invalid ID"))
+        return
+    }
+
+    light, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheTrafficLights, id), func(ctx context.Context) (*models.TrafficLight, error) {
+        return h.store.GetTrafficLightByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get traffic lights"})
+        c.Error(apperr.Wrap(err, "Failed to get traffic light"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"traffic_lights": lights})
+    respondCached(c, etag(light.Version), light.UpdatedAt, gin.H{"traffic_light": light})
 }
 
 func (h *Handler) CreateTrafficLight(c *gin.Context) {
     var req models.CreateTrafficLightRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -314,88 +570,113 @@ func (h *Handler) CreateTrafficLight(c *gin.Context) {
         Status:      req.Status,
     }
 
-    if err := h.store.CreateTrafficLight(light); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create traffic light"})
+    if !validate(c, validation.TrafficLight(light)) {
         return
     }
 
+    if err := h.store.CreateTrafficLight(c.Request.Context(), light); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create traffic light"))
+        return
+    }
+    h.cache.Invalidate(cacheTrafficLights, cacheStats, cacheTraffic)
+
     c.JSON(http.StatusCreated, gin.H{"traffic_light": light})
 }
 
 func (h *Handler) UpdateTrafficLight(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
     var req models.UpdateTrafficLightRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if !bindMergePatch(c, &req) {
         return
     }
 
-    light := &models.TrafficLight{
-        Address:     req.Address,
-        LightType:   req.LightType,
-        InstallYear: req.InstallYear,
-        Status:      req.Status,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetTrafficLightByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get traffic light"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.TrafficLight(cur)) {
+        return
     }
 
-    if err := h.store.UpdateTrafficLight(id, light); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update traffic light"})
+    newVersion, err := h.store.PatchTrafficLight(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update traffic light", func() (interface{}, int, error) {
+            cur, err := h.store.GetTrafficLightByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheTrafficLights, cacheStats, cacheTraffic)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Traffic light updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Traffic light updated successfully", "version": newVersion})
 }
 
 func (h *Handler) DeleteTrafficLight(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteTrafficLight(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete traffic light"})
+    if err := h.store.DeleteTrafficLight(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete traffic light"))
         return
     }
+    h.cache.Invalidate(cacheTrafficLights, cacheStats, cacheTraffic)
 
     c.Status(http.StatusNoContent)
 }
 
 // News
 func (h *Handler) GetNews(c *gin.Context) {
-    news, err := h.store.GetNews()
+    news, err := cache.Load(c.Request.Context(), h.cache, cacheNews, h.store.GetNews)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get news"})
+        c.Error(apperr.Wrap(err, "Failed to get news"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"news": news})
+    last := lastUpdated(news, func(x *models.News) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(news), last), last, gin.H{"news": news})
 }
 
 func (h *Handler) GetNewsByID(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    news, err := h.store.GetNewsByID(id)
+    news, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheNews, id), func(ctx context.Context) (*models.News, error) {
+        return h.store.GetNewsByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+        c.Error(apperr.Wrap(err, "Failed to get news"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"news": news})
+    respondCached(c, etag(news.Version), news.UpdatedAt, gin.H{"news": news})
 }
 
 func (h *Handler) CreateNews(c *gin.Context) {
     var req models.CreateNewsRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -405,10 +686,15 @@ func (h *Handler) CreateNews(c *gin.Context) {
         Tag:     req.Tag,
     }
 
-    if err := h.store.CreateNews(news); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create news"})
+    if !validate(c, validation.News(news)) {
+        return
+    }
+
+    if err := h.store.CreateNews(c.Request.Context(), news); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create news"))
         return
     }
+    h.cache.Invalidate(cacheNews)
 
     c.JSON(http.StatusCreated, gin.H{"news": news})
 }
@@ -416,76 +702,97 @@ func (h *Handler) CreateNews(c *gin.Context) {
 func (h *Handler) UpdateNews(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
     var req models.UpdateNewsRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if !bindMergePatch(c, &req) {
         return
     }
 
-    news := &models.News{
-        Title:   req.Title,
-        Content: req.Content,
-        Tag:     req.Tag,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetNewsByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get news"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.News(cur)) {
+        return
     }
 
-    if err := h.store.UpdateNews(id, news); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update news"})
+    newVersion, err := h.store.PatchNews(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update news", func() (interface{}, int, error) {
+            cur, err := h.store.GetNewsByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheNews)
 
-    c.JSON(http.StatusOK, gin.H{"message": "News updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "News updated successfully", "version": newVersion})
 }
 
 func (h *Handler) DeleteNews(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteNews(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete news"})
+    if err := h.store.DeleteNews(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete news"))
         return
     }
+    h.cache.Invalidate(cacheNews)
 
     c.Status(http.StatusNoContent)
 }
 
 // Services
 func (h *Handler) GetServices(c *gin.Context) {
-    services, err := h.store.GetServices()
+    services, err := cache.Load(c.Request.Context(), h.cache, cacheServices, h.store.GetServices)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get services"})
+        c.Error(apperr.Wrap(err, "Failed to get services"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"services": services})
+    last := lastUpdated(services, func(x *models.Service) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(services), last), last, gin.H{"services": services})
 }
 
 func (h *Handler) GetServiceByID(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    service, err := h.store.GetServiceByID(id)
+    service, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheServices, id), func(ctx context.Context) (*models.Service, error) {
+        return h.store.GetServiceByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+        c.Error(apperr.Wrap(err, "Failed to get service"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"service": service})
+    respondCached(c, etag(service.Version), service.UpdatedAt, gin.H{"service": service})
 }
 
 func (h *Handler) CreateService(c *gin.Context) {
     var req models.CreateServiceRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -497,136 +804,167 @@ func (h *Handler) CreateService(c *gin.Context) {
         IconURL:     req.IconURL,
     }
 
-    if err := h.store.CreateService(service); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service"})
+    if !validate(c, validation.Service(service)) {
         return
     }
 
+    if err := h.store.CreateService(c.Request.Context(), service); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create service"))
+        return
+    }
+    h.cache.Invalidate(cacheServices)
+
     c.JSON(http.StatusCreated, gin.H{"service": service})
 }
 
 func (h *Handler) UpdateService(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
     var req models.UpdateServiceRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if !bindMergePatch(c, &req, "icon_url") {
         return
     }
 
-    service := &models.Service{
-        Title:       req.Title,
-        Description: req.Description,
-        Price:       req.Price,
-        Category:    req.Category,
-        IconURL:     req.IconURL,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetServiceByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get service"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.Service(cur)) {
+        return
     }
 
-    if err := h.store.UpdateService(id, service); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service"})
+    newVersion, err := h.store.PatchService(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update service", func() (interface{}, int, error) {
+            cur, err := h.store.GetServiceByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheServices)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Service updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Service updated successfully", "version": newVersion})
 }
 
 func (h *Handler) DeleteService(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteService(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service"})
+    if err := h.store.DeleteService(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete service"))
         return
     }
+    h.cache.Invalidate(cacheServices)
 
     c.Status(http.StatusNoContent)
 }
 
 // Team
 func (h *Handler) GetTeam(c *gin.Context) {
-    team, err := h.store.GetTeam()
+    team, err := cache.Load(c.Request.Context(), h.cache, cacheTeam, h.store.GetTeam)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get team"})
+        c.Error(apperr.Wrap(err, "Failed to get team"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"team": team})
+    last := lastUpdated(team, func(x *models.TeamMember) time.Time { return timeOrZero(x.UpdatedAt) })
+    respondCached(c, listETag(len(team), last), last, gin.H{"team": team})
 }
 
 func (h *Handler) GetTeamMemberByID(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    member, err := h.store.GetTeamMemberByID(id)
+    member, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheTeam, id), func(ctx context.Context) (*models.TeamMember, error) {
+        return h.store.GetTeamMemberByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+        c.Error(apperr.Wrap(err, "Failed to get team member"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"team_member": member})
+    respondCached(c, etag(member.Version), timeOrZero(member.UpdatedAt), gin.H{"team_member": member})
 }
 
 // Update team member (admin/editor)
 func (h *Handler) UpdateTeam(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    var req models.TeamMember
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    var req models.UpdateTeamMemberRequest
+    if !bindMergePatch(c, &req, "photo_url") {
         return
     }
 
-    member := &models.TeamMember{
-        Name:       req.Name,
-        Position:   req.Position,
-        Experience: req.Experience,
-        PhotoURL:   req.PhotoURL,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
     }
 
-    affected, err := h.store.UpdateTeam(id, member)
+    cur, err := h.store.GetTeamMemberByID(c.Request.Context(), id)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member"})
+        c.Error(apperr.Wrap(err, "Failed to get team member"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.TeamMember(cur)) {
         return
     }
-    if affected == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+
+    newVersion, err := h.store.PatchTeamMember(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update team member", func() (interface{}, int, error) {
+            cur, err := h.store.GetTeamMemberByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheTeam)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Team member updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Team member updated successfully", "version": newVersion})
 }
 
 // Delete team member (admin/editor)
 func (h *Handler) DeleteTeam(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    affected, err := h.store.DeleteTeamByID(id)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team member"})
-        return
-    }
-    if affected == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+    if err := h.store.DeleteTeam(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete team member"))
         return
     }
+    h.cache.Invalidate(cacheTeam)
 
     c.Status(http.StatusNoContent)
 }
@@ -635,7 +973,7 @@ func (h *Handler) DeleteTeam(c *gin.Context) {
 func (h *Handler) CreateTeam(c *gin.Context) {
     var req models.TeamMember
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -646,30 +984,54 @@ func (h *Handler) CreateTeam(c *gin.Context) {
         PhotoURL:   req.PhotoURL,
     }
 
-    if err := h.store.CreateTeamMember(member); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team member"})
+    if !validate(c, validation.TeamMember(member)) {
+        return
+    }
+
+    if err := h.store.CreateTeamMember(c.Request.Context(), member); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create team member"))
         return
     }
+    h.cache.Invalidate(cacheTeam)
 
     c.JSON(http.StatusCreated, gin.H{"team_member": member})
 }
 
 // Projects
 func (h *Handler) GetProjects(c *gin.Context) {
-    projects, err := h.store.GetProjects()
+    projects, err := cache.Load(c.Request.Context(), h.cache, cacheProjects, h.store.GetProjects)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get projects"))
+        return
+    }
+
+    last := lastUpdated(projects, func(x *models.Project) time.Time { return x.UpdatedAt })
+    respondCached(c, listETag(len(projects), last), last, gin.H{"projects": projects})
+}
+
+func (h *Handler) GetProjectByID(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil || id <= 0 {
+        c.Error(apperr.BadRequest("Invalid ID"))
+        return
+    }
+
+    project, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheProjects, id), func(ctx context.Context) (*models.Project, error) {
+        return h.store.GetProjectByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get projects"})
+        c.Error(apperr.Wrap(err, "Failed to get project"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"projects": projects})
+    respondCached(c, etag(project.Version), project.UpdatedAt, gin.H{"project": project})
 }
 
 // CreateProject
 func (h *Handler) CreateProject(c *gin.Context) {
     var req models.Project
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -680,11 +1042,16 @@ func (h *Handler) CreateProject(c *gin.Context) {
         Status:      req.Status,
     }
 
-    if err := h.store.CreateProject(p); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
+    if !validate(c, validation.Project(p)) {
         return
     }
 
+    if err := h.store.CreateProject(c.Request.Context(), p); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create project"))
+        return
+    }
+    h.cache.Invalidate(cacheProjects)
+
     c.JSON(http.StatusCreated, gin.H{"project": p})
 }
 
@@ -692,100 +1059,123 @@ func (h *Handler) CreateProject(c *gin.Context) {
 func (h *Handler) UpdateProject(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    var req models.Project
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    var req models.UpdateProjectRequest
+    if !bindMergePatch(c, &req) {
         return
     }
 
-    p := &models.Project{
-        Title:       req.Title,
-        Description: req.Description,
-        Category:    req.Category,
-        Status:      req.Status,
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetProjectByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get project"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.Project(cur)) {
+        return
     }
 
-    if err := h.store.UpdateProject(id, p); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
+    newVersion, err := h.store.PatchProject(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update project", func() (interface{}, int, error) {
+            cur, err := h.store.GetProjectByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheProjects)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Project updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Project updated successfully", "version": newVersion})
 }
 
 // DeleteProject
 func (h *Handler) DeleteProject(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteProject(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+    if err := h.store.DeleteProject(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete project"))
         return
     }
+    h.cache.Invalidate(cacheProjects)
 
     c.Status(http.StatusNoContent)
 }
 
 // Stats
 func (h *Handler) GetStats(c *gin.Context) {
-    stats, err := h.store.GetStats()
+    stats, err := cache.Load(c.Request.Context(), h.cache, cacheStats, h.store.GetStats)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+        c.Error(apperr.Wrap(err, "Failed to get stats"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"stats": stats})
+    body := gin.H{"stats": stats}
+    respondCached(c, bodyETag(body), time.Time{}, body)
 }
 
 // Traffic
 func (h *Handler) GetTraffic(c *gin.Context) {
-    traffic, err := h.store.GetTraffic()
+    traffic, err := cache.Load(c.Request.Context(), h.cache, cacheTraffic, h.store.GetTraffic)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get traffic"})
+        c.Error(apperr.Wrap(err, "Failed to get traffic"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"traffic": traffic})
+    body := gin.H{"traffic": traffic}
+    respondCached(c, bodyETag(body), time.Time{}, body)
 }
 
 func (h *Handler) GetVacancies(c *gin.Context) {
-    vacancies, err := h.store.GetVacancies()
+    vacancies, err := cache.Load(c.Request.Context(), h.cache, cacheVacancies, h.store.GetVacancies)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get vacancies"})
+        c.Error(apperr.Wrap(err, "Failed to get vacancies"))
         return
     }
-    c.JSON(http.StatusOK, gin.H{"vacancies": vacancies})
+
+    last := lastUpdated(vacancies, func(x *models.Vacancy) time.Time { return timeOrZero(x.UpdatedAt) })
+    respondCached(c, listETag(len(vacancies), last), last, gin.H{"vacancies": vacancies})
 }
 
 // Публичное получение вакансии по ID
 func (h *Handler) GetVacancyByID(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    vacancy, err := h.store.GetVacancyByID(id)
+    vacancy, err := cache.Load(c.Request.Context(), h.cache, itemKey(cacheVacancies, id), func(ctx context.Context) (*models.Vacancy, error) {
+        return h.store.GetVacancyByID(ctx, id)
+    })
     if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Vacancy not found"})
+        c.Error(apperr.Wrap(err, "Failed to get vacancy"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"vacancy": vacancy})
+    respondCached(c, etag(vacancy.Version), timeOrZero(vacancy.UpdatedAt), gin.H{"vacancy": vacancy})
 }
 
 // Создание вакансии (admin/editor)
 func (h *Handler) CreateVacancy(c *gin.Context) {
     var req models.CreateVacancyRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(apperr.BadRequest(err.Error()))
         return
     }
 
@@ -795,10 +1185,15 @@ func (h *Handler) CreateVacancy(c *gin.Context) {
         Salary:     req.Salary,
     }
 
-    if err := h.store.CreateVacancy(v); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create vacancy"})
+    if !validate(c, validation.Vacancy(v)) {
+        return
+    }
+
+    if err := h.store.CreateVacancy(c.Request.Context(), v); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to create vacancy"))
         return
     }
+    h.cache.Invalidate(cacheVacancies)
 
     c.JSON(http.StatusCreated, gin.H{"vacancy": v})
 }
@@ -807,50 +1202,60 @@ func (h *Handler) CreateVacancy(c *gin.Context) {
 func (h *Handler) UpdateVacancy(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
     var req models.UpdateVacancyRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    if !bindMergePatch(c, &req) {
         return
     }
 
-    v := &models.Vacancy{
-        Position:   ptrOrEmpty(req.Position),
-        Experience: ptrOrEmpty(req.Experience),
-        Salary:     ptrOrEmpty(req.Salary),
+    version, ok := requireVersion(c, req.Version)
+    if !ok {
+        return
+    }
+
+    cur, err := h.store.GetVacancyByID(c.Request.Context(), id)
+    if err != nil {
+        c.Error(apperr.Wrap(err, "Failed to get vacancy"))
+        return
+    }
+    req.ApplyTo(cur)
+    if !validate(c, validation.Vacancy(cur)) {
+        return
     }
 
-    if err := h.store.UpdateVacancy(id, v); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vacancy"})
+    newVersion, err := h.store.PatchVacancy(c.Request.Context(), id, version, &req)
+    if err != nil {
+        respondUpdateError(c, err, "Failed to update vacancy", func() (interface{}, int, error) {
+            cur, err := h.store.GetVacancyByID(c.Request.Context(), id)
+            if err != nil {
+                return nil, 0, err
+            }
+            return cur, cur.Version, nil
+        })
         return
     }
+    h.cache.Invalidate(cacheVacancies)
 
-    c.JSON(http.StatusOK, gin.H{"message": "Vacancy updated successfully"})
+    c.Header("ETag", etag(newVersion))
+    c.JSON(http.StatusOK, gin.H{"message": "Vacancy updated successfully", "version": newVersion})
 }
 
 // Удаление вакансии (admin/editor)
 func (h *Handler) DeleteVacancy(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil || id <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        c.Error(apperr.BadRequest("Invalid ID"))
         return
     }
 
-    if err := h.store.DeleteVacancy(id); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete vacancy"})
+    if err := h.store.DeleteVacancy(c.Request.Context(), id); err != nil {
+        c.Error(apperr.Wrap(err, "Failed to delete vacancy"))
         return
     }
+    h.cache.Invalidate(cacheVacancies)
 
     c.Status(http.StatusNoContent)
 }
-
-// утилита: вернуть значение из *string либо пустую строку
-func ptrOrEmpty(s *string) string {
-    if s == nil {
-        return ""
-    }
-    return *s
-}
\ No newline at end of file
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/health"
+)
+
+// healthz — живость процесса: отвечает 200, пока процесс способен обслуживать запросы.
+func healthz(hc *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":         health.StatusOK,
+			"uptime_seconds": int64(hc.Uptime().Seconds()),
+		})
+	}
+}
+
+// readyz — готовность принимать трафик: БД, версия миграций, фоновые воркеры.
+// Во время остановки отвечает 503 со статусом draining.
+func readyz(hc *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, ok := hc.Ready(c.Request.Context())
+		status := http.StatusOK
+		if !ok {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control для маршрутов чтения. Списки на публичном сайте можно недолго держать
+// в кэше браузера и прокси; отдельные записи и всё, что видит админка, сверяются
+// с сервером на каждый запрос (ETag записи нужен для If-Match при редактировании).
+const (
+	cacheRevalidate = "no-cache"
+	cacheShort      = "public, max-age=60"
+	cacheLong       = "public, max-age=300"
+	cachePrivate    = "private, no-cache"
+)
+
+// Ресурсы кэша чтения: ключи "news", "news:5" и т.д.
+const (
+	cacheNews             = "news"
+	cacheServices         = "services"
+	cacheTeam             = "team"
+	cacheProjects         = "projects"
+	cacheVacancies        = "vacancies"
+	cacheFines            = "fines"
+	cacheEvacuations      = "evacuations"
+	cacheEvacuationRoutes = "evacuation_routes"
+	cacheTrafficLights    = "traffic_lights"
+	cacheStats            = "stats"
+	cacheTraffic          = "traffic"
+	cacheFeedback         = "feedback"
+)
+
+// itemKey — ключ кэша для одной записи ресурса.
+func itemKey(resource string, id int) string {
+	return resource + ":" + strconv.Itoa(id)
+}
+
+// cacheControl задаёт Cache-Control ответа маршрута.
+func cacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
+
+// respondCached отдаёт body как JSON с ETag и Last-Modified (если известен)
+// или 304, если у клиента уже эта версия (If-None-Match, а без него — If-Modified-Since).
+func respondCached(c *gin.Context, tag string, lastModified time.Time, body interface{}) {
+	if !checkCached(c, tag, lastModified) {
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// checkCached ставит ETag и Last-Modified и, если у клиента уже эта версия, отвечает 304
+// и возвращает true; иначе тело ответа формирует вызывающий (для ответов не в JSON).
+func checkCached(c *gin.Context, tag string, lastModified time.Time) bool {
+	c.Header("ETag", tag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request, tag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+func notModified(r *http.Request, tag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		// Слабое сравнение (RFC 9110, 13.1.2): W/ не учитывается.
+		want := strings.TrimPrefix(tag, "W/")
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || strings.TrimPrefix(t, "W/") == want {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// listETag — слабый ETag списка из числа записей и последнего updated_at:
+// меняется при добавлении, изменении и удалении записей.
+func listETag(n int, last time.Time) string {
+	return `W/"` + strconv.Itoa(n) + "-" + strconv.FormatInt(last.UnixMicro(), 10) + `"`
+}
+
+// bodyETag — слабый ETag по содержимому ответа, для агрегатов без updated_at.
+func bodyETag(body interface{}) string {
+	data, _ := json.Marshal(body)
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// lastUpdated — самый поздний updated_at среди записей списка.
+func lastUpdated[T any](items []T, updatedAt func(*T) time.Time) time.Time {
+	var last time.Time
+	for i := range items {
+		if t := updatedAt(&items[i]); t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// timeOrZero — для моделей, где updated_at может быть NULL.
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
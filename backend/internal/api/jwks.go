@@ -0,0 +1,14 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS — открытые ключи подписи токенов. Кэш короче JWKSPrepublish: новый ключ
+// попадает к проверяющим сервисам до того, как им начнут подписывать.
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/auth"
+	"backend/internal/logging"
+	"backend/internal/mfa"
+	"backend/internal/models"
+)
+
+var errInvalidMFACode = apperr.Unauthorized("Invalid verification code")
+
+// currentClaims — claims токена, проверенного AuthMiddleware.
+func currentClaims(c *gin.Context) *auth.JWTClaims {
+	claims, _ := c.MustGet("claims").(*auth.JWTClaims)
+	return claims
+}
+
+// MFAVerify — второй шаг логина: токен второго шага + код TOTP или код восстановления.
+// Неверные коды учитываются так же, как неверные пароли (задержка и блокировка).
+func (h *Handler) MFAVerify(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	claims, err := h.keys.ValidateToken(c.Request.Context(), req.MFAToken)
+	if err != nil || claims.Purpose != auth.PurposeMFAVerify {
+		c.Error(apperr.Unauthorized("Invalid or expired MFA token"))
+		return
+	}
+	endpoint := claims.Endpoint
+
+	ctx := c.Request.Context()
+	key := "mfa:" + strconv.Itoa(claims.UserID)
+	if wait := h.loginBackoff.Wait(key); wait > 0 {
+		countLogin(endpoint, "throttled")
+		tooManyRequests(c, wait, "Too many verification attempts, try again later")
+		return
+	}
+
+	user, err := h.store.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get user"))
+		return
+	}
+	if !user.TOTPEnabled {
+		c.Error(apperr.Unauthorized("Invalid or expired MFA token"))
+		return
+	}
+	// Блокировка могла наступить уже после первого шага — в том числе из-за неверных кодов.
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		logging.FromContext(ctx).Warn("Login rejected: account locked", "endpoint", endpoint, "user_id", user.ID)
+		countLogin(endpoint, "locked")
+		tooManyRequests(c, time.Until(*user.LockedUntil), "Too many login attempts, try again later")
+		return
+	}
+
+	ok := false
+	switch {
+	case req.Code != "":
+		ok, err = h.checkTOTP(ctx, user, req.Code)
+		if err != nil {
+			c.Error(apperr.Wrap(err, "Failed to check verification code"))
+			return
+		}
+	case req.RecoveryCode != "":
+		ok, err = h.store.UseRecoveryCode(ctx, user.ID, mfa.HashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			c.Error(apperr.Wrap(err, "Failed to check recovery code"))
+			return
+		}
+		if ok {
+			logging.FromContext(ctx).Warn("Recovery code used", "user_id", user.ID)
+		}
+	default:
+		c.Error(apperr.BadRequest("code or recovery_code required"))
+		return
+	}
+
+	if !ok {
+		h.loginBackoff.Fail(key)
+		h.recordLoginFailure(c, user)
+		logging.FromContext(ctx).Warn("Login failed: invalid MFA code", "endpoint", endpoint, "user_id", user.ID)
+		countLogin(endpoint, "invalid_mfa_code")
+		c.Error(errInvalidMFACode)
+		return
+	}
+
+	h.loginBackoff.Reset(key)
+	h.issueToken(c, endpoint, user)
+}
+
+// MFAEnroll начинает подключение 2FA: выдаёт секрет и otpauth:// URI для QR-кода.
+// Доступен с обычным токеном и с токеном обязательной настройки 2FA.
+func (h *Handler) MFAEnroll(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, err := h.store.GetUserByID(ctx, currentClaims(c).UserID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get user"))
+		return
+	}
+
+	secret, uri, err := mfa.GenerateSecret(user.Email)
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate secret"))
+		return
+	}
+	if err := h.store.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to start enrollment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAEnrollResponse{Secret: secret, OTPAuthURI: uri})
+}
+
+// MFAConfirm завершает подключение 2FA кодом из приложения и выдаёт коды восстановления.
+// Если подключение шло по токену обязательной настройки, заодно выдаётся обычный JWT.
+func (h *Handler) MFAConfirm(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	claims := currentClaims(c)
+	user, err := h.store.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get user"))
+		return
+	}
+	if user.TOTPEnabled {
+		c.Error(apperr.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.Error(apperr.BadRequest("Enrollment not started"))
+		return
+	}
+	ok, err := h.checkTOTP(ctx, user, req.Code)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check verification code"))
+		return
+	}
+	if !ok {
+		c.Error(errInvalidMFACode)
+		return
+	}
+
+	codes, hashes, err := mfa.NewRecoveryCodes()
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate recovery codes"))
+		return
+	}
+	if err := h.store.EnableTOTP(ctx, user.ID, hashes); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to enable two-factor authentication"))
+		return
+	}
+	logging.FromContext(ctx).Info("Two-factor authentication enabled", "user_id", user.ID)
+
+	resp := models.MFAConfirmResponse{RecoveryCodes: codes}
+	if claims.Purpose == auth.PurposeMFAEnroll {
+		user.TOTPEnabled = true
+		login := h.loginResponse(c, claims.Endpoint, user)
+		if login == nil {
+			return
+		}
+		resp.Token, resp.User = login.Token, &login.User
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// MFADisable выключает 2FA по текущему коду; запрещено, если 2FA обязательна для роли.
+func (h *Handler) MFADisable(c *gin.Context) {
+	user := h.verifiedMFAUser(c)
+	if user == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	required, err := h.store.MFARequired(ctx, user.Role)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check MFA policy"))
+		return
+	}
+	if required {
+		c.Error(apperr.Forbidden("Two-factor authentication is required for your role"))
+		return
+	}
+
+	if err := h.store.DisableTOTP(ctx, user.ID); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to disable two-factor authentication"))
+		return
+	}
+	logging.FromContext(ctx).Warn("Two-factor authentication disabled", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// MFARecoveryCodes выдаёт новый набор кодов восстановления; старые перестают действовать.
+func (h *Handler) MFARecoveryCodes(c *gin.Context) {
+	user := h.verifiedMFAUser(c)
+	if user == nil {
+		return
+	}
+
+	codes, hashes, err := mfa.NewRecoveryCodes()
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate recovery codes"))
+		return
+	}
+	if err := h.store.ReplaceRecoveryCodes(c.Request.Context(), user.ID, hashes); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to replace recovery codes"))
+		return
+	}
+	c.JSON(http.StatusOK, models.MFAConfirmResponse{RecoveryCodes: codes})
+}
+
+// verifiedMFAUser — текущий пользователь с включённой 2FA, подтвердивший действие кодом.
+func (h *Handler) verifiedMFAUser(c *gin.Context) *models.User {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.store.GetUserByID(ctx, currentClaims(c).UserID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get user"))
+		return nil
+	}
+	if !user.TOTPEnabled {
+		c.Error(apperr.BadRequest("Two-factor authentication is not enabled"))
+		return nil
+	}
+	ok, err := h.checkTOTP(ctx, user, req.Code)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check verification code"))
+		return nil
+	}
+	if !ok {
+		c.Error(errInvalidMFACode)
+		return nil
+	}
+	return user
+}
+
+// checkTOTP проверяет код TOTP и запоминает его шаг, чтобы тот же код нельзя было
+// предъявить повторно. Условное обновление в БД закрывает и параллельные повторы.
+func (h *Handler) checkTOTP(ctx context.Context, user *models.User, code string) (bool, error) {
+	step, ok := mfa.Validate(code, user.TOTPSecret, user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+	return h.store.UseTOTPStep(ctx, user.ID, step)
+}
+
+// GetMFAPolicy — для каких ролей 2FA обязательна.
+func (h *Handler) GetMFAPolicy(c *gin.Context) {
+	policies, err := h.store.GetMFAPolicies(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get MFA policy"))
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// SetMFAPolicy делает 2FA обязательной (или необязательной) для роли.
+// Пользователи без 2FA при следующем входе получат токен обязательной настройки.
+func (h *Handler) SetMFAPolicy(c *gin.Context) {
+	var req models.MFAPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	exists, err := h.perms.RoleExists(c.Request.Context(), req.Role)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check role"))
+		return
+	}
+	if !exists {
+		c.Error(apperr.BadRequest("Unknown role"))
+		return
+	}
+
+	if err := h.store.SetMFAPolicy(c.Request.Context(), &req); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to update MFA policy"))
+		return
+	}
+	logging.FromContext(c.Request.Context()).Info("MFA policy updated", "role", req.Role, "required", req.Required, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+)
+
+func TestMFAVerifyLockedAccount(t *testing.T) {
+	h, s := testHandler(t)
+	ctx := context.Background()
+	keys, err := auth.NewKeySet(ctx, s, h.cfg.Auth.JWTAlgorithm, h.cfg.Auth.JWTKeyRotation, h.cfg.Auth.JWTKeyOverlap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.keys = keys
+
+	const secret = "JBSWY3DPEHPK3PXP"
+	user := &models.User{Email: fmt.Sprintf("mfa-lock-%d@smolensk.example", time.Now().UnixNano()), Password: "hash", Role: "editor", IsActive: true}
+	if err := s.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnableTOTP(ctx, user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Блокировка наступила после первого шага: токен второго шага уже выдан.
+	token, err := keys.GenerateMFAToken(*user, auth.PurposeMFAVerify, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LockUser(ctx, user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.POST("/mfa/verify", h.MFAVerify)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serve(r, http.MethodPost, "/mfa/verify", fmt.Sprintf(`{"mfa_token": %q, "code": %q}`, token, code), nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked account: status = %d, want 429: %s", w.Code, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After header missing")
+	}
+
+	// После снятия блокировки тот же код принимается один раз.
+	if err := s.ClearLockout(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(r, http.MethodPost, "/mfa/verify", fmt.Sprintf(`{"mfa_token": %q, "code": %q}`, token, code), nil); w.Code != http.StatusOK {
+		t.Fatalf("unlocked account: status = %d: %s", w.Code, w.Body)
+	}
+	if w := serve(r, http.MethodPost, "/mfa/verify", fmt.Sprintf(`{"mfa_token": %q, "code": %q}`, token, code), nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("replayed code: status = %d, want 401", w.Code)
+	}
+}
@@ -1,77 +1,100 @@
 package api
 
 import (
+    "log/slog"
     "net/http"
+    "strconv"
     "strings"
+    "time"
 
     "github.com/gin-gonic/gin"
-    "backend/config"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/trace"
+    "backend/internal/apperr"
     "backend/internal/auth"
+    "backend/internal/logging"
+    "backend/internal/metrics"
+    "backend/internal/rbac"
+    "backend/internal/store"
+    "backend/internal/tracing"
+    "backend/pkg"
 )
 
 // AuthMiddleware валидирует JWT, извлекает user_id и role и кладёт их в контекст.
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// Принимаются только обычные токены доступа; токены второго шага логина
+// (purpose) пропускаются, только если перечислены в purposes.
+// Токен доступа действует, пока не отозвана его сессия (claim sid).
+func AuthMiddleware(keys *auth.KeySet, s *store.Store, purposes ...string) gin.HandlerFunc {
+    allowedPurposes := append([]string{""}, purposes...)
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+            c.Error(apperr.Unauthorized("Authorization header required"))
             c.Abort()
             return
         }
 
         parts := strings.Split(authHeader, " ")
         if len(parts) != 2 || parts[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+            c.Error(apperr.Unauthorized("Invalid token format"))
             c.Abort()
             return
         }
 
-        claims, err := auth.ValidateToken(parts[1], cfg.JWTSecret)
-        if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+        claims, err := keys.ValidateToken(c.Request.Context(), parts[1])
+        if err != nil || !pkg.Contains(allowedPurposes, claims.Purpose) {
+            c.Error(apperr.Unauthorized("Invalid token"))
             c.Abort()
             return
         }
 
+        if claims.Purpose == "" {
+            if claims.SessionID == "" {
+                c.Error(apperr.Unauthorized("Invalid token"))
+                c.Abort()
+                return
+            }
+            active, err := s.CheckSession(c.Request.Context(), claims.SessionID, sessionTouchInterval)
+            if err != nil {
+                c.Error(apperr.Wrap(err, "Failed to check session"))
+                c.Abort()
+                return
+            }
+            if !active {
+                c.Error(apperr.Unauthorized("Session has been revoked or expired"))
+                c.Abort()
+                return
+            }
+        }
+
         c.Set("user_id", claims.UserID)
         c.Set("role", claims.Role)
+        c.Set("session_id", claims.SessionID)
+        c.Set("claims", claims)
         c.Next()
     }
 }
 
-// RequireAdmin — доступ только для роли admin (строго).
-// В дальнейшем сюда можно добавлять дополнительные условия/атрибуты.
-func RequireAdmin() gin.HandlerFunc {
+// RequirePermission пускает только роли, у которых есть permission в матрице прав.
+func RequirePermission(perms *rbac.Cache, permission string) gin.HandlerFunc {
     return func(c *gin.Context) {
-        role, ok := c.Get("role")
-        if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Role not found in context"})
+        role := c.GetString("role")
+        if role == "" {
+            c.Error(apperr.Unauthorized("Role not found in context"))
             c.Abort()
             return
         }
-        if roleStr, _ := role.(string); roleStr != "admin" {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Admin permissions required"})
+        ok, err := perms.Has(c.Request.Context(), role, permission)
+        if err != nil {
+            c.Error(apperr.Wrap(err, "Failed to check permissions"))
             c.Abort()
             return
         }
-        c.Next()
-    }
-}
-
-// RequireEditor — на текущий момент пускает и admin, и editor,
-// чтобы у редактора были те же права, что и у админа.
-// Позже можно будет сузить до editor‑списка (или сделать матрицу прав).
-func RequireEditor() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        role, ok := c.Get("role")
         if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Role not found in context"})
-            c.Abort()
-            return
-        }
-        roleStr, _ := role.(string)
-        if roleStr != "editor" && roleStr != "admin" {
-            c.JSON(http.StatusForbidden, gin.H{"error": "Editor or Admin permissions required"})
+            c.Error(apperr.Forbidden("Permission required: " + permission))
             c.Abort()
             return
         }
@@ -79,95 +102,115 @@ func RequireEditor() gin.HandlerFunc {
     }
 }
 
-// Обновлённый CORS‑мидлвар
-func CORSMiddleware() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        origin := c.Request.Header.Get("Origin")
-
-        allowedOrigins := []string{
-            "http://localhost:3000",
-            "http://localhost:5173",
-            "http://localhost:8080",
-            "http://127.0.0.1:3000",
-            "http://127.0.0.1:5173",
-            "http://127.0.0.1:8080",
-        }
+const (
+    requestIDHeader = "X-Request-ID"
+    maxRequestIDLen = 128
+)
 
-        originAllowed := false
-        for _, allowed := range allowedOrigins {
-            if origin == allowed {
-                originAllowed = true
-                break
-            }
+// RequestID берёт X-Request-ID клиента (если он безопасный) или генерирует новый,
+// возвращает его в ответе и кладёт в контекст запроса логгер с request_id.
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id := c.GetHeader(requestIDHeader)
+        if !validRequestID(id) {
+            id = pkg.GenerateRandomString(16)
         }
+        c.Set("request_id", id)
+        c.Header(requestIDHeader, id)
 
-        if originAllowed {
-            c.Header("Access-Control-Allow-Origin", origin)
-        } else {
-            c.Header("Access-Control-Allow-Origin", "*")
-        }
+        ctx := c.Request.Context()
+        logger := logging.FromContext(ctx).With("request_id", id)
+        c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
+        c.Next()
+    }
+}
 
-        c.Header("Access-Control-Allow-Credentials", "true")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-        c.Header("Access-Control-Allow-Headers",
-            "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, " +
-                "Authorization, accept, origin, Cache-Control, X-Requested-With, " +
-                "X-HTTP-Method-Override, X-Forwarded-For")
-        c.Header("Access-Control-Expose-Headers",
-            "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, " +
-                "Content-Type, Cache-Control, Expires, Last-Modified")
-        c.Header("Access-Control-Max-Age", "3600")
-
-        if c.Request.Method == "OPTIONS" {
-            c.Header("Access-Control-Allow-Origin", origin)
-            c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-            c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With")
-            c.AbortWithStatus(http.StatusNoContent)
-            return
+// validRequestID пропускает только короткие id из [A-Za-z0-9._-],
+// чтобы чужой заголовок не мог испортить логи.
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestIDLen {
+        return false
+    }
+    for _, r := range id {
+        switch {
+        case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+        default:
+            return false
         }
-
-        c.Next()
     }
+    return true
 }
 
-// Более строгий CORS для продакшена
-func ProductionCORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
+// Tracing открывает серверный спан на запрос, продолжая traceparent клиента,
+// и добавляет trace_id в логгер запроса.
+func Tracing() gin.HandlerFunc {
     return func(c *gin.Context) {
-        origin := c.Request.Header.Get("Origin")
-
-        originAllowed := false
-        for _, allowed := range allowedOrigins {
-            if origin == allowed {
-                originAllowed = true
-                c.Header("Access-Control-Allow-Origin", origin)
-                break
-            }
+        ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+        route := c.FullPath()
+        if route == "" {
+            route = "unmatched"
         }
-        if !originAllowed {
-            c.AbortWithStatus(http.StatusForbidden)
-            return
+        ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+            trace.WithSpanKind(trace.SpanKindServer),
+            trace.WithAttributes(
+                attribute.String("http.request.method", c.Request.Method),
+                attribute.String("http.route", route),
+            ),
+        )
+        defer span.End()
+
+        if sc := span.SpanContext(); sc.IsValid() {
+            ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("trace_id", sc.TraceID().String()))
         }
+        c.Request = c.Request.WithContext(ctx)
 
-        c.Header("Access-Control-Allow-Credentials", "true")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
-        c.Header("Access-Control-Max-Age", "86400")
+        c.Next()
 
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(http.StatusNoContent)
-            return
+        status := c.Writer.Status()
+        span.SetAttributes(attribute.Int("http.response.status_code", status))
+        if status >= http.StatusInternalServerError {
+            span.SetStatus(codes.Error, http.StatusText(status))
         }
+    }
+}
 
+// RequestLogger пишет одну строку JSON-лога на запрос через логгер запроса
+// (с request_id и trace_id).
+func RequestLogger() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
         c.Next()
+
+        status := c.Writer.Status()
+        level := slog.LevelInfo
+        if status >= http.StatusInternalServerError {
+            level = slog.LevelError
+        }
+        logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "request",
+            "method", c.Request.Method,
+            "path", c.Request.URL.Path,
+            "route", c.FullPath(),
+            "status", status,
+            "latency_ms", time.Since(start).Milliseconds(),
+            "ip", c.ClientIP(),
+        )
     }
 }
 
-// Логирование запросов (полезно при отладке CORS)
-func RequestLogger() gin.HandlerFunc {
+// Metrics считает запросы и их длительность по шаблону маршрута Gin,
+// чтобы id в пути не раздували число временных рядов.
+func Metrics() gin.HandlerFunc {
     return func(c *gin.Context) {
-        // origin := c.Request.Header.Get("Origin")
-        // method := c.Request.Method
-        // log.Printf("CORS Request: %s %s from %s", method, c.Request.URL.Path, origin)
+        start := time.Now()
         c.Next()
+
+        route := c.FullPath()
+        if route == "" {
+            route = "unmatched"
+        }
+        status := strconv.Itoa(c.Writer.Status())
+        metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+        metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
     }
 }
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/logging"
+	"backend/internal/oidc"
+)
+
+// oidcFlowCookie хранит state, nonce и PKCE verifier между редиректом к провайдеру и callback.
+const oidcFlowCookie = "oidc_flow"
+
+const oidcCookiePath = "/api/auth/oidc"
+
+// OIDCLogin отправляет браузер на страницу входа OIDC-провайдера.
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		c.Error(apperr.NotFound("OIDC login is not configured"))
+		return
+	}
+
+	authURL, flow, err := h.oidc.AuthURL(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Internal(err, "Identity provider is unavailable"))
+		return
+	}
+	value, err := oidc.EncodeFlow(flow, []byte(h.cfg.Auth.JWTSecret))
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to start login"))
+		return
+	}
+
+	// Lax: cookie должна прийти с редиректом от провайдера (top-level GET).
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, value, int(oidc.FlowTTL.Seconds()), oidcCookiePath, "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback завершает вход: проверяет state, меняет code на токены,
+// сопоставляет группы роли и создаёт или обновляет пользователя.
+// 2FA здесь не запрашивается — второй фактор на стороне провайдера.
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		c.Error(apperr.NotFound("OIDC login is not configured"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := logging.FromContext(ctx)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, "", -1, oidcCookiePath, "", c.Request.TLS != nil, true)
+
+	if e := c.Query("error"); e != "" {
+		logger.Warn("OIDC login rejected by provider", "error", e, "description", c.Query("error_description"))
+		countLogin("oidc", "invalid_credentials")
+		c.Error(apperr.Unauthorized("Login was rejected by the identity provider"))
+		return
+	}
+
+	value, _ := c.Cookie(oidcFlowCookie)
+	flow, err := oidc.DecodeFlow(value, []byte(h.cfg.Auth.JWTSecret))
+	if err != nil || c.Query("state") != flow.State || c.Query("code") == "" {
+		countLogin("oidc", "invalid_credentials")
+		c.Error(apperr.BadRequest("Invalid or expired login state, start again"))
+		return
+	}
+
+	identity, err := h.oidc.Exchange(ctx, c.Query("code"), flow)
+	if err != nil {
+		logger.Warn("OIDC login failed", "err", err)
+		countLogin("oidc", "invalid_credentials")
+		c.Error(apperr.Unauthorized("OIDC login failed"))
+		return
+	}
+
+	role, err := h.oidc.RoleFor(identity.Groups)
+	if err == nil {
+		var exists bool
+		if exists, err = h.perms.RoleExists(ctx, role); err != nil {
+			countLogin("oidc", "error")
+			c.Error(apperr.Wrap(err, "Failed to check role"))
+			return
+		}
+		if !exists {
+			logger.Error("OIDC group mapped to unknown role", "role", role)
+			err = oidc.ErrNoRole
+		}
+	}
+	if errors.Is(err, oidc.ErrNoRole) {
+		logger.Warn("OIDC login denied: no role for groups", "subject", identity.Subject, "groups", identity.Groups)
+		countLogin("oidc", "forbidden")
+		c.Error(apperr.Forbidden("No role is assigned to your groups"))
+		return
+	}
+
+	user, err := h.store.UpsertOIDCUser(ctx, identity.Issuer, identity.Subject, identity.Email, identity.EmailVerified, role)
+	if err != nil {
+		countLogin("oidc", "error")
+		c.Error(apperr.Wrap(err, "Failed to provision user"))
+		return
+	}
+
+	resp := h.loginResponse(c, "oidc", user)
+	if resp == nil {
+		return
+	}
+	if h.cfg.Auth.OIDC.SuccessURL == "" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	// Токен во фрагменте: он не уходит на сервер фронтенда и не попадает в его логи.
+	c.Redirect(http.StatusFound, h.cfg.Auth.OIDC.SuccessURL+"#token="+url.QueryEscape(resp.Token))
+}
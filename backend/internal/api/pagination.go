@@ -0,0 +1,29 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+)
+
+// pageParams читает ?limit= (по умолчанию def, не больше max) и ?offset= для постраничных списков админки.
+func pageParams(c *gin.Context, def, max int) (limit, offset int, err error) {
+	limit = def
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > max {
+			return 0, 0, apperr.BadRequest("limit must be between 1 and " + strconv.Itoa(max))
+		}
+		limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, apperr.BadRequest("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+	return limit, offset, nil
+}
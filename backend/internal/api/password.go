@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/auth"
+	"backend/internal/logging"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/rbac"
+	"backend/internal/store"
+	"backend/internal/validation"
+)
+
+// mailTimeout — сколько ждать отправку письма, которое уходит после ответа клиенту.
+const mailTimeout = 30 * time.Second
+
+// ForgotPassword отправляет ссылку сброса пароля. Ответ всегда одинаковый и не ждёт письма,
+// чтобы по нему нельзя было узнать, есть ли такой email.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a reset link has been sent"})
+
+	ctx := c.Request.Context()
+	if ok, _ := h.resetLimit.Allow(email); !ok {
+		logging.FromContext(ctx).Warn("Password reset throttled", "email", email)
+		return
+	}
+	go h.sendPasswordReset(context.WithoutCancel(ctx), email)
+}
+
+func (h *Handler) sendPasswordReset(ctx context.Context, email string) {
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	logger := logging.FromContext(ctx)
+
+	user, err := h.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !apperr.IsNotFound(err) {
+			logger.Error("Password reset: failed to get user", "err", err)
+		}
+		return
+	}
+	// Пароля нет у входящих через SSO и у приглашённых, ещё не принявших приглашение.
+	if user.Password == "" {
+		logger.Info("Password reset skipped: account has no password", "user_id", user.ID)
+		return
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		logger.Error("Password reset: failed to generate token", "err", err)
+		return
+	}
+	if err := h.store.CreateUserToken(ctx, user.ID, store.TokenPasswordReset, hash, time.Now().Add(h.cfg.Auth.PasswordResetTTL)); err != nil {
+		return
+	}
+	if err := h.sendLink(ctx, mailer.TemplatePasswordReset, user.Email, "/reset-password", token, h.cfg.Auth.PasswordResetTTL, ""); err != nil {
+		logger.Error("Password reset: failed to send email", "user_id", user.ID, "err", err)
+		return
+	}
+	logger.Info("Password reset link sent", "user_id", user.ID)
+}
+
+// ResetPassword задаёт новый пароль по ссылке из письма сброса.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	h.setPasswordWithToken(c, store.TokenPasswordReset)
+}
+
+// AcceptInvite задаёт пароль по ссылке из приглашения.
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	h.setPasswordWithToken(c, store.TokenInvite)
+}
+
+func (h *Handler) setPasswordWithToken(c *gin.Context, purpose string) {
+	var req models.SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenHash := auth.HashOpaqueToken(req.Token)
+	user, err := h.store.GetUserByToken(ctx, purpose, tokenHash)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check link"))
+		return
+	}
+	if !validate(c, validation.Password(req.Password, user.Email, h.cfg.Auth.PasswordMinLength)) {
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to hash password"))
+		return
+	}
+	if _, err := h.store.SetPasswordWithToken(ctx, purpose, tokenHash, passwordHash); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to set password"))
+		return
+	}
+
+	logging.FromContext(ctx).Info("Password set by link", "user_id", user.ID, "purpose", purpose)
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// InviteUser — админ приглашает сотрудника: учётная запись без пароля и письмо со ссылкой.
+func (h *Handler) InviteUser(c *gin.Context) {
+	var req models.InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	ctx := c.Request.Context()
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	exists, err := h.perms.RoleExists(ctx, req.Role)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check role"))
+		return
+	}
+	if !exists {
+		c.Error(apperr.BadRequest("Unknown role"))
+		return
+	}
+	if !h.mayAssignRole(c, req.Role) {
+		return
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate token"))
+		return
+	}
+	user, err := h.store.CreateInvitedUser(ctx, email, req.Role, hash, time.Now().Add(h.cfg.Auth.InviteTTL))
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to invite user"))
+		return
+	}
+
+	logger := logging.FromContext(ctx)
+	sent := true
+	if err := h.sendLink(ctx, mailer.TemplateInvite, email, "/invite", token, h.cfg.Auth.InviteTTL, req.Role); err != nil {
+		logger.Error("Invite: failed to send email", "user_id", user.ID, "err", err)
+		sent = false
+	}
+	logger.Info("User invited", "user_id", user.ID, "role", req.Role, "email_sent", sent, "by", c.GetInt("user_id"))
+
+	user.Password = ""
+	c.JSON(http.StatusCreated, models.InviteUserResponse{User: *user, EmailSent: sent})
+}
+
+// mayAssignRole проверяет, что текущий пользователь может выдать role: с roles:manage —
+// любую (он и так может менять матрицу), иначе только роль, чьи права есть у него самого.
+// Иначе users:manage позволял бы пригласить администратора. При отказе кладёт ошибку в c.
+func (h *Handler) mayAssignRole(c *gin.Context, role string) bool {
+	ctx := c.Request.Context()
+	own := c.GetString("role")
+	ok, err := h.perms.Has(ctx, own, rbac.RolesManage)
+	if err == nil && !ok {
+		ok, err = h.perms.Covers(ctx, own, role)
+	}
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to check permissions"))
+		return false
+	}
+	if !ok {
+		c.Error(apperr.Forbidden("Cannot assign a role with permissions you do not have"))
+		return false
+	}
+	return true
+}
+
+// sendLink отправляет письмо template со ссылкой APP_URL+path?token=...
+func (h *Handler) sendLink(ctx context.Context, template, to, path, token string, ttl time.Duration, role string) error {
+	link := strings.TrimRight(h.cfg.HTTP.AppURL, "/") + path + "?token=" + url.QueryEscape(token)
+	msg, err := mailer.Render(template, to, mailer.LinkData{Link: link, ExpiresIn: formatTTL(ttl), Role: role})
+	if err != nil {
+		return err
+	}
+	return h.mail.Send(ctx, msg)
+}
+
+// formatTTL — срок действия ссылки для текста письма: "1 ч", "30 мин".
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d ч", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d мин", int(d.Minutes()))
+}
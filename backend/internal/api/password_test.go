@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/rbac"
+)
+
+func TestInviteUserRejectsWiderRole(t *testing.T) {
+	h := &Handler{perms: rbac.NewCache(func(context.Context) (map[string][]string, error) {
+		return map[string][]string{
+			rbac.AdminRole: {rbac.UsersManage, rbac.RolesManage, rbac.NewsWrite},
+			"hr":           {rbac.UsersManage, rbac.NewsWrite},
+			"moderator":    {rbac.FeedbackModerate},
+		}, nil
+	}, time.Minute)}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.POST("/invite", func(c *gin.Context) { c.Set("role", "hr") }, h.InviteUser)
+
+	// Отказ происходит до обращения к БД: store в этом Handler нет.
+	for _, role := range []string{rbac.AdminRole, "moderator"} {
+		w := serve(r, http.MethodPost, "/invite", `{"email": "new@smolensk.example", "role": "`+role+`"}`, nil)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("hr inviting %s: status = %d, want 403: %s", role, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/pkg"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch разбирает тело PUT/PATCH как JSON Merge Patch (RFC 7396) в dst —
+// структуру Update*Request с указателями: отсутствующие ключи остаются nil и не меняются.
+// null допустим только для колонок из nullable (он превращается в "" и store пишет NULL),
+// для остальных это ошибка: обязательное поле нельзя "удалить".
+func bindMergePatch(c *gin.Context, dst interface{}, nullable ...string) bool {
+	if ct := c.ContentType(); ct != "" {
+		if mt, _, err := mime.ParseMediaType(ct); err != nil || (mt != gin.MIMEJSON && mt != mergePatchContentType) {
+			c.Error(apperr.New(apperr.KindUnsupportedMediaType, "Content-Type must be application/json or "+mergePatchContentType))
+			return false
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return false
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		c.Error(apperr.BadRequest("Patch document must be a JSON object"))
+		return false
+	}
+
+	for key, raw := range doc {
+		if string(raw) != "null" {
+			continue
+		}
+		if !pkg.Contains(nullable, key) {
+			c.Error(apperr.BadRequest("Field '" + key + "' cannot be null"))
+			return false
+		}
+		doc[key] = json.RawMessage(`""`)
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return false
+	}
+	if err := json.Unmarshal(normalized, dst); err != nil {
+		c.Error(apperr.BadRequest(err.Error()))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/models"
+)
+
+func TestBindMergePatch(t *testing.T) {
+	type result struct {
+		ok  bool
+		req models.UpdateTeamMemberRequest
+	}
+	gin.SetMode(gin.TestMode)
+	var got result
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.PATCH("/team", func(c *gin.Context) {
+		got = result{}
+		got.ok = bindMergePatch(c, &got.req, "photo_url")
+		if got.ok {
+			c.Status(http.StatusNoContent)
+		}
+	})
+
+	str := func(p *string) string {
+		if p == nil {
+			return "<nil>"
+		}
+		return strconv.Quote(*p)
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		wantName    string // ожидаемое Name
+		wantPhoto   string // ожидаемое PhotoURL: <nil> — поле не передано
+	}{
+		{"partial", "application/json", `{"name": "Петров"}`, http.StatusNoContent, `"Петров"`, "<nil>"},
+		{"merge-patch type", "application/merge-patch+json", `{"photo_url": "https://cdn.example/p.jpg"}`, http.StatusNoContent, "<nil>", `"https://cdn.example/p.jpg"`},
+		{"null clears nullable", "application/json", `{"photo_url": null}`, http.StatusNoContent, "<nil>", `""`},
+		{"empty object", "application/json", `{}`, http.StatusNoContent, "<nil>", "<nil>"},
+		{"null on required field", "application/json", `{"name": null}`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"array", "application/json", `[{"name": "Петров"}]`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"string", "application/json", `"Петров"`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"null document", "application/json", `null`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"not JSON", "application/json", `name=Петров`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"wrong field type", "application/json", `{"name": 5}`, http.StatusBadRequest, "<nil>", "<nil>"},
+		{"form content type", "application/x-www-form-urlencoded", `{"name": "Петров"}`, http.StatusUnsupportedMediaType, "<nil>", "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, http.MethodPatch, "/team", tt.body, map[string]string{"Content-Type": tt.contentType})
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if !got.ok {
+				return
+			}
+			if s := str(got.req.Name); s != tt.wantName {
+				t.Errorf("name = %s, want %s", s, tt.wantName)
+			}
+			if s := str(got.req.PhotoURL); s != tt.wantPhoto {
+				t.Errorf("photo_url = %s, want %s", s, tt.wantPhoto)
+			}
+			if got.req.Position != nil || got.req.Experience != nil {
+				t.Errorf("absent fields were set: %+v", got.req)
+			}
+		})
+	}
+}
+
+func TestUpdateTeamMergePatch(t *testing.T) {
+	h, s := testHandler(t)
+	ctx := context.Background()
+	photo := "https://cdn.example/ivanov.jpg"
+	m := &models.TeamMember{Name: "Иванов", Position: "Инженер", Experience: "5 лет", PhotoURL: &photo}
+	if err := s.CreateTeamMember(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.DeleteTeam(ctx, m.ID) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.PATCH("/team/:id", h.UpdateTeam)
+	target := "/team/" + strconv.Itoa(m.ID)
+
+	w := serve(r, http.MethodPatch, target, `{"photo_url": null, "version": `+strconv.Itoa(m.Version)+`}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	got, err := s.GetTeamMemberByID(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PhotoURL != nil {
+		t.Errorf("photo_url = %q, want NULL", *got.PhotoURL)
+	}
+	if got.Name != m.Name || got.Position != m.Position || got.Experience != m.Experience {
+		t.Errorf("absent fields changed: %+v", got)
+	}
+
+	if w := serve(r, http.MethodPatch, target, `[]`, map[string]string{"If-Match": etag(got.Version)}); w.Code != http.StatusBadRequest {
+		t.Errorf("array body: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/ratelimit"
+)
+
+// RateLimit ограничивает частоту запросов с одного IP (token bucket).
+// При превышении отвечает 429 с Retry-After.
+func RateLimit(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, wait := l.Allow(c.ClientIP()); !ok {
+			tooManyRequests(c, wait, "Rate limit exceeded")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// tooManyRequests отвечает 429 и подсказывает клиенту, через сколько секунд повторить.
+func tooManyRequests(c *gin.Context, wait time.Duration, message string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.Error(apperr.New(apperr.KindTooManyRequests, message).With("retry_after", seconds))
+}
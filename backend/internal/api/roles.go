@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/rbac"
+	"backend/pkg"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// GetRoles — матрица прав: роли и их права.
+func (h *Handler) GetRoles(c *gin.Context) {
+	roles, err := h.store.GetRoles(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get roles"))
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetPermissions — все права, которые можно выдать роли.
+func (h *Handler) GetPermissions(c *gin.Context) {
+	perms, err := h.store.GetPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get permissions"))
+		return
+	}
+	c.JSON(http.StatusOK, perms)
+}
+
+// CreateRole создаёт роль с набором прав.
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	if !roleNamePattern.MatchString(req.Name) {
+		c.Error(apperr.BadRequest("Role name must be 2-20 characters: a-z, 0-9, _"))
+		return
+	}
+
+	role := models.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+	if err := h.store.CreateRole(c.Request.Context(), &role); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to create role"))
+		return
+	}
+	h.perms.Invalidate()
+
+	logging.FromContext(c.Request.Context()).Info("Role created", "role", role.Name, "permissions", role.Permissions, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusCreated, role)
+}
+
+// SetRolePermissions заменяет набор прав роли.
+// Роль admin не может потерять roles:manage — иначе матрицу будет некому править.
+func (h *Handler) SetRolePermissions(c *gin.Context) {
+	var req models.SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	role := c.Param("name")
+	if role == rbac.AdminRole && !pkg.Contains(req.Permissions, rbac.RolesManage) {
+		c.Error(apperr.Conflict("Role admin must keep " + rbac.RolesManage))
+		return
+	}
+
+	if err := h.store.SetRolePermissions(c.Request.Context(), role, req.Permissions); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to update role permissions"))
+		return
+	}
+	h.perms.Invalidate()
+
+	logging.FromContext(c.Request.Context()).Info("Role permissions updated", "role", role, "permissions", req.Permissions, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, models.Role{Name: role, Permissions: req.Permissions})
+}
+
+// DeleteRole удаляет роль, если она никому не назначена (admin удалить нельзя).
+func (h *Handler) DeleteRole(c *gin.Context) {
+	role := c.Param("name")
+	if role == rbac.AdminRole {
+		c.Error(apperr.Conflict("Role admin cannot be deleted"))
+		return
+	}
+
+	if err := h.store.DeleteRole(c.Request.Context(), role); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to delete role"))
+		return
+	}
+	h.perms.Invalidate()
+
+	logging.FromContext(c.Request.Context()).Info("Role deleted", "role", role, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
+}
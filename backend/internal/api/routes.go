@@ -3,178 +3,277 @@ package api
 import (
     "github.com/gin-gonic/gin"
     "backend/config"
+    authpkg "backend/internal/auth"
+    "backend/internal/events"
+    "backend/internal/health"
+    "backend/internal/mailer"
+    "backend/internal/ratelimit"
+    "backend/internal/rbac"
     "backend/internal/store"
 )
 
-func RegisterRoutes(r *gin.Engine, s *store.Store, cfg *config.Config) {
-    r.Use(CORSMiddleware())
-
-    h := NewHandler(s, cfg)
+func RegisterRoutes(r *gin.Engine, s *store.Store, cfg *config.Config, hc *health.Checker, keys *authpkg.KeySet, mail mailer.Mailer, bus *events.Bus) {
+    // IP клиента (лимиты, блокировка входа, сессии) — из X-Forwarded-For только за своими прокси.
+    // Список уже проверен в validateCfg; nil — заголовкам не доверяем.
+    _ = r.SetTrustedProxies(cfg.HTTP.TrustedProxies)
+    r.Use(RequestID())
+    r.Use(Tracing())
+    r.Use(RequestLogger())
+    r.Use(Metrics())
+    r.Use(SecureHeaders(cfg.HTTP))
+    // Политика уже проверена в validateCfg при старте.
+    cors, _ := NewCORSPolicy(cfg, gin.Mode() == gin.ReleaseMode)
+    r.Use(CORS(cors))
+    r.Use(ErrorHandler())
+    r.NoRoute(routeNotFound)
+
+    h := NewHandler(s, cfg, keys, mail, bus)
+    publicLimit := RateLimit(ratelimit.NewLimiter(cfg.HTTP.RateLimitRPS, cfg.HTTP.RateLimitBurst))
+
+    // Живость и готовность (для docker healthcheck и балансировщика)
+    r.GET("/healthz", healthz(hc))
+    r.GET("/readyz", readyz(hc))
+
+    // Открытые ключи для проверки наших JWT другими сервисами
+    r.GET("/.well-known/jwks.json", h.JWKS)
 
     // Аутентификация — раздельные эндпоинты + общий
-    auth := r.Group("/api/auth")
+    auth := r.Group("/api/auth", publicLimit)
     {
         auth.POST("/admin/login", h.AdminLogin)
         auth.POST("/editor/login", h.EditorLogin)
         auth.POST("/login", h.Login) // общий логин
+        auth.POST("/mfa/verify", h.MFAVerify) // второй шаг логина при включённой 2FA
+
+        // Сброс пароля и приглашения по ссылке из письма
+        auth.POST("/password/forgot", h.ForgotPassword)
+        auth.POST("/password/reset", h.ResetPassword)
+        auth.POST("/invite/accept", h.AcceptInvite)
+
+        // Вход сотрудников через OIDC-провайдер
+        auth.GET("/oidc/login", h.OIDCLogin)
+        auth.GET("/oidc/callback", h.OIDCCallback)
+    }
+
+    // Свои сессии: список и завершение (DELETE текущей — выход)
+    sessions := r.Group("/api/auth/sessions", cacheControl(cachePrivate), AuthMiddleware(keys, s))
+    {
+        sessions.GET("", h.GetMySessions)
+        sessions.DELETE("/:id", h.RevokeMySession)
+    }
+
+    // Подключение 2FA: обычный токен или токен обязательной настройки из логина
+    mfaSetup := r.Group("/api/account/mfa", AuthMiddleware(keys, s, authpkg.PurposeMFAEnroll))
+    {
+        mfaSetup.POST("/enroll", h.MFAEnroll)
+        mfaSetup.POST("/confirm", h.MFAConfirm)
+    }
+
+    // Управление своей 2FA (только обычный токен)
+    account := r.Group("/api/account", AuthMiddleware(keys, s))
+    {
+        account.DELETE("/mfa", h.MFADisable)
+        account.POST("/mfa/recovery-codes", h.MFARecoveryCodes)
     }
 
-    // Публичные маршруты (без авторизации)
-    api := r.Group("/api")
+    // Публичные маршруты (без авторизации). Списки браузер и прокси могут держать
+    // недолго, записи сверяют по ETag на каждый запрос.
+    short, long, revalidate := cacheControl(cacheShort), cacheControl(cacheLong), cacheControl(cacheRevalidate)
+    api := r.Group("/api", publicLimit)
     {
         // Новости
-        api.GET("/news", h.GetNews)
-        api.GET("/news/:id", h.GetNewsByID)
+        api.GET("/news", short, h.GetNews)
+        api.GET("/news/:id", revalidate, h.GetNewsByID)
 
         // Услуги
-        api.GET("/services", h.GetServices)
-        api.GET("/services/:id", h.GetServiceByID)
+        api.GET("/services", short, h.GetServices)
+        api.GET("/services/:id", revalidate, h.GetServiceByID)
 
         // Команда
-        api.GET("/team", h.GetTeam)
-        api.GET("/team/:id", h.GetTeamMemberByID)
+        api.GET("/team", short, h.GetTeam)
+        api.GET("/team/:id", revalidate, h.GetTeamMemberByID)
 
         // Проекты
-        api.GET("/projects", h.GetProjects)
+        api.GET("/projects", short, h.GetProjects)
+        api.GET("/projects/:id", revalidate, h.GetProjectByID)
 
         // Статистика/трафик
-        api.GET("/stats", h.GetStats)
-        api.GET("/traffic", h.GetTraffic)
+        api.GET("/stats", short, h.GetStats)
+        api.GET("/traffic", long, h.GetTraffic)
 
         // Данные из Excel — публичные
-        api.GET("/fines", h.GetFines)
-        api.GET("/evacuations", h.GetEvacuations)
-        api.GET("/evacuation-routes", h.GetEvacuationRoutes)
-        api.GET("/traffic-lights", h.GetTrafficLights)
+        api.GET("/fines", short, h.GetFines)
+        api.GET("/fines/:id", revalidate, h.GetFineByID)
+        api.GET("/evacuations", short, h.GetEvacuations)
+        api.GET("/evacuation-routes", long, h.GetEvacuationRoutes)
+        api.GET("/traffic-lights", short, h.GetTrafficLights)
+        api.GET("/traffic-lights/:id", revalidate, h.GetTrafficLightByID)
 
         // Вакансии — публичное чтение
-        api.GET("/vacancies", h.GetVacancies)
-        api.GET("/vacancies/:id", h.GetVacancyByID)
+        api.GET("/vacancies", short, h.GetVacancies)
+        api.GET("/vacancies/:id", revalidate, h.GetVacancyByID)
+
+        // Обращения жителей: приём (лимит по IP сверх общего), статус по номеру, опубликованные ответы
+        api.POST("/feedback", h.SubmitFeedback)
+        api.GET("/feedback", short, h.GetPublicFeedback)
+        api.GET("/feedback/:ticket", cacheControl(cachePrivate), h.GetFeedbackStatus)
+
+        // Заказы платных услуг: оформление (лимит по IP сверх общего) и статус по номеру
+        api.POST("/service-orders", h.CreateServiceOrder)
+        api.GET("/service-orders/:number", cacheControl(cachePrivate), h.GetServiceOrderStatus)
+
+        // Поток событий об изменениях (Server-Sent Events)
+        api.GET("/events", h.Events)
     }
 
-    // Админские маршруты (только админ)
-    admin := r.Group("/api/admin", AuthMiddleware(cfg), RequireAdmin())
+    // Ленты новостей RSS/Atom для агрегаторов и городских порталов
+    feeds := r.Group("/feeds", publicLimit, short)
     {
-        // Зеркальные GET для админских страниц (чтение с авторизацией)
-        admin.GET("/news", h.GetNews)
-        admin.GET("/news/:id", h.GetNewsByID)
-
-        admin.GET("/services", h.GetServices)
-        admin.GET("/services/:id", h.GetServiceByID)
-
-        admin.GET("/team", h.GetTeam)
-        admin.GET("/team/:id", h.GetTeamMemberByID)
-
-        admin.GET("/projects", h.GetProjects)
-
-        admin.GET("/fines", h.GetFines)
-        admin.GET("/evacuations", h.GetEvacuations)
-        admin.GET("/evacuation-routes", h.GetEvacuationRoutes)
-        admin.GET("/traffic-lights", h.GetTrafficLights)
-
-        admin.GET("/vacancies", h.GetVacancies)
-        admin.GET("/vacancies/:id", h.GetVacancyByID)
-
-        // Новости — CRUD
-        admin.POST("/news", h.CreateNews)
-        admin.PUT("/news/:id", h.UpdateNews)
-        admin.DELETE("/news/:id", h.DeleteNews)
-
-        // Услуги — CRUD
-        admin.POST("/services", h.CreateService)
-        admin.PUT("/services/:id", h.UpdateService)
-        admin.DELETE("/services/:id", h.DeleteService)
-
-        // Штрафы — CRUD
-        admin.POST("/fines", h.CreateFine)
-        admin.PUT("/fines/:id", h.UpdateFine)
-        admin.DELETE("/fines/:id", h.DeleteFine)
-
-        // Эвакуация — CRUD
-        admin.POST("/evacuations", h.CreateEvacuation)
-        admin.POST("/evacuation-routes", h.CreateEvacuationRoute)
-
-        // Светофоры — CRUD
-        admin.POST("/traffic-lights", h.CreateTrafficLight)
-        admin.PUT("/traffic-lights/:id", h.UpdateTrafficLight)
-        admin.DELETE("/traffic-lights/:id", h.DeleteTrafficLight)
-
-        // Команда — CRUD
-        admin.POST("/team", h.CreateTeam)        // если реализовано
-        admin.PUT("/team/:id", h.UpdateTeam)     // если реализовано
-        admin.DELETE("/team/:id", h.DeleteTeam)
-
-        // Проекты — CRUD
-        admin.POST("/projects", h.CreateProject)
-        admin.PUT("/projects/:id", h.UpdateProject)
-        admin.DELETE("/projects/:id", h.DeleteProject)
-
-        // Вакансии — CRUD
-        admin.POST("/vacancies", h.CreateVacancy)
-        admin.PUT("/vacancies/:id", h.UpdateVacancy)
-        admin.DELETE("/vacancies/:id", h.DeleteVacancy)
+        feeds.GET("/news.rss", h.NewsRSS)
+        feeds.GET("/news.atom", h.NewsAtom)
+        feeds.GET("/news/:file", h.NewsTagFeed)
     }
 
-    // Редакторские маршруты (редактор/админ)
-    editor := r.Group("/api/editor", AuthMiddleware(cfg), RequireEditor())
+    // perm — проверка права из матрицы ролей для конкретного маршрута.
+    perm := func(permission string) gin.HandlerFunc {
+        return RequirePermission(h.perms, permission)
+    }
+
+    // Админские и редакторские маршруты: одинаковый набор, доступ решают права роли.
+    // Оба префикса оставлены ради совместимости с фронтендом.
+    // Ответы с авторизацией не кладутся в общие кэши.
+    private := cacheControl(cachePrivate)
+    admin := r.Group("/api/admin", private, AuthMiddleware(keys, s))
+    registerContentRoutes(admin, h, perm)
     {
-        // Зеркальные GET для редакторских страниц (чтение с авторизацией)
-        editor.GET("/news", h.GetNews)
-        editor.GET("/news/:id", h.GetNewsByID)
-
-        editor.GET("/services", h.GetServices)
-        editor.GET("/services/:id", h.GetServiceByID)
-
-        editor.GET("/team", h.GetTeam)
-        editor.GET("/team/:id", h.GetTeamMemberByID)
-
-        editor.GET("/projects", h.GetProjects)
-
-        editor.GET("/fines", h.GetFines)
-        editor.GET("/evacuations", h.GetEvacuations)
-        editor.GET("/evacuation-routes", h.GetEvacuationRoutes)
-        editor.GET("/traffic-lights", h.GetTrafficLights)
-
-        editor.GET("/vacancies", h.GetVacancies)
-        editor.GET("/vacancies/:id", h.GetVacancyByID)
-
-        // Новости — CRUD
-        editor.POST("/news", h.CreateNews)
-        editor.PUT("/news/:id", h.UpdateNews)
-        editor.DELETE("/news/:id", h.DeleteNews)
-
-        // Услуги — CRUD
-        editor.POST("/services", h.CreateService)
-        editor.PUT("/services/:id", h.UpdateService)
-        editor.DELETE("/services/:id", h.DeleteService)
-
-        // Штрафы — CRUD
-        editor.POST("/fines", h.CreateFine)
-        editor.PUT("/fines/:id", h.UpdateFine)
-        editor.DELETE("/fines/:id", h.DeleteFine)
-
-        // Эвакуация — CRUD
-        editor.POST("/evacuations", h.CreateEvacuation)
-        editor.POST("/evacuation-routes", h.CreateEvacuationRoute)
-
-        // Светофоры — CRUD
-        editor.POST("/traffic-lights", h.CreateTrafficLight)
-        editor.PUT("/traffic-lights/:id", h.UpdateTrafficLight)
-        editor.DELETE("/traffic-lights/:id", h.DeleteTrafficLight)
-
-        // Команда — CRUD
-        editor.POST("/team", h.CreateTeam)        // если реализовано
-        editor.PUT("/team/:id", h.UpdateTeam)     // если реализовано
-        editor.DELETE("/team/:id", h.DeleteTeam)
-
-        // Проекты — CRUD
-        editor.POST("/projects", h.CreateProject)
-        editor.PUT("/projects/:id", h.UpdateProject)
-        editor.DELETE("/projects/:id", h.DeleteProject)
-
-        // Вакансии — CRUD
-        editor.POST("/vacancies", h.CreateVacancy)
-        editor.PUT("/vacancies/:id", h.UpdateVacancy)
-        editor.DELETE("/vacancies/:id", h.DeleteVacancy)
+        // Пользователи — снять блокировку входа
+        admin.POST("/users/:id/unlock", perm(rbac.UsersManage), h.UnlockUser)
+
+        // Пользователи — приглашение по email
+        admin.POST("/users/invite", perm(rbac.UsersManage), h.InviteUser)
+
+        // Пользователи — активные сессии
+        admin.GET("/users/:id/sessions", perm(rbac.UsersManage), h.GetUserSessions)
+        admin.DELETE("/users/:id/sessions", perm(rbac.UsersManage), h.RevokeUserSessions)
+        admin.DELETE("/users/:id/sessions/:sid", perm(rbac.UsersManage), h.RevokeUserSession)
+
+        // Политика 2FA по ролям
+        admin.GET("/mfa-policy", perm(rbac.SecurityManage), h.GetMFAPolicy)
+        admin.PUT("/mfa-policy", perm(rbac.SecurityManage), h.SetMFAPolicy)
+
+        // Матрица прав
+        admin.GET("/roles", perm(rbac.RolesManage), h.GetRoles)
+        admin.POST("/roles", perm(rbac.RolesManage), h.CreateRole)
+        admin.PUT("/roles/:name/permissions", perm(rbac.RolesManage), h.SetRolePermissions)
+        admin.DELETE("/roles/:name", perm(rbac.RolesManage), h.DeleteRole)
+        admin.GET("/permissions", perm(rbac.RolesManage), h.GetPermissions)
+
+        // Вебхуки партнёров и журнал доставок
+        admin.GET("/webhooks", perm(rbac.WebhooksManage), h.GetWebhooks)
+        admin.POST("/webhooks", perm(rbac.WebhooksManage), h.CreateWebhook)
+        admin.PATCH("/webhooks/:id", perm(rbac.WebhooksManage), h.UpdateWebhook)
+        admin.DELETE("/webhooks/:id", perm(rbac.WebhooksManage), h.DeleteWebhook)
+        admin.POST("/webhooks/:id/secret", perm(rbac.WebhooksManage), h.RotateWebhookSecret)
+        admin.GET("/webhooks/:id/deliveries", perm(rbac.WebhooksManage), h.GetWebhookDeliveries)
+        admin.POST("/webhooks/:id/deliveries/:delivery_id/replay", perm(rbac.WebhooksManage), h.ReplayWebhookDelivery)
+
+        // Заказы платных услуг: список с итогами по категориям, статус, исполнитель
+        admin.GET("/service-orders", perm(rbac.OrdersManage), h.GetServiceOrders)
+        admin.GET("/service-orders/:id", perm(rbac.OrdersManage), h.GetServiceOrderByID)
+        admin.PUT("/service-orders/:id/status", perm(rbac.OrdersManage), h.UpdateServiceOrderStatus)
+        admin.PUT("/service-orders/:id/assignee", perm(rbac.OrdersManage), h.AssignServiceOrder)
     }
+
+    editor := r.Group("/api/editor", private, AuthMiddleware(keys, s))
+    registerContentRoutes(editor, h, perm)
+}
+
+// registerContentRoutes — чтение и CRUD контента и данных для админки.
+func registerContentRoutes(g *gin.RouterGroup, h *Handler, perm func(string) gin.HandlerFunc) {
+    // Зеркальные GET для админских страниц (чтение с авторизацией)
+    read := perm(rbac.DashboardRead)
+
+    g.GET("/news", read, h.GetNews)
+    g.GET("/news/:id", read, h.GetNewsByID)
+
+    g.GET("/services", read, h.GetServices)
+    g.GET("/services/:id", read, h.GetServiceByID)
+
+    g.GET("/team", read, h.GetTeam)
+    g.GET("/team/:id", read, h.GetTeamMemberByID)
+
+    g.GET("/projects", read, h.GetProjects)
+    g.GET("/projects/:id", read, h.GetProjectByID)
+
+    g.GET("/fines", read, h.GetFines)
+    g.GET("/fines/:id", read, h.GetFineByID)
+    g.GET("/evacuations", read, h.GetEvacuations)
+    g.GET("/evacuation-routes", read, h.GetEvacuationRoutes)
+    g.GET("/traffic-lights", read, h.GetTrafficLights)
+    g.GET("/traffic-lights/:id", read, h.GetTrafficLightByID)
+
+    g.GET("/vacancies", read, h.GetVacancies)
+    g.GET("/vacancies/:id", read, h.GetVacancyByID)
+
+    // Новости — CRUD
+    news := perm(rbac.NewsWrite)
+    g.POST("/news", news, h.CreateNews)
+    g.PUT("/news/:id", news, h.UpdateNews)
+    g.PATCH("/news/:id", news, h.UpdateNews)
+    g.DELETE("/news/:id", news, h.DeleteNews)
+
+    // Услуги — CRUD
+    services := perm(rbac.ServicesWrite)
+    g.POST("/services", services, h.CreateService)
+    g.PUT("/services/:id", services, h.UpdateService)
+    g.PATCH("/services/:id", services, h.UpdateService)
+    g.DELETE("/services/:id", services, h.DeleteService)
+
+    // Штрафы — CRUD
+    fines := perm(rbac.FinesWrite)
+    g.POST("/fines", fines, h.CreateFine)
+    g.PUT("/fines/:id", fines, h.UpdateFine)
+    g.PATCH("/fines/:id", fines, h.UpdateFine)
+    g.DELETE("/fines/:id", fines, h.DeleteFine)
+
+    // Эвакуация — CRUD
+    evacuations := perm(rbac.EvacuationsWrite)
+    g.POST("/evacuations", evacuations, h.CreateEvacuation)
+    g.POST("/evacuation-routes", evacuations, h.CreateEvacuationRoute)
+
+    // Светофоры — CRUD
+    lights := perm(rbac.TrafficLightsWrite)
+    g.POST("/traffic-lights", lights, h.CreateTrafficLight)
+    g.PUT("/traffic-lights/:id", lights, h.UpdateTrafficLight)
+    g.PATCH("/traffic-lights/:id", lights, h.UpdateTrafficLight)
+    g.DELETE("/traffic-lights/:id", lights, h.DeleteTrafficLight)
+
+    // Команда — CRUD
+    team := perm(rbac.TeamWrite)
+    g.POST("/team", team, h.CreateTeam)
+    g.PUT("/team/:id", team, h.UpdateTeam)
+    g.PATCH("/team/:id", team, h.UpdateTeam)
+    g.DELETE("/team/:id", team, h.DeleteTeam)
+
+    // Проекты — CRUD
+    projects := perm(rbac.ProjectsWrite)
+    g.POST("/projects", projects, h.CreateProject)
+    g.PUT("/projects/:id", projects, h.UpdateProject)
+    g.PATCH("/projects/:id", projects, h.UpdateProject)
+    g.DELETE("/projects/:id", projects, h.DeleteProject)
+
+    // Вакансии — CRUD
+    vacancies := perm(rbac.VacanciesWrite)
+    g.POST("/vacancies", vacancies, h.CreateVacancy)
+    g.PUT("/vacancies/:id", vacancies, h.UpdateVacancy)
+    g.PATCH("/vacancies/:id", vacancies, h.UpdateVacancy)
+    g.DELETE("/vacancies/:id", vacancies, h.DeleteVacancy)
+
+    // Обращения жителей — модерация и ответы
+    feedback := perm(rbac.FeedbackModerate)
+    g.GET("/feedback", feedback, h.GetFeedbackQueue)
+    g.GET("/feedback/:id", feedback, h.GetFeedbackByID)
+    g.POST("/feedback/:id/approve", feedback, h.ApproveFeedback)
+    g.POST("/feedback/:id/reject", feedback, h.RejectFeedback)
+    g.POST("/feedback/:id/respond", feedback, h.RespondFeedback)
 }
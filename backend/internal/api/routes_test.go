@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/config"
+	"backend/internal/events"
+	"backend/internal/health"
+)
+
+// publicRouter — роутер публичного порта; маршруты, которым нужны store и ключи, не вызываются.
+func publicRouter(t *testing.T, cfg *config.Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterRoutes(r, nil, cfg, health.New(time.Second), nil, nil, events.NewBus(1))
+	return r
+}
+
+func TestMetricsNotOnPublicPort(t *testing.T) {
+	r := publicRouter(t, config.Default())
+	if w := serve(r, http.MethodGet, "/metrics", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics on the API port: status = %d, want 404", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/healthz", "", nil); w.Code != http.StatusOK {
+		t.Errorf("GET /healthz: status = %d, want 200", w.Code)
+	}
+}
+
+// TestRateLimitKeyIgnoresSpoofedXFF — без доверенных прокси X-Forwarded-For не меняет
+// ключ лимита: клиент с одного адреса не получит новый bucket, подставив другой заголовок.
+func TestRateLimitKeyIgnoresSpoofedXFF(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		remote  string
+		second  int // статус второго запроса с другим X-Forwarded-For
+	}{
+		{"no trusted proxies", nil, "203.0.113.7:40000", http.StatusTooManyRequests},
+		{"untrusted peer", []string{"10.0.0.0/8"}, "203.0.113.7:40000", http.StatusTooManyRequests},
+		{"behind trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.2:40000", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.HTTP.RateLimitRPS, cfg.HTTP.RateLimitBurst = 0.001, 1
+			cfg.HTTP.TrustedProxies = tt.proxies
+			r := publicRouter(t, cfg)
+
+			login := func(xff string) int {
+				req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader("not json"))
+				req.RemoteAddr = tt.remote
+				req.Header.Set("X-Forwarded-For", xff)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				return w.Code
+			}
+			// Тело невалидно, поэтому пропущенный лимитом запрос получает 400, не доходя до БД.
+			if got := login("198.51.100.1"); got != http.StatusBadRequest {
+				t.Fatalf("first request: status = %d, want 400", got)
+			}
+			if got := login("198.51.100.2"); got != tt.second {
+				t.Errorf("second request with another X-Forwarded-For: status = %d, want %d", got, tt.second)
+			}
+		})
+	}
+}
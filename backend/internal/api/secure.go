@@ -0,0 +1,35 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/config"
+)
+
+// SecureHeaders добавляет заголовки безопасности. API отдаёт только JSON,
+// поэтому CSP запрещает всё, включая встраивание во фреймы.
+// Strict-Transport-Security отправляется только по HTTPS-соединению с самим сервером:
+// за балансировщиком, терминирующим TLS, заголовок ставит балансировщик.
+func SecureHeaders(cfg config.HTTPConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		if hsts != "" && c.Request.TLS != nil {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/rbac"
+	"backend/internal/validation"
+	"backend/pkg"
+)
+
+// ordersPerHour — сколько заказов в час принимается с одного IP (сверх общего лимита API).
+const ordersPerHour = 10
+
+// Список заказов в админке: размер страницы.
+const (
+	defaultOrdersLimit = 50
+	maxOrdersLimit     = 500
+)
+
+// CreateServiceOrder оформляет заказ услуги из каталога. Цена фиксируется на момент заказа.
+func (h *Handler) CreateServiceOrder(c *gin.Context) {
+	var req models.CreateServiceOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	o := &models.ServiceOrder{
+		ServiceID:    &req.ServiceID,
+		CustomerName: strings.TrimSpace(req.CustomerName),
+		Phone:        strings.TrimSpace(req.Phone),
+		Email:        strings.TrimSpace(req.Email),
+		Comment:      strings.TrimSpace(req.Comment),
+	}
+	if !validate(c, validation.ServiceOrder(o)) {
+		return
+	}
+	if ok, wait := h.orderLimit.Allow(c.ClientIP()); !ok {
+		tooManyRequests(c, wait, "Too many orders")
+		return
+	}
+
+	number, err := newTicket("SO")
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to create order"))
+		return
+	}
+	o.Number = number
+	if err := h.store.CreateServiceOrder(ctx, o); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to create order"))
+		return
+	}
+	logging.FromContext(ctx).Info("Service order created", "order_id", o.ID, "number", o.Number, "service_id", req.ServiceID)
+	c.JSON(http.StatusCreated, gin.H{"order": o.Ticket()})
+}
+
+// GetServiceOrderStatus — статус заказа по номеру (для заказчика).
+func (h *Handler) GetServiceOrderStatus(c *gin.Context) {
+	number := strings.ToUpper(strings.TrimSpace(c.Param("number")))
+	o, err := h.store.GetServiceOrderByNumber(c.Request.Context(), number)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get order"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order": o.Ticket()})
+}
+
+// orderFilter читает фильтры списка заказов: ?status=, ?category=, ?service_id=,
+// ?assigned_to= (id или none), ?from= и ?to= (YYYY-MM-DD, включительно).
+func orderFilter(c *gin.Context) (*models.ServiceOrderFilter, error) {
+	f := &models.ServiceOrderFilter{
+		Status:   c.Query("status"),
+		Category: strings.TrimSpace(c.Query("category")),
+	}
+	if f.Status != "" && !pkg.Contains(models.OrderStatuses, f.Status) {
+		return nil, apperr.BadRequest("status must be new, in_progress, done or cancelled")
+	}
+	if v := c.Query("service_id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, apperr.BadRequest("Invalid service_id")
+		}
+		f.ServiceID = n
+	}
+	if v := c.Query("assigned_to"); v == "none" {
+		f.Unassigned = true
+	} else if v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, apperr.BadRequest("assigned_to must be a user ID or none")
+		}
+		f.AssignedTo = n
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			return nil, apperr.BadRequest("from must be a date in YYYY-MM-DD format")
+		}
+		f.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			return nil, apperr.BadRequest("to must be a date in YYYY-MM-DD format")
+		}
+		f.To = t.AddDate(0, 0, 1)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return nil, apperr.BadRequest("from must not be after to")
+	}
+	return f, nil
+}
+
+// GetServiceOrders — заказы по фильтрам и итоги по категориям услуг для тех же фильтров.
+func (h *Handler) GetServiceOrders(c *gin.Context) {
+	f, err := orderFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	limit := defaultOrdersLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxOrdersLimit {
+			c.Error(apperr.BadRequest("limit must be between 1 and " + strconv.Itoa(maxOrdersLimit)))
+			return
+		}
+		limit = n
+	}
+
+	ctx := c.Request.Context()
+	orders, err := h.store.GetServiceOrders(ctx, f, limit)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get orders"))
+		return
+	}
+	totals, err := h.store.GetServiceOrderTotals(ctx, f)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get order totals"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"orders": orders, "totals": totals})
+}
+
+func (h *Handler) GetServiceOrderByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	o, err := h.store.GetServiceOrderByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get order"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order": o})
+}
+
+// UpdateServiceOrderStatus переводит заказ по жизненному циклу:
+// new → in_progress → done, отмена — из new или in_progress.
+func (h *Handler) UpdateServiceOrderStatus(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	var req models.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+	if !pkg.Contains(models.OrderStatuses, req.Status) {
+		c.Error(apperr.BadRequest("status must be new, in_progress, done or cancelled"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	o, err := h.store.SetServiceOrderStatus(ctx, id, req.Status)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to update order"))
+		return
+	}
+	logging.FromContext(ctx).Info("Service order status changed", "order_id", id, "status", o.Status, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"order": o})
+}
+
+// AssignServiceOrder назначает сотрудника, который ведёт заказ ({"user_id": null} — снять).
+// Назначить можно только пользователя, чья роль имеет право orders:manage.
+func (h *Handler) AssignServiceOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	var req models.AssignOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.UserID != nil {
+		user, err := h.store.GetUserByID(ctx, *req.UserID)
+		if apperr.IsNotFound(err) {
+			c.Error(apperr.BadRequest("Assignee not found"))
+			return
+		}
+		if err != nil {
+			c.Error(apperr.Wrap(err, "Failed to assign order"))
+			return
+		}
+		ok, err := h.perms.Has(ctx, user.Role, rbac.OrdersManage)
+		if err != nil {
+			c.Error(apperr.Internal(err, "Failed to assign order"))
+			return
+		}
+		if !ok {
+			c.Error(apperr.BadRequest("Assignee cannot manage orders"))
+			return
+		}
+	}
+
+	o, err := h.store.AssignServiceOrder(ctx, id, req.UserID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to assign order"))
+		return
+	}
+	logging.FromContext(ctx).Info("Service order assigned", "order_id", id, "assigned_to", req.UserID, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"order": o})
+}
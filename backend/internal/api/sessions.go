@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/auth"
+	"backend/internal/logging"
+	"backend/internal/models"
+)
+
+// sessionTouchInterval — не чаще этого обновлять last_seen_at сессии.
+const sessionTouchInterval = time.Minute
+
+// maxUserAgentLen — длина колонки sessions.user_agent.
+const maxUserAgentLen = 255
+
+// createSession сохраняет сессию для нового токена доступа и возвращает её id.
+func (h *Handler) createSession(c *gin.Context, user *models.User) (string, error) {
+	id, err := auth.NewSessionID()
+	if err != nil {
+		return "", err
+	}
+	ua := []rune(c.Request.UserAgent())
+	if len(ua) > maxUserAgentLen {
+		ua = ua[:maxUserAgentLen]
+	}
+	now := time.Now()
+	sess := &models.Session{
+		ID:        id,
+		UserID:    user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: string(ua),
+		CreatedAt: now,
+		ExpiresAt: now.Add(auth.TokenTTL),
+	}
+	if err := h.store.CreateSession(c.Request.Context(), sess); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetMySessions — действующие сессии текущего пользователя; текущая помечена current.
+func (h *Handler) GetMySessions(c *gin.Context) {
+	h.respondSessions(c, c.GetInt("user_id"))
+}
+
+// RevokeMySession — пользователь завершает свою сессию (в том числе текущую — это выход).
+func (h *Handler) RevokeMySession(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	if err := h.store.RevokeSession(ctx, c.GetInt("user_id"), id); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to revoke session"))
+		return
+	}
+	logging.FromContext(ctx).Info("Session revoked", "user_id", c.GetInt("user_id"), "session_id", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// GetUserSessions — админ смотрит действующие сессии пользователя.
+func (h *Handler) GetUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	h.respondSessions(c, userID)
+}
+
+// RevokeUserSession — админ завершает одну сессию пользователя.
+func (h *Handler) RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	ctx := c.Request.Context()
+	sid := c.Param("sid")
+	if err := h.store.RevokeSession(ctx, userID, sid); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to revoke session"))
+		return
+	}
+	logging.FromContext(ctx).Info("Session revoked", "user_id", userID, "session_id", sid, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeUserSessions — админ завершает все сессии пользователя («выкинуть из системы»).
+func (h *Handler) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return
+	}
+	ctx := c.Request.Context()
+	n, err := h.store.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to revoke sessions"))
+		return
+	}
+	logging.FromContext(ctx).Info("User sessions revoked", "user_id", userID, "count", n, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked", "revoked": n})
+}
+
+func (h *Handler) respondSessions(c *gin.Context, userID int) {
+	sessions, err := h.store.GetUserSessions(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get sessions"))
+		return
+	}
+	current := c.GetString("session_id")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	c.JSON(http.StatusOK, sessions)
+}
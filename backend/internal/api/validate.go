@@ -0,0 +1,25 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/validation"
+)
+
+// validate передаёт в ErrorHandler ошибку вида validation (422) со списком нарушений по полям,
+// если сущность не прошла бизнес-правила.
+func validate(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		c.Error(apperr.New(apperr.KindValidation, "Validation failed").With("fields", fields))
+		return false
+	}
+	c.Error(apperr.Internal(err, "Validation error"))
+	return false
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/apperr"
+	"backend/internal/logging"
+	"backend/internal/models"
+	"backend/internal/validation"
+)
+
+// Размер страницы журнала доставок.
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// newWebhookSecret — случайный ключ подписи; партнёр получает его один раз.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// GetWebhooks — подписки партнёров (без секретов).
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	hooks, err := h.store.GetWebhooks(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get webhooks"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+// CreateWebhook создаёт подписку; секрет для проверки подписи есть только в этом ответе.
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	w := &models.Webhook{URL: req.URL, Events: req.Events, Description: req.Description, Active: true}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+	if !validate(c, validation.Webhook(w)) {
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate webhook secret"))
+		return
+	}
+	w.Secret = secret
+	if err := h.store.CreateWebhook(c.Request.Context(), w); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to create webhook"))
+		return
+	}
+
+	logging.FromContext(c.Request.Context()).Info("Webhook created", "webhook_id", w.ID, "url", w.URL, "events", w.Events, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusCreated, gin.H{"webhook": w})
+}
+
+// UpdateWebhook меняет адрес, фильтры, описание или активность подписки.
+// Выключенная подписка новых событий не получает, а её очередь ждёт включения.
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.BadRequest("Invalid request body"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	w, err := h.store.GetWebhookByID(ctx, id)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get webhook"))
+		return
+	}
+	req.ApplyTo(w)
+	if !validate(c, validation.Webhook(w)) {
+		return
+	}
+	if err := h.store.UpdateWebhook(ctx, w); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to update webhook"))
+		return
+	}
+
+	logging.FromContext(ctx).Info("Webhook updated", "webhook_id", id, "url", w.URL, "events", w.Events, "active", w.Active, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"webhook": w})
+}
+
+// DeleteWebhook удаляет подписку и её журнал.
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.store.DeleteWebhook(ctx, id); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to delete webhook"))
+		return
+	}
+	logging.FromContext(ctx).Info("Webhook deleted", "webhook_id", id, "by", c.GetInt("user_id"))
+	c.Status(http.StatusNoContent)
+}
+
+// RotateWebhookSecret выдаёт новый ключ подписи (старый перестаёт действовать сразу).
+func (h *Handler) RotateWebhookSecret(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.Error(apperr.Internal(err, "Failed to generate webhook secret"))
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.store.SetWebhookSecret(ctx, id, secret); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to rotate webhook secret"))
+		return
+	}
+	logging.FromContext(ctx).Info("Webhook secret rotated", "webhook_id", id, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusOK, gin.H{"secret": secret})
+}
+
+// GetWebhookDeliveries — журнал доставок подписки: ?status=pending|delivered|failed, ?limit=.
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed:
+	default:
+		c.Error(apperr.BadRequest("status must be pending, delivered or failed"))
+		return
+	}
+	limit := defaultDeliveriesLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			c.Error(apperr.BadRequest("limit must be between 1 and " + strconv.Itoa(maxDeliveriesLimit)))
+			return
+		}
+		limit = n
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.store.GetWebhookByID(ctx, id); err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get webhook"))
+		return
+	}
+	deliveries, err := h.store.GetWebhookDeliveries(ctx, id, status, limit)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to get webhook deliveries"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// ReplayWebhookDelivery ставит событие из журнала в очередь ещё раз (например, после сбоя у партнёра).
+func (h *Handler) ReplayWebhookDelivery(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid delivery ID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	d, err := h.store.ReplayWebhookDelivery(ctx, id, deliveryID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to replay webhook delivery"))
+		return
+	}
+	logging.FromContext(ctx).Info("Webhook delivery replayed", "webhook_id", id, "delivery_id", deliveryID, "new_delivery_id", d.ID, "by", c.GetInt("user_id"))
+	c.JSON(http.StatusAccepted, gin.H{"delivery": d})
+}
+
+func webhookID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid ID"))
+		return 0, false
+	}
+	return id, true
+}
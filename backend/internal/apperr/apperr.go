@@ -0,0 +1,127 @@
+// Package apperr — типизированные ошибки приложения.
+// Store и хендлеры возвращают *Error с видом (Kind), а ErrorHandler в api
+// превращает его в ответ application/problem+json (RFC 7807) с нужным HTTP-статусом.
+// Любая другая ошибка считается внутренней (500), её текст клиенту не показывается.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Kind — машиночитаемый код ошибки, уходит клиенту в поле "code".
+type Kind string
+
+const (
+	KindNotFound             Kind = "not_found"
+	KindConflict             Kind = "conflict"
+	KindValidation           Kind = "validation"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindInternal             Kind = "internal"
+	KindBadRequest           Kind = "bad_request"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindPreconditionRequired Kind = "precondition_required"
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+	KindTimeout              Kind = "timeout"
+	KindTooManyRequests      Kind = "too_many_requests"
+)
+
+var statuses = map[Kind]int{
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindValidation:           http.StatusUnprocessableEntity,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindInternal:             http.StatusInternalServerError,
+	KindBadRequest:           http.StatusBadRequest,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+	KindPreconditionRequired: http.StatusPreconditionRequired,
+	KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	KindTimeout:              http.StatusGatewayTimeout,
+	KindTooManyRequests:      http.StatusTooManyRequests,
+}
+
+// Error — ошибка с видом, сообщением для клиента и (опционально) исходной причиной.
+type Error struct {
+	Kind    Kind
+	Message string                 // безопасно показывать клиенту
+	Err     error                  // причина, только для логов
+	Extra   map[string]interface{} // дополнительные поля problem+json
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return string(e.Kind) + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return string(e.Kind) + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is сравнивает ошибки по виду и сообщению, чтобы errors.Is работал и с копиями из With.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Message == e.Message
+}
+
+// Status — HTTP-статус для вида ошибки.
+func (e *Error) Status() int {
+	if s, ok := statuses[e.Kind]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// With возвращает копию ошибки с дополнительным полем ответа (исходная не меняется —
+// ею могут быть общие переменные вроде store.ErrVersionConflict).
+func (e *Error) With(key string, value interface{}) *Error {
+	cp := *e
+	cp.Extra = make(map[string]interface{}, len(e.Extra)+1)
+	for k, v := range e.Extra {
+		cp.Extra[k] = v
+	}
+	cp.Extra[key] = value
+	return &cp
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func NotFound(message string) *Error     { return New(KindNotFound, message) }
+func Conflict(message string) *Error     { return New(KindConflict, message) }
+func Unauthorized(message string) *Error { return New(KindUnauthorized, message) }
+func Forbidden(message string) *Error    { return New(KindForbidden, message) }
+func BadRequest(message string) *Error   { return New(KindBadRequest, message) }
+
+// Internal оборачивает неожиданную ошибку; клиент увидит только message.
+func Internal(err error, message string) *Error {
+	return &Error{Kind: KindInternal, Message: message, Err: err}
+}
+
+// Wrap оставляет типизированную ошибку как есть, а любую другую делает внутренней с message.
+// Истёкший таймаут запроса к БД становится KindTimeout (504).
+func Wrap(err error, message string) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: KindTimeout, Message: "Request timed out", Err: err}
+	}
+	return Internal(err, message)
+}
+
+// KindOf возвращает вид ошибки; для нетипизированных — KindInternal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// IsNotFound — сокращение для частой проверки.
+func IsNotFound(err error) bool { return KindOf(err) == KindNotFound }
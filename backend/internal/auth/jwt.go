@@ -1,6 +1,9 @@
 package auth
 
 import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
     "errors"
     "time"
 
@@ -8,30 +11,77 @@ import (
     "backend/internal/models"
 )
 
+// Назначения ограниченных токенов второго шага логина.
+const (
+    PurposeMFAVerify = "mfa_verify" // только для /api/auth/mfa/verify
+    PurposeMFAEnroll = "mfa_enroll" // только для подключения обязательной 2FA
+)
+
+// TokenTTL — время жизни токена доступа.
+const TokenTTL = 24 * time.Hour
+
+// MFATokenTTL — время жизни токена второго шага.
+const MFATokenTTL = 5 * time.Minute
+
 type JWTClaims struct {
-    UserID int    `json:"user_id"`
-    Role   string `json:"role"`
+    UserID    int    `json:"user_id"`
+    Role      string `json:"role"`
+    // Purpose пуст у обычного токена доступа; у токенов второго шага — Purpose*.
+    Purpose   string `json:"purpose,omitempty"`
+    // Endpoint — через какой логин (admin, editor, user) начат вход.
+    Endpoint  string `json:"endpoint,omitempty"`
+    // SessionID — сессия в БД, по которой AuthMiddleware проверяет отзыв (только у токена доступа).
+    SessionID string `json:"sid,omitempty"`
     jwt.RegisteredClaims
 }
 
-func GenerateToken(user models.User, secretKey string) (string, error) {
+// NewSessionID — случайный идентификатор сессии для claim sid.
+func NewSessionID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+func (k *KeySet) GenerateToken(user models.User, sessionID string) (string, error) {
+    claims := JWTClaims{
+        UserID:    user.ID,
+        Role:      user.Role,
+        SessionID: sessionID,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenTTL)),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+            Subject:   user.Email,
+        },
+    }
+
+    return k.sign(claims)
+}
+
+// GenerateMFAToken выдаёт короткоживущий токен второго шага логина.
+func (k *KeySet) GenerateMFAToken(user models.User, purpose, endpoint string) (string, error) {
     claims := JWTClaims{
-        UserID: user.ID,
-        Role:   user.Role,
+        UserID:   user.ID,
+        Role:     user.Role,
+        Purpose:  purpose,
+        Endpoint: endpoint,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFATokenTTL)),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
             Subject:   user.Email,
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString([]byte(secretKey))
+    return k.sign(claims)
 }
 
-func ValidateToken(tokenString, secretKey string) (*JWTClaims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-        return []byte(secretKey), nil
+// ValidateToken проверяет подпись ключом из заголовка kid. Алгоритм токена должен
+// совпадать с алгоритмом этого ключа: HS256 и "none" не принимаются.
+func (k *KeySet) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+    parser := jwt.NewParser(jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}))
+    token, err := parser.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+        return k.verificationKey(ctx, token)
     })
 
     if err != nil {
@@ -0,0 +1,353 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"backend/internal/logging"
+	"backend/internal/models"
+)
+
+// Поддерживаемые алгоритмы подписи.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWKSPrepublish — за сколько до начала подписи новый ключ появляется в JWKS,
+// чтобы сервисы с закэшированным JWKS успели его получить.
+const JWKSPrepublish = 10 * time.Minute
+
+// unknownKIDReload — не чаще этого перечитывать ключи из-за незнакомого kid
+// (его могла только что создать другая реплика).
+const unknownKIDReload = 10 * time.Second
+
+var errNoActiveKey = errors.New("no active signing key")
+
+// KeyStore — хранилище ключей подписи (store.Store).
+type KeyStore interface {
+	GetSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKey, error)
+	RotateSigningKey(ctx context.Context, key *models.SigningKey, dueBefore, purgeBefore time.Time) (bool, error)
+}
+
+type signingKey struct {
+	kid       string
+	alg       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	notBefore time.Time
+	retiredAt *time.Time
+}
+
+// KeySet — ключи подписи JWT с ротацией по расписанию.
+// Подписывает самый новый ключ, чей срок уже наступил; проверка принимает
+// любой ключ набора, включая заменённые не раньше чем overlap назад.
+type KeySet struct {
+	store    KeyStore
+	alg      string
+	rotation time.Duration
+	overlap  time.Duration
+
+	mu         sync.RWMutex
+	keys       []*signingKey // по убыванию notBefore
+	lastReload time.Time
+}
+
+// NewKeySet загружает ключи и при необходимости создаёт первый.
+// overlap должен быть не меньше TokenTTL, иначе токены переживут свой ключ.
+func NewKeySet(ctx context.Context, store KeyStore, alg string, rotation, overlap time.Duration) (*KeySet, error) {
+	if _, err := methodFor(alg); err != nil {
+		return nil, err
+	}
+	k := &KeySet{store: store, alg: alg, rotation: rotation, overlap: overlap}
+	if err := k.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Run каждые interval перечитывает ключи и ротирует их по расписанию, пока не отменён ctx.
+// Ошибки логируются; до следующей попытки работает прежний набор.
+func (k *KeySet) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := k.refresh(ctx); err != nil {
+				logging.FromContext(ctx).Error("JWT key refresh failed", "err", err)
+			}
+		}
+	}
+}
+
+// refresh загружает ключи и создаёт новый, если действующий старше rotation
+// или подписывает другим алгоритмом.
+func (k *KeySet) refresh(ctx context.Context) error {
+	now := time.Now()
+	keys, err := k.load(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if k.rotationDue(keys, now) {
+		notBefore := now.Add(JWKSPrepublish)
+		if len(keys) == 0 {
+			notBefore = now
+		}
+		key, err := newSigningKey(k.alg, notBefore)
+		if err != nil {
+			return err
+		}
+		added, err := k.store.RotateSigningKey(ctx, key, now.Add(-k.rotation), now.Add(-k.overlap))
+		if err != nil {
+			return err
+		}
+		if added {
+			logging.FromContext(ctx).Info("JWT signing key rotated", "kid", key.KID, "alg", key.Algorithm, "active_from", key.NotBefore)
+		}
+		if keys, err = k.load(ctx, now); err != nil {
+			return err
+		}
+	}
+
+	k.mu.Lock()
+	k.keys, k.lastReload = keys, now
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeySet) rotationDue(keys []*signingKey, now time.Time) bool {
+	for _, key := range keys {
+		if key.alg == k.alg {
+			return !key.notBefore.After(now.Add(-k.rotation))
+		}
+	}
+	return true
+}
+
+func (k *KeySet) load(ctx context.Context, now time.Time) ([]*signingKey, error) {
+	stored, err := k.store.GetSigningKeys(ctx, now.Add(-k.overlap))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*signingKey, 0, len(stored))
+	for _, s := range stored {
+		key, err := parseSigningKey(s)
+		if err != nil {
+			logging.FromContext(ctx).Error("Skipping invalid JWT signing key", "kid", s.KID, "err", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// active — ключ, которым сейчас подписываются токены.
+func (k *KeySet) active() (*signingKey, error) {
+	now := time.Now()
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if !key.notBefore.After(now) {
+			return key, nil
+		}
+	}
+	return nil, errNoActiveKey
+}
+
+func (k *KeySet) sign(claims JWTClaims) (string, error) {
+	key, err := k.active()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// verificationKey — открытый ключ по kid токена; незнакомый kid — повод перечитать набор.
+func (k *KeySet) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key := k.lookup(kid)
+	if key == nil && k.reloadAllowed() {
+		if err := k.reload(ctx); err != nil {
+			return nil, err
+		}
+		key = k.lookup(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if key.retiredAt != nil && time.Since(*key.retiredAt) > k.overlap {
+		return nil, fmt.Errorf("key %q is expired", kid)
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.private.Public(), nil
+}
+
+func (k *KeySet) lookup(kid string) *signingKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.kid == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+func (k *KeySet) reloadAllowed() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return time.Since(k.lastReload) > unknownKIDReload
+}
+
+func (k *KeySet) reload(ctx context.Context) error {
+	now := time.Now()
+	keys, err := k.load(ctx, now)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys, k.lastReload = keys, now
+	k.mu.Unlock()
+	return nil
+}
+
+// JWK — открытый ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS — набор открытых ключей для /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS — открытые ключи набора: действующий, заранее опубликованный следующий
+// и заменённые, чьи токены ещё могут быть в ходу.
+func (k *KeySet) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk := JWK{Kid: key.kid, Alg: key.alg, Use: "sig"}
+		switch pub := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func methodFor(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case AlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported JWT algorithm %q", alg)
+}
+
+func newSigningKey(alg string, notBefore time.Time) (*models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	kid := make([]byte, 12)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+	return &models.SigningKey{
+		KID:        base64.RawURLEncoding.EncodeToString(kid),
+		Algorithm:  alg,
+		PrivateKey: der,
+		NotBefore:  notBefore,
+	}, nil
+}
+
+func parseSigningKey(s models.SigningKey) (*signingKey, error) {
+	method, err := methodFor(s.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(s.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("key is not a signer")
+	}
+	switch private.(type) {
+	case *rsa.PrivateKey:
+		if s.Algorithm != AlgRS256 {
+			return nil, fmt.Errorf("RSA key for %s", s.Algorithm)
+		}
+	case ed25519.PrivateKey:
+		if s.Algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("Ed25519 key for %s", s.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+	return &signingKey{
+		kid:       s.KID,
+		alg:       s.Algorithm,
+		method:    method,
+		private:   private,
+		notBefore: s.NotBefore,
+		retiredAt: s.RetiredAt,
+	}, nil
+}
@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"backend/internal/models"
+)
+
+// memKeyStore — KeyStore в памяти с той же семантикой, что store.Store.
+type memKeyStore struct {
+	mu   sync.Mutex
+	keys []models.SigningKey
+}
+
+func (m *memKeyStore) GetSigningKeys(_ context.Context, retiredAfter time.Time) ([]models.SigningKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []models.SigningKey
+	for _, k := range m.keys {
+		if k.RetiredAt == nil || k.RetiredAt.After(retiredAfter) {
+			res = append(res, k)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].NotBefore.After(res[j].NotBefore) })
+	return res, nil
+}
+
+func (m *memKeyStore) RotateSigningKey(_ context.Context, key *models.SigningKey, dueBefore, purgeBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range m.keys {
+		if k.Algorithm == key.Algorithm && k.NotBefore.After(dueBefore) {
+			return false, nil
+		}
+	}
+	kept := m.keys[:0]
+	for _, k := range m.keys {
+		if k.RetiredAt == nil {
+			retired := key.NotBefore
+			k.RetiredAt = &retired
+		}
+		if !k.RetiredAt.Before(purgeBefore) {
+			kept = append(kept, k)
+		}
+	}
+	key.CreatedAt = time.Now()
+	m.keys = append(kept, *key)
+	return true, nil
+}
+
+// add кладёт в хранилище готовый ключ alg с заданными сроками.
+func (m *memKeyStore) add(t *testing.T, alg string, notBefore time.Time, retiredAt *time.Time) models.SigningKey {
+	t.Helper()
+	key, err := newSigningKey(alg, notBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.RetiredAt = retiredAt
+	m.mu.Lock()
+	m.keys = append(m.keys, *key)
+	m.mu.Unlock()
+	return *key
+}
+
+// signWith подписывает токен конкретным ключом, минуя выбор действующего.
+func signWith(t *testing.T, stored models.SigningKey, claims JWTClaims) string {
+	t.Helper()
+	key, err := parseSigningKey(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	s, err := token.SignedString(key.private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testClaims() JWTClaims {
+	return JWTClaims{
+		UserID: 7,
+		Role:   "editor",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			ctx := context.Background()
+			store := &memKeyStore{}
+			k, err := NewKeySet(ctx, store, alg, 24*time.Hour, 48*time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(store.keys) != 1 {
+				t.Fatalf("first start created %d keys, want 1", len(store.keys))
+			}
+
+			token, err := k.GenerateToken(models.User{ID: 7, Role: "editor", Email: "editor@smolensk.example"}, "sid")
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parsed.Header["kid"]; got != store.keys[0].KID {
+				t.Errorf("kid = %v, want %s", got, store.keys[0].KID)
+			}
+			if got := parsed.Method.Alg(); got != alg {
+				t.Errorf("alg = %s, want %s", got, alg)
+			}
+
+			claims, err := k.ValidateToken(ctx, token)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if claims.UserID != 7 || claims.SessionID != "sid" {
+				t.Errorf("claims = %+v", claims)
+			}
+
+			// Подпись чужим ключом с подменённым kid не проходит.
+			foreign := mustKey(t, alg)
+			foreign.KID = store.keys[0].KID
+			forged := signWith(t, *foreign, testClaims())
+			if _, err := k.ValidateToken(ctx, forged); err == nil {
+				t.Error("token signed by a foreign key accepted")
+			}
+		})
+	}
+}
+
+func mustKey(t *testing.T, alg string) *models.SigningKey {
+	t.Helper()
+	key, err := newSigningKey(alg, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerifyRejects(t *testing.T) {
+	ctx := context.Background()
+	store := &memKeyStore{}
+	k, err := NewKeySet(ctx, store, AlgRS256, 24*time.Hour, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := store.keys[0].KID
+
+	noKID, err := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims()).SignedString(mustPrivate(t, store.keys[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	hmac.Header["kid"] = kid
+	hs256, err := hmac.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := signWith(t, *mustKey(t, AlgRS256), testClaims())
+	// Ключ Ed25519 не может подписывать от имени RSA-ключа с тем же kid.
+	ed := mustKey(t, AlgEdDSA)
+	ed.KID = kid
+	wrongAlg := signWith(t, *ed, testClaims())
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"no kid", noKID},
+		{"HS256", hs256},
+		{"unknown kid", unknown},
+		{"algorithm of another key type", wrongAlg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := k.ValidateToken(ctx, tt.token); err == nil {
+				t.Error("token accepted")
+			}
+		})
+	}
+}
+
+func mustPrivate(t *testing.T, stored models.SigningKey) interface{} {
+	t.Helper()
+	key, err := parseSigningKey(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.private
+}
+
+func TestRotatedKeyOverlap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	const overlap = 2 * time.Hour
+
+	store := &memKeyStore{}
+	retired := now.Add(-time.Hour)
+	old := store.add(t, AlgRS256, now.Add(-25*time.Hour), &retired)
+	current := store.add(t, AlgRS256, now.Add(-time.Hour), nil)
+	next := store.add(t, AlgRS256, now.Add(5*time.Minute), nil)
+
+	k, err := NewKeySet(ctx, store, AlgRS256, 24*time.Hour, overlap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 3 {
+		t.Fatalf("unexpected rotation: %d keys in store", len(store.keys))
+	}
+
+	// Подписывает действующий ключ, а не заранее опубликованный следующий.
+	token, err := k.GenerateToken(models.User{ID: 1, Role: "admin"}, "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if got := parsed.Header["kid"]; got != current.KID {
+		t.Errorf("signed with %v, want the current key %s (next is %s)", got, current.KID, next.KID)
+	}
+
+	// Токен заменённого ключа принимается, пока не прошёл overlap.
+	oldToken := signWith(t, old, testClaims())
+	if _, err := k.ValidateToken(ctx, oldToken); err != nil {
+		t.Errorf("token of a key retired %v ago rejected: %v", now.Sub(retired).Round(time.Minute), err)
+	}
+
+	// Прошёл overlap с момента замены — токен отклоняется, даже если ключ ещё в памяти.
+	expired := now.Add(-overlap - time.Minute)
+	k.lookup(old.KID).retiredAt = &expired
+	if _, err := k.ValidateToken(ctx, oldToken); err == nil {
+		t.Error("token of an expired key accepted")
+	}
+
+	// Реплика, загрузившая ключи позже, вовсе не получает такой ключ из хранилища.
+	store.keys[0].RetiredAt = &expired
+	fresh, err := NewKeySet(ctx, store, AlgRS256, 24*time.Hour, overlap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.lookup(old.KID) != nil {
+		t.Error("expired key loaded from the store")
+	}
+	if _, err := fresh.ValidateToken(ctx, oldToken); err == nil {
+		t.Error("token of an expired key accepted after reload")
+	}
+}
+
+func TestRotationRetiresPreviousKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := &memKeyStore{}
+	old := store.add(t, AlgRS256, now.Add(-25*time.Hour), nil)
+
+	k, err := NewKeySet(ctx, store, AlgRS256, 24*time.Hour, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 2 {
+		t.Fatalf("%d keys in store, want the old and a new one", len(store.keys))
+	}
+	added := store.keys[1]
+	if d := added.NotBefore.Sub(now); d < JWKSPrepublish-time.Minute || d > JWKSPrepublish+time.Minute {
+		t.Errorf("new key starts in %v, want ~%v", d, JWKSPrepublish)
+	}
+	if r := store.keys[0].RetiredAt; r == nil || !r.Equal(added.NotBefore) {
+		t.Errorf("old key retired_at = %v, want %v", r, added.NotBefore)
+	}
+
+	// Пока новый ключ не вступил в силу, подписывает старый.
+	token, err := k.GenerateToken(models.User{ID: 1, Role: "admin"}, "sid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if got := parsed.Header["kid"]; got != old.KID {
+		t.Errorf("signed with %v before the new key is due, want %s", got, old.KID)
+	}
+}
+
+func TestJWKSPublishesOnlyPublicKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := &memKeyStore{}
+	retired := now.Add(-time.Hour)
+	rsaKey := store.add(t, AlgRS256, now.Add(-25*time.Hour), &retired)
+	edKey := store.add(t, AlgEdDSA, now.Add(-time.Hour), nil)
+
+	k, err := NewKeySet(ctx, store, AlgEdDSA, 24*time.Hour, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := k.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(set.Keys))
+	}
+
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, jwk := range doc.Keys {
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+			if _, ok := jwk[private]; ok {
+				t.Errorf("key %v publishes private member %q", jwk["kid"], private)
+			}
+		}
+		if jwk["use"] != "sig" {
+			t.Errorf("key %v use = %v, want sig", jwk["kid"], jwk["use"])
+		}
+	}
+
+	byKID := map[string]JWK{}
+	for _, jwk := range set.Keys {
+		byKID[jwk.Kid] = jwk
+	}
+
+	rsaPub := mustPrivate(t, rsaKey).(*rsa.PrivateKey).PublicKey
+	got := byKID[rsaKey.KID]
+	n, _ := base64.RawURLEncoding.DecodeString(got.N)
+	e, _ := base64.RawURLEncoding.DecodeString(got.E)
+	if got.Kty != "RSA" || got.Alg != AlgRS256 ||
+		new(big.Int).SetBytes(n).Cmp(rsaPub.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaPub.E) {
+		t.Errorf("RSA JWK %+v does not match the public key", got)
+	}
+
+	edPub := mustPrivate(t, edKey).(ed25519.PrivateKey).Public().(ed25519.PublicKey)
+	got = byKID[edKey.KID]
+	x, _ := base64.RawURLEncoding.DecodeString(got.X)
+	if got.Kty != "OKP" || got.Crv != "Ed25519" || got.Alg != AlgEdDSA || !edPub.Equal(ed25519.PublicKey(x)) {
+		t.Errorf("Ed25519 JWK %+v does not match the public key", got)
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash сравнивается с паролем, когда пользователя нет, чтобы по времени ответа
+// нельзя было понять, существует ли email.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// HashPassword — bcrypt-хэш пароля для колонки users.password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword сверяет пароль с сохранённым значением. Кроме bcrypt поддерживаются
+// старые пароли в открытом виде (из seed-данных): после успешного входа их нужно
+// перехэшировать (NeedsRehash). Пустой сохранённый пароль (SSO, неприглашённые) не подходит никогда.
+func CheckPassword(stored, password string) bool {
+	if stored == "" {
+		return false
+	}
+	if isBcrypt(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+// CheckDummyPassword тратит на проверку столько же времени, сколько CheckPassword для bcrypt.
+func CheckDummyPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// NeedsRehash — сохранённый пароль ещё не bcrypt.
+func NeedsRehash(stored string) bool {
+	return stored != "" && !isBcrypt(stored)
+}
+
+func isBcrypt(s string) bool {
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
+
+// NewOpaqueToken — случайный токен для ссылки в письме и его SHA-256 для БД.
+// Токен 256-битный, поэтому медленный хэш не нужен, а поиск по хэшу остаётся простым.
+func NewOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken — SHA-256 токена из ссылки.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,104 @@
+// Package cache — кэш результатов чтения из БД в памяти процесса.
+// Записи живут ttl и сбрасываются раньше, когда обработчик записи вызывает Invalidate.
+// Между репликами кэш не синхронизируется: другая реплика увидит изменение не позже чем через ttl.
+package cache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/metrics"
+)
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// Cache — ключ → значение с временем жизни. Ключи вида "news" и "news:5":
+// часть до двоеточия — ресурс, по нему работают Invalidate и метрики.
+type Cache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	items     map[string]entry
+	gen       uint64 // увеличивается на каждом Invalidate
+	lastSweep time.Time
+}
+
+// New создаёт кэш; ttl <= 0 — кэш выключен, Load всегда читает из источника.
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, items: make(map[string]entry)}
+}
+
+// Invalidate удаляет записи ресурсов: "news" удаляет "news" и все "news:*".
+func (c *Cache) Invalidate(resources ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for key := range c.items {
+		if r := resource(key); slices.Contains(resources, r) {
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *Cache) get(key string, now time.Time) (any, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if ok && now.After(e.expires) {
+		delete(c.items, key)
+		ok = false
+	}
+	return e.value, c.gen, ok
+}
+
+// set сохраняет значение, только если с начала загрузки не было Invalidate:
+// иначе в кэш попали бы данные, прочитанные до записи.
+func (c *Cache) set(key string, value any, gen uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if now.Sub(c.lastSweep) > c.ttl {
+		for k, e := range c.items {
+			if now.After(e.expires) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.items[key] = entry{value: value, expires: now.Add(c.ttl)}
+}
+
+// Load отдаёт значение по ключу из кэша или загружает его через load и запоминает.
+// Ошибки не кэшируются. Значение общее для всех читателей — изменять его нельзя.
+func Load[T any](ctx context.Context, c *Cache, key string, load func(context.Context) (T, error)) (T, error) {
+	if c == nil || c.ttl <= 0 {
+		return load(ctx)
+	}
+	now := time.Now()
+	v, gen, ok := c.get(key, now)
+	if ok {
+		metrics.CacheLookups.WithLabelValues(resource(key), "hit").Inc()
+		return v.(T), nil
+	}
+	metrics.CacheLookups.WithLabelValues(resource(key), "miss").Inc()
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	c.set(key, value, gen, now)
+	return value, nil
+}
+
+func resource(key string) string {
+	r, _, _ := strings.Cut(key, ":")
+	return r
+}
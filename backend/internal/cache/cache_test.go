@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetSkipsAfterInvalidate(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Now()
+
+	// Загрузка началась, пока шла запись: Invalidate между get и set.
+	_, gen, ok := c.get("news", now)
+	if ok {
+		t.Fatal("empty cache hit")
+	}
+	c.Invalidate("news")
+	c.set("news", "stale", gen, now)
+	if _, _, ok := c.get("news", now); ok {
+		t.Error("value loaded before Invalidate was cached")
+	}
+
+	// Invalidate другого ресурса тоже меняет поколение: кэш теряет одну запись, но не врёт.
+	_, gen, _ = c.get("news", now)
+	c.Invalidate("fines")
+	c.set("news", "maybe stale", gen, now)
+	if _, _, ok := c.get("news", now); ok {
+		t.Error("value cached across an Invalidate of another resource")
+	}
+
+	_, gen, _ = c.get("news", now)
+	c.set("news", "fresh", gen, now)
+	if v, _, ok := c.get("news", now); !ok || v != "fresh" {
+		t.Errorf("get = (%v, %v), want (fresh, true)", v, ok)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Now()
+	_, gen, _ := c.get("news", now)
+	c.set("news", 1, gen, now)
+
+	if _, _, ok := c.get("news", now.Add(59*time.Second)); !ok {
+		t.Error("entry expired before ttl")
+	}
+	if _, _, ok := c.get("news", now.Add(61*time.Second)); ok {
+		t.Error("entry alive after ttl")
+	}
+}
+
+func TestInvalidateResources(t *testing.T) {
+	c := New(time.Minute)
+	now := time.Now()
+	for _, key := range []string{"news", "news:5", "newsletter", "fines:1"} {
+		_, gen, _ := c.get(key, now)
+		c.set(key, key, gen, now)
+	}
+
+	c.Invalidate("news")
+	for key, want := range map[string]bool{"news": false, "news:5": false, "newsletter": true, "fines:1": true} {
+		if _, _, ok := c.get(key, now); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	c := New(time.Minute)
+	calls := 0
+	load := func(context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, err := Load(ctx, c, "news", load); err != nil || v != 1 {
+			t.Fatalf("Load = (%d, %v), want (1, nil)", v, err)
+		}
+	}
+	c.Invalidate("news")
+	if v, _ := Load(ctx, c, "news", load); v != 2 {
+		t.Errorf("Load after Invalidate = %d, want a fresh value 2", v)
+	}
+
+	// Запись во время загрузки: прочитанное значение отдаётся, но не кэшируется.
+	racing := func(context.Context) (int, error) {
+		c.Invalidate("fines")
+		return -1, nil
+	}
+	if v, _ := Load(ctx, c, "fines", racing); v != -1 {
+		t.Errorf("Load = %d, want -1", v)
+	}
+	if v, _ := Load(ctx, c, "fines", load); v != 3 {
+		t.Errorf("Load after a racing write = %d, want 3 (not the cached -1)", v)
+	}
+
+	// Ошибки не кэшируются.
+	errLoad := errors.New("db down")
+	if _, err := Load(ctx, c, "team", func(context.Context) (int, error) { return 0, errLoad }); err != errLoad {
+		t.Errorf("err = %v, want %v", err, errLoad)
+	}
+	if v, _ := Load(ctx, c, "team", load); v != 4 {
+		t.Errorf("Load after an error = %d, want 4", v)
+	}
+}
+
+func TestLoadDisabled(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	load := func(context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+	for _, c := range []*Cache{nil, New(0)} {
+		calls = 0
+		Load(ctx, c, "news", load)
+		Load(ctx, c, "news", load)
+		if calls != 2 {
+			t.Errorf("disabled cache: load called %d times, want 2", calls)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"slices"
+	"testing"
+)
+
+func publishRange(b *Bus, from, to int64) {
+	for id := from; id <= to; id++ {
+		b.Publish(Event{ID: id, Type: NewsUpdated})
+	}
+}
+
+func ids(events []Event) []int64 {
+	res := make([]int64, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
+
+func TestSubscribeHistory(t *testing.T) {
+	b := NewBus(3)
+	publishRange(b, 1, 5) // в истории остаются 3, 4, 5
+
+	tests := []struct {
+		name         string
+		after        int64
+		wantMissed   []int64
+		wantComplete bool
+	}{
+		{"no history requested", 0, nil, true},
+		{"history covers after", 3, []int64{4, 5}, true},
+		{"up to date", 5, nil, true},
+		{"history starts later", 1, []int64{3, 4, 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, complete := b.Subscribe(tt.after)
+			defer b.Unsubscribe(sub)
+			if got := ids(missed); !slices.Equal(got, tt.wantMissed) {
+				t.Errorf("missed = %v, want %v", got, tt.wantMissed)
+			}
+			if complete != tt.wantComplete {
+				t.Errorf("complete = %v, want %v", complete, tt.wantComplete)
+			}
+		})
+	}
+
+	// Пустая история после перезапуска: продолжить с after нельзя.
+	if _, _, complete := NewBus(3).Subscribe(7); complete {
+		t.Error("empty history reported complete")
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	b := NewBus(0)
+	slow, _, _ := b.Subscribe(0)
+	fast, _, _ := b.Subscribe(0)
+
+	publishRange(b, 1, subscriberBuffer)
+	for i := 0; i < subscriberBuffer; i++ {
+		<-fast.C
+	}
+	// Буфер slow полон — следующее событие отключает его, fast получает событие.
+	b.Publish(Event{ID: subscriberBuffer + 1, Type: NewsUpdated})
+
+	if e, ok := <-fast.C; !ok || e.ID != subscriberBuffer+1 {
+		t.Errorf("fast subscriber got (%v, %v), want event %d", e.ID, ok, subscriberBuffer+1)
+	}
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("slow subscriber received %d events before being dropped, want %d", received, subscriberBuffer)
+	}
+
+	// Повторная отписка отключённого подписчика ничего не делает.
+	b.Unsubscribe(slow)
+	b.Unsubscribe(fast)
+	if _, ok := <-fast.C; ok {
+		t.Error("channel open after Unsubscribe")
+	}
+}
+
+func TestClose(t *testing.T) {
+	b := NewBus(10)
+	sub, _, _ := b.Subscribe(0)
+	b.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription open after Close")
+	}
+
+	b.Publish(Event{ID: 1, Type: NewsUpdated})
+	late, missed, complete := b.Subscribe(0)
+	if _, ok := <-late.C; ok || len(missed) != 0 || !complete {
+		t.Error("Subscribe after Close must return a closed subscription without history")
+	}
+	if !b.Closed() {
+		t.Error("Closed() = false after Close")
+	}
+}
@@ -0,0 +1,244 @@
+// Package events — шина событий об изменениях данных: store публикует событие после
+// успешной записи, подписчики (поток /api/events) получают его сразу.
+// С EVENTS_PG_NOTIFY события идут через PostgreSQL NOTIFY и доходят до всех реплик.
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/metrics"
+)
+
+// Типы событий: <ресурс>.<действие>.
+const (
+	NewsCreated = "news.created"
+	NewsUpdated = "news.updated"
+	NewsDeleted = "news.deleted"
+
+	ServiceCreated = "service.created"
+	ServiceUpdated = "service.updated"
+	ServiceDeleted = "service.deleted"
+
+	TeamMemberCreated = "team_member.created"
+	TeamMemberUpdated = "team_member.updated"
+	TeamMemberDeleted = "team_member.deleted"
+
+	ProjectCreated = "project.created"
+	ProjectUpdated = "project.updated"
+	ProjectDeleted = "project.deleted"
+
+	VacancyCreated = "vacancy.created"
+	VacancyUpdated = "vacancy.updated"
+	VacancyDeleted = "vacancy.deleted"
+
+	FineCreated = "fine.created"
+	FineUpdated = "fine.updated"
+	FineDeleted = "fine.deleted"
+
+	EvacuationCreated      = "evacuation.created"
+	EvacuationRouteCreated = "evacuation_route.created"
+
+	TrafficLightCreated       = "traffic_light.created"
+	TrafficLightUpdated       = "traffic_light.updated"
+	TrafficLightDeleted       = "traffic_light.deleted"
+	TrafficLightStatusChanged = "traffic_light.status_changed"
+
+	// FeedbackPublished — обращение появилось в публичной ленте или у него изменился ответ;
+	// FeedbackWithdrawn — снято с публикации.
+	FeedbackPublished = "feedback.published"
+	FeedbackWithdrawn = "feedback.withdrawn"
+
+	// StatsUpdated — изменились данные, из которых считаются /api/stats и /api/traffic.
+	StatsUpdated = "stats.updated"
+)
+
+// Types — все типы событий.
+var Types = []string{
+	NewsCreated, NewsUpdated, NewsDeleted,
+	ServiceCreated, ServiceUpdated, ServiceDeleted,
+	TeamMemberCreated, TeamMemberUpdated, TeamMemberDeleted,
+	ProjectCreated, ProjectUpdated, ProjectDeleted,
+	VacancyCreated, VacancyUpdated, VacancyDeleted,
+	FineCreated, FineUpdated, FineDeleted,
+	EvacuationCreated, EvacuationRouteCreated,
+	TrafficLightCreated, TrafficLightUpdated, TrafficLightDeleted, TrafficLightStatusChanged,
+	FeedbackPublished, FeedbackWithdrawn,
+	StatsUpdated,
+}
+
+// Match сообщает, подходит ли тип под фильтр: точный тип, маска "ресурс.*" или "*" — все события.
+func Match(pattern, typ string) bool {
+	if pattern == "*" || pattern == typ {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(typ, prefix)
+}
+
+// ValidPattern — фильтр подходит хотя бы под один тип из Types (защита от опечаток в подписках).
+func ValidPattern(pattern string) bool {
+	for _, t := range Types {
+		if Match(pattern, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Event — событие шины. ID растёт от события к событию (последовательность в БД, общая для реплик);
+// Data — небольшое описание изменения, полные данные клиент читает из API.
+type Event struct {
+	ID   int64           `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+	Time time.Time       `json:"time"`
+}
+
+// Ref — данные событий *.created, *.updated и *.deleted: id записи и её версия после изменения.
+type Ref struct {
+	ID      int `json:"id"`
+	Version int `json:"version,omitempty"`
+}
+
+// NewsRef — данные news.created: заголовок и тег, чтобы показать уведомление без запроса к API.
+type NewsRef struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Tag   string `json:"tag"`
+}
+
+// RouteRef — данные evacuation_route.created.
+type RouteRef struct {
+	ID    int    `json:"id"`
+	Year  int    `json:"year"`
+	Month string `json:"month"`
+	Route string `json:"route"`
+}
+
+// StatusChange — данные traffic_light.status_changed.
+type StatusChange struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// StatsSource — данные stats.updated: таблица, из-за которой изменилась статистика.
+type StatsSource struct {
+	Source string `json:"source"`
+}
+
+// subscriberBuffer — сколько событий ждёт медленного подписчика, прежде чем его отключат.
+const subscriberBuffer = 64
+
+// Subscription — подписка на шину. C закрывается, когда шина отключает подписчика
+// (не успевает читать или шина закрыта); клиент переподключается с последним ID.
+type Subscription struct {
+	C  <-chan Event
+	ch chan Event
+}
+
+// Bus рассылает события подписчикам и хранит последние history событий
+// для продолжения потока после переподключения.
+type Bus struct {
+	size int
+
+	mu      sync.Mutex
+	history []Event
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+// NewBus создаёт шину, которая помнит history последних событий.
+func NewBus(history int) *Bus {
+	return &Bus{size: history, subs: make(map[*Subscription]struct{})}
+}
+
+// Publish запоминает событие и отправляет его всем подписчикам.
+// Подписчик с заполненным буфером отключается, чтобы не задерживать остальных.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	metrics.EventsPublished.WithLabelValues(e.Type).Inc()
+	if b.size > 0 {
+		b.history = append(b.history, e)
+		if len(b.history) > b.size {
+			b.history = b.history[len(b.history)-b.size:]
+		}
+	}
+	for sub := range b.subs {
+		select {
+		case sub.ch <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe подписывает на новые события и возвращает сохранённые события с ID больше after.
+// complete = false, если история не доходит до after (события могли потеряться или сервер
+// перезапускался) — тогда клиенту нужно перечитать данные целиком. after = 0 — без истории.
+func (b *Bus) Subscribe(after int64) (sub *Subscription, missed []Event, complete bool) {
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return sub, nil, after == 0
+	}
+	b.subs[sub] = struct{}{}
+	metrics.EventSubscribers.Inc()
+
+	if after == 0 {
+		return sub, nil, true
+	}
+	for _, e := range b.history {
+		if e.ID <= after {
+			complete = true
+		} else {
+			missed = append(missed, e)
+		}
+	}
+	return sub, missed, complete
+}
+
+// Unsubscribe отменяет подписку; повторный вызов ничего не делает.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		b.drop(sub)
+	}
+}
+
+// Close отключает всех подписчиков (при остановке сервера, чтобы открытые потоки
+// не задерживали Shutdown); события после Close не рассылаются.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// Closed сообщает, что шина закрыта.
+func (b *Bus) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *Bus) drop(sub *Subscription) {
+	delete(b.subs, sub)
+	close(sub.ch)
+	metrics.EventSubscribers.Dec()
+}
@@ -0,0 +1,177 @@
+// Package health — проверки живости и готовности сервиса для /healthz и /readyz.
+// Зависимости (БД, миграции) регистрируются как проверки, фоновые воркеры —
+// через Worker; при остановке сервер переводится в режим draining,
+// и готовность отвечает отказом, пока балансировщик не уберёт трафик.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// CheckFunc — проверка зависимости; nil означает "здорово".
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult — результат одной проверки в отчёте /readyz. Отчёт публичный,
+// поэтому текст ошибки в него не попадает — он пишется в лог.
+type CheckResult struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// WorkerStatus — состояние фонового воркера в отчёте /readyz.
+type WorkerStatus struct {
+	Running bool `json:"running"`
+}
+
+// Report — подробный ответ /readyz.
+type Report struct {
+	Status  string                  `json:"status"`
+	Checks  map[string]CheckResult  `json:"checks"`
+	Workers map[string]WorkerStatus `json:"workers,omitempty"`
+}
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker хранит проверки, воркеры и флаг остановки.
+type Checker struct {
+	timeout  time.Duration
+	started  time.Time
+	draining atomic.Bool
+
+	mu      sync.RWMutex
+	checks  []namedCheck
+	workers map[string]*Worker
+}
+
+// New создаёт Checker; timeout ограничивает каждую проверку.
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		started: time.Now(),
+		workers: make(map[string]*Worker),
+	}
+}
+
+// AddCheck регистрирует проверку зависимости.
+func (c *Checker) AddCheck(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, fn: fn})
+}
+
+// Worker регистрирует фоновый воркер; пока он не отметится Running, сервис не готов.
+func (c *Checker) Worker(name string) *Worker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &Worker{name: name}
+	c.workers[name] = w
+	return w
+}
+
+// Drain переводит сервис в режим остановки: /readyz начинает отвечать 503.
+func (c *Checker) Drain() { c.draining.Store(true) }
+
+// Draining сообщает, идёт ли остановка.
+func (c *Checker) Draining() bool { return c.draining.Load() }
+
+// Uptime — время с запуска процесса.
+func (c *Checker) Uptime() time.Duration { return time.Since(c.started) }
+
+// Ready выполняет все проверки параллельно и собирает отчёт.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	workers := make(map[string]WorkerStatus, len(c.workers))
+	for name, w := range c.workers {
+		workers[name] = w.status()
+	}
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func(i int, chk namedCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx, chk)
+		}(i, chk)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	if len(workers) > 0 {
+		report.Workers = workers
+	}
+	for i, chk := range checks {
+		report.Checks[chk.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	for _, w := range workers {
+		if !w.Running {
+			report.Status = StatusFail
+		}
+	}
+	if c.Draining() {
+		report.Status = StatusDraining
+	}
+	return report, report.Status == StatusOK
+}
+
+func (c *Checker) run(ctx context.Context, chk namedCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := chk.fn(ctx)
+	res := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status = StatusFail
+		slog.Warn("Readiness check failed", "check", chk.name, "err", err)
+	}
+	return res
+}
+
+// Worker — отметка о работе фонового воркера.
+type Worker struct {
+	name    string
+	mu      sync.Mutex
+	running bool
+}
+
+// Running отмечает, что воркер запущен и работает.
+func (w *Worker) Running() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running = true
+}
+
+// Stopped отмечает остановку воркера; err — причина, если он упал (пишется в лог).
+func (w *Worker) Stopped(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running = false
+	if err != nil {
+		slog.Error("Background worker stopped", "worker", w.name, "err", err)
+	}
+}
+
+func (w *Worker) status() WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WorkerStatus{Running: w.running}
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadyReport(t *testing.T) {
+	leak := errors.New(`pq: password authentication failed for user "postgres" at 10.0.3.7:5432`)
+
+	tests := []struct {
+		name    string
+		check   error
+		running bool
+		drain   bool
+		status  string
+		ready   bool
+	}{
+		{"ready", nil, true, false, StatusOK, true},
+		{"check fails", leak, true, false, StatusFail, false},
+		{"worker not running", nil, false, false, StatusFail, false},
+		{"draining", nil, true, true, StatusDraining, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(time.Second)
+			c.AddCheck("database", func(context.Context) error { return tt.check })
+			w := c.Worker("cleanup")
+			if tt.running {
+				w.Running()
+			} else {
+				w.Stopped(leak)
+			}
+			if tt.drain {
+				c.Drain()
+			}
+
+			report, ready := c.Ready(context.Background())
+			if report.Status != tt.status || ready != tt.ready {
+				t.Errorf("status = %s, ready = %v; want %s, %v", report.Status, ready, tt.status, tt.ready)
+			}
+			body, err := json.Marshal(report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Отчёт публичный: текст ошибок остаётся в логах.
+			if strings.Contains(string(body), "pq:") || strings.Contains(string(body), "10.0.3.7") {
+				t.Errorf("report leaks error details: %s", body)
+			}
+		})
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	c := New(20 * time.Millisecond)
+	c.AddCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	report, ready := c.Ready(context.Background())
+	if ready || report.Checks["slow"].Status != StatusFail {
+		t.Errorf("slow check: %+v", report.Checks["slow"])
+	}
+}
@@ -0,0 +1,57 @@
+// Package logging — JSON-логи на slog с логгером на каждый запрос.
+// Логгер с request_id (и trace_id) кладётся в context.Context мидлварами api
+// и достаётся в хендлерах и store через FromContext.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// New создаёт JSON-логгер в stdout с указанным уровнем и редактированием персональных данных.
+func New(level string) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: redact,
+	}))
+}
+
+// Setup создаёт логгер и делает его логгером по умолчанию —
+// в том числе для оставшихся вызовов стандартного пакета log.
+func Setup(level string) *slog.Logger {
+	logger := New(level)
+	slog.SetDefault(logger)
+	return logger
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithLogger возвращает контекст с логгером запроса.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext возвращает логгер запроса, а вне запроса — логгер по умолчанию.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"backend/pkg"
+)
+
+// Ключи, значения которых никогда не пишутся в лог.
+var secretKeys = []string{"password", "token", "secret", "authorization", "jwt_secret", "db_password"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// MaskEmail оставляет первую букву локальной части и домен: admin@smolensk.ru -> a***@smolensk.ru.
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// redact — ReplaceAttr для slog: скрывает секреты по ключу и маскирует email
+// как в атрибуте email, так и внутри любых строк (включая сообщение), в том числе
+// в тексте ошибок ("err", err) и значений с методом String.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	if pkg.Contains(secretKeys, key) {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(maskEmails(a.Value.String()))
+	case slog.KindAny:
+		var s string
+		switch v := a.Value.Any().(type) {
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			return a
+		}
+		// Значение без email остаётся как есть: JSON-обработчик может выводить его иначе, чем String.
+		if masked := maskEmails(s); masked != s {
+			a.Value = slog.StringValue(masked)
+		}
+	}
+	return a
+}
+
+func maskEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+}
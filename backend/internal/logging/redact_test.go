@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// logLine пишет одну запись через redact и возвращает её поля.
+func logLine(t *testing.T, msg string, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact})).Info(msg, args...)
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%s: %v", buf.String(), err)
+	}
+	return line
+}
+
+func TestRedact(t *testing.T) {
+	pqErr := fmt.Errorf("create user: %w",
+		errors.New(`pq: duplicate key value violates unique constraint "users_email_key": Key (email)=(ivanov@smolensk.ru) already exists.`))
+	link, _ := url.Parse("https://codd.example/invite?email=petrov@smolensk.ru")
+
+	tests := []struct {
+		name string
+		args []any
+		key  string
+		want any
+	}{
+		{"email attr", []any{"email", "admin@smolensk.ru"}, "email", "a***@smolensk.ru"},
+		{"email in string", []any{"note", "sent to editor@smolensk.ru and admin@codd.example"}, "note", "sent to e***@smolensk.ru and a***@codd.example"},
+		{"error", []any{"err", pqErr}, "err",
+			`create user: pq: duplicate key value violates unique constraint "users_email_key": Key (email)=(i***@smolensk.ru) already exists.`},
+		{"error without email", []any{"err", errors.New("connection refused")}, "err", "connection refused"},
+		{"stringer", []any{"url", link}, "url", "https://codd.example/invite?email=p***@smolensk.ru"},
+		{"password", []any{"password", "hunter2"}, "password", "[REDACTED]"},
+		{"token any case", []any{"Token", "eyJhbGciOi"}, "Token", "[REDACTED]"},
+		{"secret non-string", []any{"secret", 42}, "secret", "[REDACTED]"},
+		{"jwt_secret", []any{"jwt_secret", "s3cr3t"}, "jwt_secret", "[REDACTED]"},
+		{"authorization", []any{"authorization", "Bearer abc"}, "authorization", "[REDACTED]"},
+		{"number", []any{"user_id", 7}, "user_id", float64(7)},
+		{"struct kept", []any{"body", struct{ Email string }{"x@example.ru"}}, "body", map[string]any{"Email": "x@example.ru"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := logLine(t, "test", tt.args...)
+			if got := fmt.Sprint(line[tt.key]); got != fmt.Sprint(tt.want) {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactMessageAndGroups(t *testing.T) {
+	line := logLine(t, "Invite sent to petrov@smolensk.ru",
+		slog.Group("user", "email", "petrov@smolensk.ru", "password", "x"),
+		"err", fmt.Errorf("smtp: 550 mailbox petrov@smolensk.ru unavailable"))
+	raw, _ := json.Marshal(line)
+	if strings.Contains(string(raw), "petrov@") || strings.Contains(string(raw), `"x"`) {
+		t.Errorf("personal data leaked: %s", raw)
+	}
+	if got := line["msg"]; got != "Invite sent to p***@smolensk.ru" {
+		t.Errorf("msg = %v", got)
+	}
+}
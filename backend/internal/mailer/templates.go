@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// Шаблоны писем: для каждого письма <name>.txt.tmpl (с блоком "subject") и <name>.html.tmpl.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = template.Must(template.ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Имена писем.
+const (
+	TemplatePasswordReset = "password_reset"
+	TemplateInvite        = "invite"
+)
+
+// LinkData — данные писем со ссылкой.
+type LinkData struct {
+	Link      string
+	ExpiresIn string
+	Role      string
+}
+
+// Render собирает письмо name для адресата to.
+func Render(name, to string, data any) (Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,137 @@
+// Package metrics — метрики Prometheus для /metrics.
+// Все метрики регистрируются в собственном реестре, а не в глобальном,
+// чтобы в выдачу не попадало ничего лишнего из сторонних библиотек.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "smolathon"
+
+// Registry — реестр всех метрик сервиса.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// HTTPRequests — число запросов по маршруту (шаблону Gin, не сырому пути) и статусу.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration — время обработки запроса.
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// StoreDuration — время выполнения методов store.
+	StoreDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_query_duration_seconds",
+		Help:      "Store method latency by method name.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	// Logins — попытки входа по эндпоинту (admin, editor, user) и результату.
+	Logins = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by endpoint and result.",
+	}, []string{"endpoint", "result"})
+
+	// CacheLookups — обращения к кэшу чтения по ресурсу (news, services, ...) и результату (hit, miss).
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "In-process read cache lookups by resource and result.",
+	}, []string{"resource", "result"})
+
+	// EventsPublished — события шины по типу (news.created, stats.updated, ...).
+	EventsPublished = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "Change events published to the in-process bus by type.",
+	}, []string{"type"})
+
+	// WebhookDeliveries — попытки доставки вебхуков по итогу: delivered, retry (будет повтор), failed.
+	WebhookDeliveries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts by result.",
+	}, []string{"result"})
+
+	// TelegramMessages — сообщения Telegram-бота: reply — ответы на команды, notification — рассылка.
+	TelegramMessages = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_messages_total",
+		Help:      "Telegram bot messages by type and result.",
+	}, []string{"type", "result"})
+
+	// EventSubscribers — открытые подписки на шину (потоки /api/events).
+	EventSubscribers = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_subscribers",
+		Help:      "Open event stream subscriptions.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB добавляет статистику пула соединений (sql.DB.Stats):
+// открытые, занятые и простаивающие соединения, число и время ожиданий.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterGauge добавляет бизнес-метрику, которая считается запросом в момент скрейпа.
+// Если запрос упал или не уложился в timeout, значение в этот раз не отдаётся.
+func RegisterGauge(name, help string, timeout time.Duration, value func(ctx context.Context) (float64, error)) {
+	Registry.MustRegister(&queryGauge{
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, nil, nil),
+		timeout: timeout,
+		value:   value,
+	})
+}
+
+type queryGauge struct {
+	desc    *prometheus.Desc
+	timeout time.Duration
+	value   func(ctx context.Context) (float64, error)
+}
+
+func (g *queryGauge) Describe(ch chan<- *prometheus.Desc) { ch <- g.desc }
+
+func (g *queryGauge) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	v, err := g.value(ctx)
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, v)
+}
@@ -0,0 +1,92 @@
+// Package mfa — TOTP (RFC 6238) и одноразовые коды восстановления.
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Issuer — подпись аккаунта в приложении-аутентификаторе.
+const Issuer = "ЦОДД Смоленск"
+
+// RecoveryCodeCount — сколько кодов восстановления выдаётся за раз.
+const RecoveryCodeCount = 10
+
+// GenerateSecret создаёт новый секрет TOTP для account (email) и URI otpauth:// для QR-кода.
+func GenerateSecret(account string) (secret, uri string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      Issuer,
+		AccountName: account,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// Period — длина шага TOTP в секундах.
+const Period = 30
+
+var validateOpts = totp.ValidateOpts{
+	Period:    Period,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// Validate проверяет шестизначный код с допуском ±1 период (30 с) на рассинхрон часов.
+// Код принимается, только если его шаг больше lastStep — шага последнего принятого кода,
+// иначе перехваченный код можно было бы повторить в пределах окна. Возвращает шаг
+// принятого кода: его нужно сохранить как новый lastStep.
+func Validate(code, secret string, lastStep int64) (int64, bool) {
+	return validateAt(strings.TrimSpace(code), secret, lastStep, time.Now())
+}
+
+func validateAt(code, secret string, lastStep int64, now time.Time) (int64, bool) {
+	if len(code) != int(validateOpts.Digits) {
+		return 0, false
+	}
+	current := now.Unix() / Period
+	for _, step := range []int64{current - 1, current, current + 1} {
+		if step <= lastStep {
+			continue
+		}
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*Period, 0), validateOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(want)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes создаёт коды восстановления вида xxxxx-xxxxx и их хэши для БД.
+func NewRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := hex.EncodeToString(b)
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode — SHA-256 от нормализованного кода. Коды случайные (40 бит),
+// поэтому медленный хэш не нужен, а поиск по хэшу остаётся простым.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package mfa
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+func codeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(testSecret, at, validateOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1_700_000_015, 0)
+	step := now.Unix() / Period
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current", now, step, true},
+		{"previous period", now.Add(-Period * time.Second), step - 1, true},
+		{"next period", now.Add(Period * time.Second), step + 1, true},
+		{"two periods ago", now.Add(-2 * Period * time.Second), 0, false},
+		{"two periods ahead", now.Add(2 * Period * time.Second), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := validateAt(codeAt(t, tt.at), testSecret, 0, now)
+			if ok != tt.wantOK || got != tt.wantStep {
+				t.Errorf("validateAt = (%d, %v), want (%d, %v)", got, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := validateAt(code, testSecret, 0, now); ok {
+			t.Errorf("malformed code %q accepted", code)
+		}
+	}
+}
+
+func TestValidateReplay(t *testing.T) {
+	now := time.Unix(1_700_000_015, 0)
+	code := codeAt(t, now)
+
+	step, ok := validateAt(code, testSecret, 0, now)
+	if !ok {
+		t.Fatal("fresh code rejected")
+	}
+	// Тот же код в пределах окна допуска — повтор.
+	if _, ok := validateAt(code, testSecret, step, now.Add(20*time.Second)); ok {
+		t.Error("replayed code accepted")
+	}
+	// Код предыдущего шага после принятого тоже не проходит.
+	if _, ok := validateAt(codeAt(t, now.Add(-Period*time.Second)), testSecret, step, now); ok {
+		t.Error("older code accepted after a newer one")
+	}
+	// Код следующего шага принимается.
+	next := now.Add(Period * time.Second)
+	if got, ok := validateAt(codeAt(t, next), testSecret, step, next); !ok || got != step+1 {
+		t.Errorf("next code = (%d, %v), want (%d, true)", got, ok, step+1)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), RecoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[0-9a-f]{5}-[0-9a-f]{5}$`)
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q does not match xxxxx-xxxxx", code)
+		}
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("hash %d does not match its code", i)
+		}
+		if hashes[i] == code || len(hashes[i]) != 64 {
+			t.Errorf("hash %q is not a SHA-256 hex digest", hashes[i])
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCodeNormalizes(t *testing.T) {
+	want := HashRecoveryCode("a1b2c-3d4e5")
+	for _, input := range []string{"A1B2C-3D4E5", " a1b2c-3d4e5\n", "a1b2c3d4e5"} {
+		if got := HashRecoveryCode(input); got != want {
+			t.Errorf("HashRecoveryCode(%q) differs from the canonical form", input)
+		}
+	}
+	if HashRecoveryCode("a1b2c-3d4e6") == want {
+		t.Error("different codes share a hash")
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Категории обращений.
+const (
+	FeedbackComplaint  = "complaint"
+	FeedbackSuggestion = "suggestion"
+	FeedbackQuestion   = "question"
+)
+
+// FeedbackCategories — допустимые категории обращений.
+var FeedbackCategories = []string{FeedbackComplaint, FeedbackSuggestion, FeedbackQuestion}
+
+// Статусы модерации обращения.
+const (
+	FeedbackNew      = "new"
+	FeedbackApproved = "approved"
+	FeedbackRejected = "rejected"
+)
+
+// Feedback — обращение жителя, как его видит модератор.
+type Feedback struct {
+	ID       int    `json:"id"`
+	Ticket   string `json:"ticket"`
+	Category string `json:"category"`
+	// Location — адрес или перекрёсток, к которому относится обращение.
+	Location string `json:"location"`
+	Text     string `json:"text"`
+	// Contact — email или телефон для ответа; не публикуется.
+	Contact      string     `json:"contact"`
+	Status       string     `json:"status"`
+	RejectReason string     `json:"reject_reason,omitempty"`
+	ModeratedBy  *int       `json:"moderated_by,omitempty"`
+	ModeratedAt  *time.Time `json:"moderated_at,omitempty"`
+	Response     string     `json:"response,omitempty"`
+	RespondedBy  *int       `json:"responded_by,omitempty"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// CreateFeedbackRequest — форма обращения на сайте.
+type CreateFeedbackRequest struct {
+	Category string `json:"category" binding:"required"`
+	Location string `json:"location"`
+	Text     string `json:"text" binding:"required"`
+	Contact  string `json:"contact"`
+	// Website — ловушка для ботов: поле скрыто на форме, человек его не заполняет.
+	Website string `json:"website"`
+}
+
+type RejectFeedbackRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RespondFeedbackRequest struct {
+	Response string `json:"response" binding:"required"`
+}
+
+// PublicFeedback — одобренное обращение в публичной ленте: без контакта и номера.
+type PublicFeedback struct {
+	ID          int        `json:"id"`
+	Category    string     `json:"category"`
+	Location    string     `json:"location"`
+	Text        string     `json:"text"`
+	Response    string     `json:"response,omitempty"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// FeedbackTicket — статус обращения для автора (по номеру).
+type FeedbackTicket struct {
+	Ticket       string     `json:"ticket"`
+	Category     string     `json:"category"`
+	Status       string     `json:"status"`
+	RejectReason string     `json:"reject_reason,omitempty"`
+	Response     string     `json:"response,omitempty"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Public — обращение для публичной ленты.
+func (f *Feedback) Public() PublicFeedback {
+	return PublicFeedback{
+		ID:          f.ID,
+		Category:    f.Category,
+		Location:    f.Location,
+		Text:        f.Text,
+		Response:    f.Response,
+		RespondedAt: f.RespondedAt,
+		CreatedAt:   f.CreatedAt,
+	}
+}
+
+// TicketStatus — обращение для страницы статуса.
+func (f *Feedback) TicketStatus() FeedbackTicket {
+	return FeedbackTicket{
+		Ticket:       f.Ticket,
+		Category:     f.Category,
+		Status:       f.Status,
+		RejectReason: f.RejectReason,
+		Response:     f.Response,
+		RespondedAt:  f.RespondedAt,
+		CreatedAt:    f.CreatedAt,
+		UpdatedAt:    f.UpdatedAt,
+	}
+}
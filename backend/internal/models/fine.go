@@ -9,6 +9,7 @@ type Fine struct {
     OrdersTotal           int       `json:"orders_total" db:"orders_total"`
     FinesAmountTotal      int       `json:"fines_amount_total" db:"fines_amount_total"`
     CollectedAmountTotal  int       `json:"collected_amount_total" db:"collected_amount_total"`
+    Version               int       `json:"version" db:"version"`
     CreatedAt            time.Time `json:"created_at" db:"created_at"`
     UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -21,10 +22,31 @@ type CreateFineRequest struct {
     CollectedAmountTotal  int       `json:"collected_amount_total" binding:"required"`
 }
 
+// UpdateFineRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateFineRequest struct {
-    Date                   time.Time `json:"date"`
-    ViolationsTotal        int       `json:"violations_total"`
-    OrdersTotal           int       `json:"orders_total"`
-    FinesAmountTotal      int       `json:"fines_amount_total"`
-    CollectedAmountTotal  int       `json:"collected_amount_total"`
+    Date                  *time.Time `json:"date"`
+    ViolationsTotal       *int       `json:"violations_total"`
+    OrdersTotal           *int       `json:"orders_total"`
+    FinesAmountTotal      *int       `json:"fines_amount_total"`
+    CollectedAmountTotal  *int       `json:"collected_amount_total"`
+    Version               *int       `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на f (для проверки итогового состояния перед записью).
+func (r *UpdateFineRequest) ApplyTo(f *Fine) {
+    if r.Date != nil {
+        f.Date = *r.Date
+    }
+    if r.ViolationsTotal != nil {
+        f.ViolationsTotal = *r.ViolationsTotal
+    }
+    if r.OrdersTotal != nil {
+        f.OrdersTotal = *r.OrdersTotal
+    }
+    if r.FinesAmountTotal != nil {
+        f.FinesAmountTotal = *r.FinesAmountTotal
+    }
+    if r.CollectedAmountTotal != nil {
+        f.CollectedAmountTotal = *r.CollectedAmountTotal
+    }
 }
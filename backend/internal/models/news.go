@@ -8,6 +8,7 @@ type News struct {
     Content   string    `json:"content" db:"content"`
     Tag       string    `json:"tag" db:"tag"`
     Date      time.Time `json:"date" db:"date"`
+    Version   int       `json:"version" db:"version"`
     CreatedAt time.Time `json:"created_at" db:"created_at"`
     UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -18,8 +19,23 @@ type CreateNewsRequest struct {
     Tag     string `json:"tag" binding:"required"`
 }
 
+// UpdateNewsRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateNewsRequest struct {
-    Title   string `json:"title"`
-    Content string `json:"content"`
-    Tag     string `json:"tag"`
+    Title   *string `json:"title"`
+    Content *string `json:"content"`
+    Tag     *string `json:"tag"`
+    Version *int    `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на n (для проверки итогового состояния перед записью).
+func (r *UpdateNewsRequest) ApplyTo(n *News) {
+    if r.Title != nil {
+        n.Title = *r.Title
+    }
+    if r.Content != nil {
+        n.Content = *r.Content
+    }
+    if r.Tag != nil {
+        n.Tag = *r.Tag
+    }
 }
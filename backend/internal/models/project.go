@@ -8,6 +8,7 @@ type Project struct {
     Description string    `json:"description" db:"description"`
     Category    string    `json:"category" db:"category"`
     Status      string    `json:"status" db:"status"`
+    Version     int       `json:"version" db:"version"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -19,9 +20,27 @@ type CreateProjectRequest struct {
     Status      string `json:"status"`
 }
 
+// UpdateProjectRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateProjectRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
-    Category    string `json:"category"`
-    Status      string `json:"status"`
+    Title       *string `json:"title"`
+    Description *string `json:"description"`
+    Category    *string `json:"category"`
+    Status      *string `json:"status"`
+    Version     *int    `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на p (для проверки итогового состояния перед записью).
+func (r *UpdateProjectRequest) ApplyTo(p *Project) {
+    if r.Title != nil {
+        p.Title = *r.Title
+    }
+    if r.Description != nil {
+        p.Description = *r.Description
+    }
+    if r.Category != nil {
+        p.Category = *r.Category
+    }
+    if r.Status != nil {
+        p.Status = *r.Status
+    }
 }
@@ -0,0 +1,25 @@
+package models
+
+// Role — роль пользователя и её права.
+type Role struct {
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// Permission — право из матрицы (например, news:write).
+type Permission struct {
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// SetRolePermissionsRequest — полный новый набор прав роли.
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
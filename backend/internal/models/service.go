@@ -9,6 +9,7 @@ type Service struct {
     Price       int       `json:"price" db:"price"`
     Category    string    `json:"category" db:"category"`
     IconURL     string    `json:"icon_url" db:"icon_url"`
+    Version     int       `json:"version" db:"version"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -21,10 +22,31 @@ type CreateServiceRequest struct {
     IconURL     string `json:"icon_url"`
 }
 
+// UpdateServiceRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateServiceRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
-    Price       int    `json:"price"`
-    Category    string `json:"category"`
-    IconURL     string `json:"icon_url"`
+    Title       *string `json:"title"`
+    Description *string `json:"description"`
+    Price       *int    `json:"price"`
+    Category    *string `json:"category"`
+    IconURL     *string `json:"icon_url"`
+    Version     *int    `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на s (для проверки итогового состояния перед записью).
+func (r *UpdateServiceRequest) ApplyTo(s *Service) {
+    if r.Title != nil {
+        s.Title = *r.Title
+    }
+    if r.Description != nil {
+        s.Description = *r.Description
+    }
+    if r.Price != nil {
+        s.Price = *r.Price
+    }
+    if r.Category != nil {
+        s.Category = *r.Category
+    }
+    if r.IconURL != nil {
+        s.IconURL = *r.IconURL
+    }
 }
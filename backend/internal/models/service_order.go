@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Статусы заказа услуги: new → in_progress → done; отменить можно любой незавершённый.
+const (
+	OrderNew        = "new"
+	OrderInProgress = "in_progress"
+	OrderDone       = "done"
+	OrderCancelled  = "cancelled"
+)
+
+// OrderStatuses — допустимые статусы заказа.
+var OrderStatuses = []string{OrderNew, OrderInProgress, OrderDone, OrderCancelled}
+
+// orderTransitions — в какие статусы можно перевести заказ из текущего.
+var orderTransitions = map[string][]string{
+	OrderNew:        {OrderInProgress, OrderCancelled},
+	OrderInProgress: {OrderDone, OrderCancelled},
+}
+
+// CanOrderTransition сообщает, можно ли перевести заказ из статуса from в to.
+func CanOrderTransition(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceOrder — заказ платной услуги. Название, категория и цена — снимок услуги
+// на момент заказа; ServiceID обнуляется, если услугу удалили из каталога.
+type ServiceOrder struct {
+	ID              int    `json:"id"`
+	Number          string `json:"number"`
+	ServiceID       *int   `json:"service_id"`
+	ServiceTitle    string `json:"service_title"`
+	ServiceCategory string `json:"service_category"`
+	Price           int    `json:"price"`
+	CustomerName    string `json:"customer_name"`
+	Phone           string `json:"phone"`
+	Email           string `json:"email,omitempty"`
+	Comment         string `json:"comment,omitempty"`
+	Status          string `json:"status"`
+	// AssignedTo — сотрудник, который ведёт заказ.
+	AssignedTo *int      `json:"assigned_to"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreateServiceOrderRequest — форма заказа услуги на сайте.
+type CreateServiceOrderRequest struct {
+	ServiceID    int    `json:"service_id" binding:"required"`
+	CustomerName string `json:"customer_name" binding:"required"`
+	Phone        string `json:"phone" binding:"required"`
+	Email        string `json:"email"`
+	Comment      string `json:"comment"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// AssignOrderRequest — назначение сотрудника; null снимает назначение.
+type AssignOrderRequest struct {
+	UserID *int `json:"user_id"`
+}
+
+// ServiceOrderFilter — фильтры списка заказов; нулевые значения не ограничивают выборку.
+type ServiceOrderFilter struct {
+	Status     string
+	Category   string
+	ServiceID  int
+	AssignedTo int
+	// Unassigned — только заказы без исполнителя (вместо AssignedTo).
+	Unassigned bool
+	From, To   time.Time
+}
+
+// OrderTotals — итоги по заказам одной категории услуг.
+type OrderTotals struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	// Amount — сумма цен заказов (без отменённых).
+	Amount int `json:"amount"`
+}
+
+// OrderTicket — статус заказа для заказчика (по номеру), без личных данных.
+type OrderTicket struct {
+	Number       string    `json:"number"`
+	ServiceTitle string    `json:"service_title"`
+	Price        int       `json:"price"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Ticket — заказ для страницы статуса.
+func (o *ServiceOrder) Ticket() OrderTicket {
+	return OrderTicket{
+		Number:       o.Number,
+		ServiceTitle: o.ServiceTitle,
+		Price:        o.Price,
+		Status:       o.Status,
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Session — выданный токен доступа: откуда и когда вошли, когда последний раз пользовались.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"user_id"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// Current — сессия, с которой сделан запрос.
+	Current bool `json:"current,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SigningKey — ключ подписи JWT. Подписывает самый новый ключ с NotBefore в прошлом;
+// заменённый ключ (RetiredAt) ещё какое-то время годится для проверки выданных им токенов.
+type SigningKey struct {
+	KID        string
+	Algorithm  string
+	PrivateKey []byte // PKCS#8 DER
+	NotBefore  time.Time
+	RetiredAt  *time.Time
+	CreatedAt  time.Time
+}
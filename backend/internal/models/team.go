@@ -8,6 +8,7 @@ type TeamMember struct {
     Position   string     `json:"position" db:"position"`
     Experience string     `json:"experience" db:"experience"`
     PhotoURL   *string    `json:"photo_url,omitempty" db:"photo_url"`
+    Version    int        `json:"version" db:"version"`
     CreatedAt  *time.Time `json:"created_at,omitempty" db:"created_at"`
     UpdatedAt  *time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
@@ -21,11 +22,29 @@ type CreateTeamMemberRequest struct {
     PhotoURL   *string `json:"photo_url"`
 }
 
-// Запрос на обновление участника.
-// Все поля опциональны.
+// Запрос на обновление участника (PUT/PATCH, RFC 7396).
+// Все поля опциональны: nil не меняет колонку, photo_url: null убирает фото.
+// version (или If-Match) обязателен.
 type UpdateTeamMemberRequest struct {
     Name       *string `json:"name"`
     Position   *string `json:"position"`
     Experience *string `json:"experience"`
     PhotoURL   *string `json:"photo_url"`
+    Version    *int    `json:"version"`
+}
+
+// ApplyTo накладывает переданные поля на m (для проверки итогового состояния перед записью).
+func (r *UpdateTeamMemberRequest) ApplyTo(m *TeamMember) {
+    if r.Name != nil {
+        m.Name = *r.Name
+    }
+    if r.Position != nil {
+        m.Position = *r.Position
+    }
+    if r.Experience != nil {
+        m.Experience = *r.Experience
+    }
+    if r.PhotoURL != nil {
+        m.PhotoURL = r.PhotoURL
+    }
 }
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Виды подписок в Telegram-боте.
+const (
+	TelegramNews   = "news"
+	TelegramRoutes = "routes"
+)
+
+// TelegramSubscription — подписка чата на уведомления.
+type TelegramSubscription struct {
+	ChatID int64
+	Kind   string
+	// Tag — тег новостей; пустой — все новости.
+	Tag       string
+	CreatedAt time.Time
+}
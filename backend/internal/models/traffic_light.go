@@ -8,6 +8,7 @@ type TrafficLight struct {
     LightType   string    `json:"light_type" db:"light_type"`
     InstallYear int       `json:"install_year" db:"install_year"`
     Status      string    `json:"status" db:"status"`
+    Version     int       `json:"version" db:"version"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -19,9 +20,27 @@ type CreateTrafficLightRequest struct {
     Status      string `json:"status"`
 }
 
+// UpdateTrafficLightRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateTrafficLightRequest struct {
-    Address     string `json:"address"`
-    LightType   string `json:"light_type"`
-    InstallYear int    `json:"install_year"`
-    Status      string `json:"status"`
+    Address     *string `json:"address"`
+    LightType   *string `json:"light_type"`
+    InstallYear *int    `json:"install_year"`
+    Status      *string `json:"status"`
+    Version     *int    `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на t (для проверки итогового состояния перед записью).
+func (r *UpdateTrafficLightRequest) ApplyTo(t *TrafficLight) {
+    if r.Address != nil {
+        t.Address = *r.Address
+    }
+    if r.LightType != nil {
+        t.LightType = *r.LightType
+    }
+    if r.InstallYear != nil {
+        t.InstallYear = *r.InstallYear
+    }
+    if r.Status != nil {
+        t.Status = *r.Status
+    }
 }
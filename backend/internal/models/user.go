@@ -10,6 +10,16 @@ type User struct {
     IsActive  bool      `json:"is_active" db:"is_active"`
     CreatedAt time.Time `json:"created_at" db:"created_at"`
     UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+    // Защита от перебора: неудачные входы подряд и блокировка до locked_until.
+    FailedLogins int        `json:"-" db:"failed_logins"`
+    LockedUntil  *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+
+    // Двухфакторная аутентификация: секрет TOTP наружу не отдаётся.
+    // TOTPLastStep — шаг последнего принятого кода, повторно он не принимается.
+    TOTPSecret   string `json:"-" db:"totp_secret"`
+    TOTPEnabled  bool   `json:"totp_enabled" db:"totp_enabled"`
+    TOTPLastStep int64  `json:"-" db:"totp_last_step"`
 }
 
 
@@ -29,6 +39,73 @@ type EditorLoginRequest struct {
 }
 
 type LoginResponse struct {
-    Token string `json:"token"`
-    User  User   `json:"user"`
+    Token       string   `json:"token"`
+    User        User     `json:"user"`
+    // Permissions — права роли пользователя, чтобы UI мог скрыть недоступное.
+    Permissions []string `json:"permissions"`
+}
+
+// MFAChallengeResponse — ответ логина, когда нужен второй фактор.
+// MFAToken живёт несколько минут и годится только для /api/auth/mfa/verify
+// (или, если EnrollmentRequired, только для подключения 2FA).
+type MFAChallengeResponse struct {
+    MFARequired        bool   `json:"mfa_required"`
+    EnrollmentRequired bool   `json:"enrollment_required,omitempty"`
+    MFAToken           string `json:"mfa_token"`
+}
+
+// MFAVerifyRequest — второй шаг логина: код из приложения или код восстановления.
+type MFAVerifyRequest struct {
+    MFAToken     string `json:"mfa_token" binding:"required"`
+    Code         string `json:"code"`
+    RecoveryCode string `json:"recovery_code"`
+}
+
+// MFACodeRequest — подтверждение действия с 2FA текущим кодом из приложения.
+type MFACodeRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+// MFAEnrollResponse — секрет для приложения-аутентификатора.
+type MFAEnrollResponse struct {
+    Secret     string `json:"secret"`
+    OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAConfirmResponse — коды восстановления показываются один раз;
+// Token выдаётся, если 2FA подключали по токену обязательной настройки.
+type MFAConfirmResponse struct {
+    RecoveryCodes []string `json:"recovery_codes"`
+    Token         string   `json:"token,omitempty"`
+    User          *User    `json:"user,omitempty"`
+}
+
+// MFAPolicy — обязательна ли 2FA для роли.
+type MFAPolicy struct {
+    Role     string `json:"role" binding:"required"`
+    Required bool   `json:"required"`
+}
+
+// ForgotPasswordRequest — запрос письма со ссылкой сброса пароля.
+type ForgotPasswordRequest struct {
+    Email string `json:"email" binding:"required,email"`
+}
+
+// SetPasswordRequest — новый пароль по ссылке из письма (сброс или приглашение).
+type SetPasswordRequest struct {
+    Token    string `json:"token" binding:"required"`
+    Password string `json:"password" binding:"required"`
+}
+
+// InviteUserRequest — приглашение нового сотрудника.
+type InviteUserRequest struct {
+    Email string `json:"email" binding:"required,email"`
+    Role  string `json:"role" binding:"required"`
+}
+
+// InviteUserResponse — приглашённый пользователь; EmailSent=false, если письмо не ушло
+// (приглашение можно отправить повторно тем же запросом).
+type InviteUserResponse struct {
+    User      User `json:"user"`
+    EmailSent bool `json:"email_sent"`
 }
@@ -8,6 +8,7 @@ type Vacancy struct {
 	Position   string     `json:"position" db:"position"`
 	Experience string     `json:"experience" db:"experience"`
 	Salary     string     `json:"salary" db:"salary"` // строка, чтобы гибко хранить "от… до…", "по договорённости" и т.п.
+	Version    int        `json:"version" db:"version"`
 	CreatedAt  *time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt  *time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
@@ -19,8 +20,23 @@ type CreateVacancyRequest struct {
 	Salary     string `json:"salary" binding:"required"`
 }
 
+// UpdateVacancyRequest — частичное обновление (PUT/PATCH, RFC 7396): nil-поля не меняются.
 type UpdateVacancyRequest struct {
 	Position   *string `json:"position"`
 	Experience *string `json:"experience"`
 	Salary     *string `json:"salary"`
+	Version    *int    `json:"version"` // альтернатива заголовку If-Match
+}
+
+// ApplyTo накладывает переданные поля на v (для проверки итогового состояния перед записью).
+func (r *UpdateVacancyRequest) ApplyTo(v *Vacancy) {
+	if r.Position != nil {
+		v.Position = *r.Position
+	}
+	if r.Experience != nil {
+		v.Experience = *r.Experience
+	}
+	if r.Salary != nil {
+		v.Salary = *r.Salary
+	}
 }
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook — подписка внешней системы на события: POST на URL с подписью HMAC-SHA256.
+type Webhook struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+	// Events — фильтры событий: тип (traffic_light.status_changed), маска news.* или * — все.
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	// Secret — ключ подписи; отдаётся только при создании подписки и смене ключа.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest — частичное изменение подписки: nil — поле не меняется.
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// ApplyTo переносит переданные поля в подписку.
+func (r *UpdateWebhookRequest) ApplyTo(w *Webhook) {
+	if r.URL != nil {
+		w.URL = *r.URL
+	}
+	if r.Events != nil {
+		w.Events = r.Events
+	}
+	if r.Description != nil {
+		w.Description = *r.Description
+	}
+	if r.Active != nil {
+		w.Active = *r.Active
+	}
+}
+
+// Статусы доставки вебхука.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery — отправка одного события одной подписке (запись журнала доставок).
+type WebhookDelivery struct {
+	ID        int64           `json:"id"`
+	WebhookID int             `json:"webhook_id"`
+	EventID   int64           `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    string          `json:"status"`
+	Attempts  int             `json:"attempts"`
+	// NextAttemptAt — когда будет следующая попытка (для pending).
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	// ReplayOf — доставка, которую повторили вручную.
+	ReplayOf   *int64     `json:"replay_of,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// WebhookJob — доставка, взятая в работу: что, куда и с каким ключом отправить.
+type WebhookJob struct {
+	DeliveryID int64
+	EventType  string
+	Payload    []byte
+	Attempts   int
+	URL        string
+	Secret     string
+}
+
+// WebhookAttempt — итог попытки доставки.
+type WebhookAttempt struct {
+	At     time.Time
+	Status string
+	// NextAttemptAt — для Status = pending: когда повторить.
+	NextAttemptAt time.Time
+	// ResponseStatus — HTTP-статус ответа; 0 — ответа не было.
+	ResponseStatus int
+	Error          string
+}
@@ -0,0 +1,55 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// FlowTTL — сколько живёт незавершённый вход (время на страницу входа провайдера).
+const FlowTTL = 10 * time.Minute
+
+var errInvalidFlow = errors.New("invalid or expired login flow")
+
+type signedFlow struct {
+	Flow
+	Expires int64 `json:"exp"`
+}
+
+// EncodeFlow сериализует flow в значение cookie, подписанное HMAC-SHA256.
+// Подпись не даёт подсунуть браузеру чужой state (login CSRF).
+func EncodeFlow(f *Flow, key []byte) (string, error) {
+	payload, err := json.Marshal(signedFlow{Flow: *f, Expires: time.Now().Add(FlowTTL).Unix()})
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + sign(body, key), nil
+}
+
+// DecodeFlow проверяет подпись и срок и возвращает flow.
+func DecodeFlow(value string, key []byte) (*Flow, error) {
+	body, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(body, key))) {
+		return nil, errInvalidFlow
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errInvalidFlow
+	}
+	var sf signedFlow
+	if err := json.Unmarshal(payload, &sf); err != nil || time.Now().Unix() > sf.Expires {
+		return nil, errInvalidFlow
+	}
+	return &sf.Flow, nil
+}
+
+func sign(body string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("oidc-flow:" + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,208 @@
+// Package oidc — вход сотрудников через OpenID Connect-провайдер
+// (authorization code flow с PKCE и nonce).
+//
+// Discovery выполняется лениво при первом входе и повторяется после неудачи,
+// поэтому недоступный провайдер не мешает запуску сервиса и входу по паролю.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoRole — ни одна группа пользователя не сопоставлена роли.
+var ErrNoRole = errors.New("oidc: no role for user groups")
+
+// GroupRole — группа провайдера и роль, которую она даёт.
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+// ParseGroupRoles разбирает "group=role,group2=role2". Порядок сохраняется:
+// он задаёт приоритет, если пользователь состоит в нескольких группах.
+func ParseGroupRoles(s string) ([]GroupRole, error) {
+	var res []GroupRole
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid group mapping %q, want group=role", pair)
+		}
+		res = append(res, GroupRole{Group: group, Role: role})
+	}
+	return res, nil
+}
+
+// Config — настройки клиента OIDC.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	GroupsClaim  string
+	GroupRoles   []GroupRole
+}
+
+// Identity — пользователь, подтверждённый провайдером.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Groups        []string
+}
+
+// Flow — незавершённый вход: хранится у браузера (в подписанной cookie)
+// между редиректом к провайдеру и возвратом на callback.
+type Flow struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// Provider — клиент одного OIDC-провайдера.
+type Provider struct {
+	cfg Config
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// New создаёт клиента; обращений к провайдеру до первого входа нет.
+func New(cfg Config) *Provider {
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &Provider{cfg: cfg}
+}
+
+// AuthURL начинает вход: возвращает адрес страницы входа провайдера
+// и параметры, которые нужно сохранить до callback.
+func (p *Provider) AuthURL(ctx context.Context) (string, *Flow, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	state, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+	flow := &Flow{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}
+	url := oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(flow.Verifier))
+	return url, flow, nil
+}
+
+// Exchange завершает вход: меняет code на токены (с PKCE verifier),
+// проверяет подпись, аудиторию и nonce ID-токена и достаёт из него пользователя.
+// Проверку state (совпадение с flow.State) делает вызывающий.
+func (p *Provider) Exchange(ctx context.Context, code string, flow *Flow) (*Identity, error) {
+	oauth, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+	rawID, ok := token.Extra("id_token").(string)
+	if !ok || rawID == "" {
+		return nil, errors.New("no id_token in token response")
+	}
+	idToken, err := verifier.Verify(ctx, rawID)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims map[string]json.RawMessage
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("parse id_token claims: %w", err)
+	}
+	id := &Identity{Issuer: idToken.Issuer, Subject: idToken.Subject}
+	_ = json.Unmarshal(claims["email"], &id.Email)
+	_ = json.Unmarshal(claims["email_verified"], &id.EmailVerified)
+	id.Groups = stringList(claims[p.cfg.GroupsClaim])
+	if id.Email == "" {
+		return nil, errors.New("id_token has no email claim")
+	}
+	return id, nil
+}
+
+// RoleFor — роль по группам пользователя; ErrNoRole, если ни одна группа не сопоставлена.
+func (p *Provider) RoleFor(groups []string) (string, error) {
+	for _, gr := range p.cfg.GroupRoles {
+		for _, g := range groups {
+			if g == gr.Group {
+				return gr.Role, nil
+			}
+		}
+	}
+	return "", ErrNoRole
+}
+
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+	provider, err := gooidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{gooidc.ScopeOpenID, "email", "profile"},
+	}
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth, p.verifier, nil
+}
+
+// stringList читает claim групп: массив строк или одна строка.
+func stringList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil && one != "" {
+		return []string{one}
+	}
+	return nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
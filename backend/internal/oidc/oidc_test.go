@@ -0,0 +1,360 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID     = "codd-backend"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "https://codd.example/api/auth/oidc/callback"
+)
+
+// grant — выданный страницей входа code: с ним связаны PKCE challenge и nonce запроса.
+type grant struct {
+	challenge string
+	nonce     string
+}
+
+// fakeIdP — OIDC-провайдер на httptest: discovery, JWKS и token endpoint.
+// Страницу входа заменяет authorize — он проверяет параметры запроса и выдаёт code.
+type fakeIdP struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+	// claims правит claims ID-токена перед подписью (для проверок отказа).
+	claims func(jwt.MapClaims)
+	// signWith — ключ подписи ID-токена вместо опубликованного в JWKS.
+	signWith *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key, grants: make(map[string]grant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *fakeIdP) provider() *Provider {
+	return New(Config{
+		Issuer:       idp.srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		GroupRoles:   []GroupRole{{Group: "codd-admins", Role: "admin"}, {Group: "codd-editors", Role: "editor"}},
+	})
+}
+
+func (idp *fakeIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                idp.srv.URL,
+		"authorization_endpoint":                idp.srv.URL + "/authorize",
+		"token_endpoint":                        idp.srv.URL + "/token",
+		"jwks_uri":                              idp.srv.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *fakeIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := idp.key.PublicKey
+	json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test-key",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	oauthError := func(code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "authorization_code" {
+		oauthError("invalid_request")
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+	}
+	if id != testClientID || secret != testClientSecret {
+		oauthError("invalid_client")
+		return
+	}
+	if r.Form.Get("redirect_uri") != testRedirectURL {
+		oauthError("invalid_grant")
+		return
+	}
+
+	idp.mu.Lock()
+	g, ok := idp.grants[r.Form.Get("code")]
+	delete(idp.grants, r.Form.Get("code")) // code одноразовый
+	idp.mu.Unlock()
+	if !ok || s256(r.Form.Get("code_verifier")) != g.challenge {
+		oauthError("invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.srv.URL,
+		"aud":            testClientID,
+		"sub":            "user-1",
+		"nonce":          g.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          "ivanov@smolensk.example",
+		"email_verified": true,
+		"groups":         []string{"staff", "codd-editors"},
+	}
+	if idp.claims != nil {
+		idp.claims(claims)
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "test-key"
+	key := idp.key
+	if idp.signWith != nil {
+		key = idp.signWith
+	}
+	idToken, err := tok.SignedString(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// authorize играет страницу входа: проверяет запрос авторизации и выдаёт code.
+func (idp *fakeIdP) authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.srv.URL+"/authorize" {
+		t.Fatalf("auth URL points to %s", got)
+	}
+	q := u.Query()
+	for param, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"code_challenge_method": "S256",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	if scopes := strings.Fields(q.Get("scope")); !slices.Contains(scopes, "openid") || !slices.Contains(scopes, "email") {
+		t.Errorf("scope = %q", q.Get("scope"))
+	}
+	for _, param := range []string{"state", "nonce", "code_challenge"} {
+		if q.Get(param) == "" {
+			t.Fatalf("auth URL has no %s", param)
+		}
+	}
+
+	code = "code-" + q.Get("state")[:8]
+	idp.mu.Lock()
+	idp.grants[code] = grant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	idp.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func TestLoginFlow(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+	cookieKey := []byte("jwt-secret")
+
+	authURL, flow, err := p.AuthURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _ := url.Parse(authURL)
+	if got := q.Query().Get("code_challenge"); got != s256(flow.Verifier) {
+		t.Errorf("code_challenge = %q, want S256 of the flow verifier", got)
+	}
+	if q.Query().Get("nonce") != flow.Nonce {
+		t.Error("auth URL nonce differs from the flow")
+	}
+
+	// Между редиректом и callback flow живёт в подписанной cookie.
+	cookie, err := EncodeFlow(flow, cookieKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := idp.authorize(t, authURL)
+	restored, err := DecodeFlow(cookie, cookieKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *restored != *flow {
+		t.Fatalf("flow after cookie = %+v, want %+v", restored, flow)
+	}
+	if state != restored.State {
+		t.Fatalf("callback state %q does not match the flow", state)
+	}
+
+	id, err := p.Exchange(ctx, code, restored)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{Issuer: idp.srv.URL, Subject: "user-1", Email: "ivanov@smolensk.example", EmailVerified: true}
+	if id.Issuer != want.Issuer || id.Subject != want.Subject || id.Email != want.Email || !id.EmailVerified {
+		t.Errorf("identity = %+v, want %+v", id, want)
+	}
+	if role, err := p.RoleFor(id.Groups); err != nil || role != "editor" {
+		t.Errorf("RoleFor(%v) = %q, %v; want editor", id.Groups, role, err)
+	}
+
+	// Code одноразовый: повторный обмен отклоняется.
+	if _, err := p.Exchange(ctx, code, restored); err == nil {
+		t.Error("code was accepted twice")
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		claims   func(jwt.MapClaims)
+		signWith *rsa.PrivateKey
+		flow     func(*Flow)
+		reason   string // фрагмент текста ошибки
+	}{
+		{name: "nonce mismatch", claims: func(c jwt.MapClaims) { c["nonce"] = "replayed-nonce" }, reason: "nonce mismatch"},
+		{name: "no nonce", claims: func(c jwt.MapClaims) { delete(c, "nonce") }, reason: "nonce mismatch"},
+		{name: "wrong issuer", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, reason: "different provider"},
+		{name: "wrong audience", claims: func(c jwt.MapClaims) { c["aud"] = "another-client" }, reason: "audience"},
+		{name: "expired", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, reason: "expired"},
+		{name: "no email", claims: func(c jwt.MapClaims) { delete(c, "email") }, reason: "no email"},
+		{name: "foreign signature", signWith: otherKey, reason: "signature"},
+		{name: "wrong PKCE verifier", flow: func(f *Flow) { f.Verifier = strings.Repeat("x", 43) }, reason: "invalid_grant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newFakeIdP(t)
+			idp.claims, idp.signWith = tt.claims, tt.signWith
+			p := idp.provider()
+			ctx := context.Background()
+
+			authURL, flow, err := p.AuthURL(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, _ := idp.authorize(t, authURL)
+			if tt.flow != nil {
+				tt.flow(flow)
+			}
+			id, err := p.Exchange(ctx, code, flow)
+			if err == nil {
+				t.Fatalf("Exchange accepted the token: %+v", id)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestFlowCookie(t *testing.T) {
+	key := []byte("jwt-secret")
+	flow := &Flow{State: "state", Nonce: "nonce", Verifier: "verifier"}
+	value, err := EncodeFlow(flow, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, sig, _ := strings.Cut(value, ".")
+
+	expiredPayload, _ := json.Marshal(signedFlow{Flow: *flow, Expires: time.Now().Add(-time.Second).Unix()})
+	expired := base64.RawURLEncoding.EncodeToString(expiredPayload)
+	forgedPayload, _ := json.Marshal(signedFlow{Flow: Flow{State: "attacker"}, Expires: time.Now().Add(time.Hour).Unix()})
+	forged := base64.RawURLEncoding.EncodeToString(forgedPayload)
+
+	tests := []struct {
+		name  string
+		value string
+		key   []byte
+	}{
+		{"other key", value, []byte("other-secret")},
+		{"forged body", forged + "." + sig, key},
+		{"expired", expired + "." + sign(expired, key), key},
+		{"no signature", body, key},
+		{"empty", "", key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if f, err := DecodeFlow(tt.value, tt.key); err == nil {
+				t.Errorf("DecodeFlow accepted %+v", f)
+			}
+		})
+	}
+}
+
+func TestRoleFor(t *testing.T) {
+	p := New(Config{GroupRoles: []GroupRole{{"codd-admins", "admin"}, {"codd-editors", "editor"}}})
+	tests := []struct {
+		groups []string
+		role   string
+	}{
+		{[]string{"codd-editors", "codd-admins"}, "admin"}, // порядок сопоставления задаёт приоритет
+		{[]string{"codd-editors"}, "editor"},
+		{[]string{"staff"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		role, err := p.RoleFor(tt.groups)
+		if role != tt.role || (tt.role == "") != (err == ErrNoRole) {
+			t.Errorf("RoleFor(%v) = %q, %v; want %q", tt.groups, role, err, tt.role)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Backoff — экспоненциальная задержка по ключу: после n неудач подряд следующая
+// попытка разрешена не раньше чем через base * 2^(n-1), но не дольше max.
+type Backoff struct {
+	base time.Duration
+	max  time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*backoffEntry
+	lastSweep time.Time
+}
+
+type backoffEntry struct {
+	failures int
+	until    time.Time
+}
+
+// NewBackoff создаёт Backoff; base <= 0 отключает задержку.
+func NewBackoff(base, max time.Duration) *Backoff {
+	return &Backoff{
+		base:      base,
+		max:       max,
+		entries:   make(map[string]*backoffEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Wait возвращает, сколько ещё ждать до следующей попытки для key (0 — можно сейчас).
+func (b *Backoff) Wait(key string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[key]
+	if !ok {
+		return 0
+	}
+	if d := time.Until(e.until); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Fail учитывает неудачу для key и продлевает задержку.
+func (b *Backoff) Fail(key string) {
+	if b.base <= 0 {
+		return
+	}
+
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+	e, ok := b.entries[key]
+	if !ok {
+		e = &backoffEntry{}
+		b.entries[key] = e
+	}
+	e.failures++
+	e.until = now.Add(b.delay(e.failures))
+}
+
+// Reset забывает неудачи для key (после успешного входа).
+func (b *Backoff) Reset(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, key)
+}
+
+func (b *Backoff) delay(failures int) time.Duration {
+	d := b.base
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if d >= b.max {
+			return b.max
+		}
+	}
+	return d
+}
+
+func (b *Backoff) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+	b.lastSweep = now
+	for key, e := range b.entries {
+		if now.Sub(e.until) > idleTTL {
+			delete(b.entries, key)
+		}
+	}
+}
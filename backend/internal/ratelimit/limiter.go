@@ -0,0 +1,82 @@
+// Package ratelimit — ограничение частоты запросов в памяти процесса:
+// token bucket по ключу (обычно IP) для публичного API и экспоненциальная
+// задержка после неудачных входов по IP и по учётной записи.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleTTL — через сколько простоя ключ забывается, чтобы карта не росла бесконечно
+// (только если его bucket уже полон — иначе медленные лимиты сбрасывались бы раньше времени).
+const idleTTL = 10 * time.Minute
+
+// Limiter — token bucket на каждый ключ: rps запросов в секунду со всплеском до burst.
+type Limiter struct {
+	rps   rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// NewLimiter создаёт Limiter; rps <= 0 отключает ограничение.
+func NewLimiter(rps float64, burst int) *Limiter {
+	return &Limiter{
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow списывает токен для key. Если токенов нет, возвращает false
+// и время, через которое стоит повторить запрос.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.rps <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.buckets[key] = b
+	}
+	b.seen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.seen) > idleTTL && b.limiter.TokensAt(now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
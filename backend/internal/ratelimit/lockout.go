@@ -0,0 +1,27 @@
+package ratelimit
+
+import "time"
+
+// LockoutPolicy — когда и на сколько блокировать учётную запись в БД.
+// После MaxFailures неудач подряд вход блокируется на Duration, каждая следующая
+// неудача удваивает срок (до Max). Счётчик сбрасывается успешным входом или админом.
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+	Max         time.Duration
+}
+
+// LockFor возвращает срок блокировки после failures неудач подряд (0 — не блокировать).
+func (p LockoutPolicy) LockFor(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+	d := p.Duration
+	for i := p.MaxFailures; i < failures; i++ {
+		d *= 2
+		if d >= p.Max {
+			return p.Max
+		}
+	}
+	return d
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	// 50 rps — токен возвращается каждые 20ms.
+	l := NewLimiter(50, 3)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within burst rejected", i+1)
+		}
+	}
+	ok, retry := l.Allow("10.0.0.1")
+	if ok {
+		t.Fatal("request over burst allowed")
+	}
+	if retry <= 0 || retry > 20*time.Millisecond {
+		t.Errorf("retry = %v, want (0, 20ms]", retry)
+	}
+
+	// Отклонённый запрос не должен съедать токен: после паузы проходит ровно один.
+	time.Sleep(retry + 5*time.Millisecond)
+	if ok, _ := l.Allow("10.0.0.1"); !ok {
+		t.Fatal("request after refill rejected")
+	}
+	if ok, _ := l.Allow("10.0.0.1"); ok {
+		t.Fatal("second request after single refill allowed")
+	}
+
+	// Ключи независимы.
+	if ok, _ := l.Allow("10.0.0.2"); !ok {
+		t.Fatal("other key rejected")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := NewLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("k"); !ok {
+			t.Fatalf("request %d rejected with rps=0", i+1)
+		}
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := NewBackoff(time.Second, 10*time.Second)
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := b.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffWaitAndReset(t *testing.T) {
+	b := NewBackoff(time.Minute, time.Hour)
+	if d := b.Wait("user"); d != 0 {
+		t.Fatalf("Wait before failures = %v, want 0", d)
+	}
+
+	b.Fail("user")
+	b.Fail("user")
+	if d := b.Wait("user"); d <= time.Minute || d > 2*time.Minute {
+		t.Errorf("Wait after 2 failures = %v, want (1m, 2m]", d)
+	}
+	if d := b.Wait("other"); d != 0 {
+		t.Errorf("Wait for other key = %v, want 0", d)
+	}
+
+	b.Reset("user")
+	if d := b.Wait("user"); d != 0 {
+		t.Errorf("Wait after Reset = %v, want 0", d)
+	}
+
+	off := NewBackoff(0, time.Hour)
+	off.Fail("user")
+	if d := off.Wait("user"); d != 0 {
+		t.Errorf("Wait with base=0 = %v, want 0", d)
+	}
+}
+
+func TestLockoutPolicy(t *testing.T) {
+	p := LockoutPolicy{MaxFailures: 5, Duration: 15 * time.Minute, Max: time.Hour}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, 15 * time.Minute},
+		{6, 30 * time.Minute},
+		{7, time.Hour},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := p.LockFor(tt.failures); got != tt.want {
+			t.Errorf("LockFor(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := (LockoutPolicy{Duration: time.Minute, Max: time.Hour}).LockFor(100); got != 0 {
+		t.Errorf("LockFor with MaxFailures=0 = %v, want 0", got)
+	}
+}
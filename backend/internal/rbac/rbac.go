@@ -0,0 +1,131 @@
+// Package rbac — матрица прав "роль → права".
+// Права роли читаются из БД и кэшируются на короткое время, поэтому изменения
+// матрицы действуют без перевыпуска JWT: в токене лежит только роль.
+package rbac
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Права, которые требуют маршруты.
+const (
+	DashboardRead      = "dashboard:read"
+	NewsWrite          = "news:write"
+	ServicesWrite      = "services:write"
+	TeamWrite          = "team:write"
+	ProjectsWrite      = "projects:write"
+	VacanciesWrite     = "vacancies:write"
+	FinesWrite         = "fines:write"
+	EvacuationsWrite   = "evacuations:write"
+	TrafficLightsWrite = "traffic_lights:write"
+	UsersManage        = "users:manage"
+	SecurityManage     = "security:manage"
+	RolesManage        = "roles:manage"
+	WebhooksManage     = "webhooks:manage"
+	FeedbackModerate   = "feedback:moderate"
+	OrdersManage       = "orders:manage"
+)
+
+// AdminRole — встроенная роль, которую нельзя удалить или лишить roles:manage,
+// иначе управлять матрицей станет некому.
+const AdminRole = "admin"
+
+// Loader читает всю матрицу: роль → её права (роль без прав — пустой срез).
+type Loader func(ctx context.Context) (map[string][]string, error)
+
+// Cache — матрица прав в памяти с обновлением раз в ttl.
+type Cache struct {
+	load Loader
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	matrix map[string]map[string]bool
+	loaded time.Time
+}
+
+// NewCache создаёт кэш матрицы прав.
+func NewCache(load Loader, ttl time.Duration) *Cache {
+	return &Cache{load: load, ttl: ttl}
+}
+
+// Has сообщает, есть ли у роли право.
+func (c *Cache) Has(ctx context.Context, role, permission string) (bool, error) {
+	m, err := c.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	return m[role][permission], nil
+}
+
+// RoleExists сообщает, есть ли такая роль.
+func (c *Cache) RoleExists(ctx context.Context, role string) (bool, error) {
+	m, err := c.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := m[role]
+	return ok, nil
+}
+
+// Permissions — права роли.
+func (c *Cache) Permissions(ctx context.Context, role string) ([]string, error) {
+	m, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms := make([]string, 0, len(m[role]))
+	for p := range m[role] {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms, nil
+}
+
+// Covers сообщает, есть ли у роли role все права роли other: назначить other
+// может только тот, кто не получит этим больше прав, чем имеет сам.
+func (c *Cache) Covers(ctx context.Context, role, other string) (bool, error) {
+	m, err := c.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	for p := range m[other] {
+		if !m[role][p] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Invalidate сбрасывает кэш после изменения матрицы.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.matrix = nil
+}
+
+func (c *Cache) get(ctx context.Context) (map[string]map[string]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.matrix != nil && time.Since(c.loaded) < c.ttl {
+		return c.matrix, nil
+	}
+
+	raw, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matrix := make(map[string]map[string]bool, len(raw))
+	for role, perms := range raw {
+		set := make(map[string]bool, len(perms))
+		for _, p := range perms {
+			set[p] = true
+		}
+		matrix[role] = set
+	}
+	c.matrix, c.loaded = matrix, time.Now()
+	return matrix, nil
+}
@@ -0,0 +1,42 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCovers(t *testing.T) {
+	c := NewCache(func(context.Context) (map[string][]string, error) {
+		return map[string][]string{
+			AdminRole:   {UsersManage, RolesManage, NewsWrite},
+			"hr":        {UsersManage, NewsWrite},
+			"editor":    {NewsWrite},
+			"moderator": {FeedbackModerate},
+			"newcomer":  {},
+		}, nil
+	}, time.Minute)
+
+	tests := []struct {
+		role, other string
+		want        bool
+	}{
+		{"hr", "editor", true},
+		{"hr", "hr", true},
+		{"hr", "newcomer", true},
+		{"hr", AdminRole, false},
+		{"hr", "moderator", false},
+		{AdminRole, "hr", true},
+		{"editor", "hr", false},
+		{"hr", "missing", true},
+	}
+	for _, tt := range tests {
+		got, err := c.Covers(context.Background(), tt.role, tt.other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Covers(%s, %s) = %v, want %v", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
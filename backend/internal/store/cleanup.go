@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/config"
+	"backend/internal/logging"
+)
+
+// PurgeResult — сколько записей удалила PurgeExpired.
+type PurgeResult struct {
+	Sessions          int64
+	UserTokens        int64
+	WebhookDeliveries int64
+}
+
+// PurgeExpired удаляет сессии, закончившиеся (истёкшие или отозванные) раньше sessionsBefore,
+// использованные или истёкшие раньше tokensBefore токены из писем и завершённые раньше
+// deliveriesBefore доставки вебхуков (журнал).
+func (s *Store) PurgeExpired(ctx context.Context, sessionsBefore, tokensBefore, deliveriesBefore time.Time) (PurgeResult, error) {
+	ctx, span := s.startOp(ctx, "PurgeExpired")
+	defer span.End()
+
+	var res PurgeResult
+	r, err := s.db.ExecContext(ctx,
+		`DELETE FROM sessions WHERE expires_at < $1 OR revoked_at < $1`, sessionsBefore,
+	)
+	if err != nil {
+		logError(ctx, "PurgeExpired sessions", err)
+		return res, err
+	}
+	res.Sessions, _ = r.RowsAffected()
+
+	r, err = s.db.ExecContext(ctx,
+		`DELETE FROM user_tokens WHERE expires_at < $1 OR used_at < $1`, tokensBefore,
+	)
+	if err != nil {
+		logError(ctx, "PurgeExpired user_tokens", err)
+		return res, err
+	}
+	res.UserTokens, _ = r.RowsAffected()
+
+	r, err = s.db.ExecContext(ctx,
+		`DELETE FROM webhook_deliveries WHERE finished_at < $1`, deliveriesBefore,
+	)
+	if err != nil {
+		logError(ctx, "PurgeExpired webhook_deliveries", err)
+		return res, err
+	}
+	res.WebhookDeliveries, _ = r.RowsAffected()
+	return res, nil
+}
+
+// RunCleanup каждые interval удаляет записи старше сроков хранения, пока не отменён ctx.
+// Ошибки логируются; удалится в следующий раз.
+func (s *Store) RunCleanup(ctx context.Context, retention config.StorageConfig, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			now := time.Now()
+			res, err := s.PurgeExpired(ctx, now.Add(-retention.SessionRetention), now.Add(-retention.TokenRetention),
+				now.Add(-retention.WebhookDeliveryRetention))
+			if err != nil {
+				logging.FromContext(ctx).Error("Cleanup failed", "err", err)
+				continue
+			}
+			if res.Sessions > 0 || res.UserTokens > 0 || res.WebhookDeliveries > 0 {
+				logging.FromContext(ctx).Info("Expired records purged", "sessions", res.Sessions, "user_tokens", res.UserTokens,
+					"webhook_deliveries", res.WebhookDeliveries)
+			}
+		}
+	}
+}
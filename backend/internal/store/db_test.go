@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"backend/migrations"
+)
+
+// testStore — Store на базе из TEST_DATABASE_URL (строка подключения lib/pq) с применёнными
+// миграциями. Без переменной тест пропускается. База должна быть отдельной, тестовой:
+// тесты пишут в неё данные.
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s := &Store{db: db}
+	if _, err := s.Migrate(context.Background(), migrations.FS); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+
+	"backend/internal/events"
+	"backend/internal/logging"
+)
+
+// eventsChannel — канал NOTIFY для событий (режим EVENTS_PG_NOTIFY).
+const eventsChannel = "smolathon_events"
+
+// Publisher — получатель событий об изменениях данных (events.Bus).
+type Publisher interface {
+	Publish(events.Event)
+}
+
+// SetPublisher подключает шину: после каждой успешной записи контента store отправляет событие.
+// В режиме pg_notify событие уходит в NOTIFY, а в шину его передаёт ListenEvents — на всех репликах.
+func (s *Store) SetPublisher(p Publisher) {
+	s.events = p
+}
+
+// emit отправляет событие о записи, которая уже выполнена, и ставит его в очередь вебхуков.
+// Ошибки только логируются: событие — уведомление, и откатывать из-за него запись не нужно.
+// Отмена запроса клиентом событие не отменяет. Вебхуки ставятся в очередь и без шины.
+func (s *Store) emit(ctx context.Context, typ string, data interface{}) {
+	ctx, span := s.startOp(context.WithoutCancel(ctx), "emit")
+	defer span.End()
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		logError(ctx, "emit "+typ, err)
+		return
+	}
+	e := events.Event{Type: typ, Data: raw, Time: time.Now()}
+	if err := s.db.QueryRowContext(ctx, `SELECT nextval('events_id_seq')`).Scan(&e.ID); err != nil {
+		logError(ctx, "emit "+typ, err)
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		logError(ctx, "emit "+typ, err)
+		return
+	}
+	s.enqueueWebhooks(ctx, e, payload)
+
+	if s.events == nil {
+		return
+	}
+	if !s.pgNotify {
+		s.events.Publish(e)
+		return
+	}
+	if _, err := s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, string(payload)); err != nil {
+		// Клиенты других реплик событие не получат, но свои — получат.
+		logError(ctx, "emit "+typ, err)
+		s.events.Publish(e)
+	}
+}
+
+// ListenEvents — режим pg_notify: получает события всех реплик через LISTEN и передаёт их в шину,
+// пока не отменён ctx. ready вызывается, когда подписка на канал оформлена.
+// Обрыв соединения восстанавливается сам; события, отправленные за время обрыва,
+// до клиентов этой реплики не дойдут.
+func (s *Store) ListenEvents(ctx context.Context, ready func()) error {
+	log := logging.FromContext(ctx)
+	l := pq.NewListener(s.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventDisconnected:
+			log.Warn("Events listener disconnected", "err", err)
+		case pq.ListenerEventReconnected:
+			log.Info("Events listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Warn("Events listener connection attempt failed", "err", err)
+		}
+	})
+	defer l.Close()
+
+	// Listen ждёт соединения с БД; Close из другой горутины прерывает ожидание при остановке.
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+	if err := l.Listen(eventsChannel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	ready()
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-l.Notify:
+			if !ok {
+				return nil
+			}
+			if n == nil {
+				// Соединение восстановлено: уведомления за время обрыва не придут.
+				continue
+			}
+			var e events.Event
+			if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+				log.Error("Invalid event notification", "err", err)
+				continue
+			}
+			s.events.Publish(e)
+		case <-ping.C:
+			// Пинг замечает обрыв соединения, если в канале давно тихо.
+			_ = l.Ping()
+		}
+	}
+}
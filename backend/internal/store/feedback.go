@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"backend/internal/apperr"
+	"backend/internal/events"
+	"backend/internal/models"
+)
+
+// ErrFeedbackNotFound — нет обращения с таким id или номером.
+var ErrFeedbackNotFound = apperr.NotFound("Feedback not found")
+
+const feedbackColumns = `id, ticket, category, location, text, contact, status, reject_reason,
+	moderated_by, moderated_at, response, responded_by, responded_at, created_at, updated_at`
+
+// scanFeedback читает feedbackColumns и следом — колонки extra, если запрос возвращает что-то ещё.
+func scanFeedback(row interface{ Scan(...any) error }, f *models.Feedback, extra ...any) error {
+	return row.Scan(append([]any{&f.ID, &f.Ticket, &f.Category, &f.Location, &f.Text, &f.Contact, &f.Status, &f.RejectReason,
+		&f.ModeratedBy, &f.ModeratedAt, &f.Response, &f.RespondedBy, &f.RespondedAt, &f.CreatedAt, &f.UpdatedAt}, extra...)...)
+}
+
+// CreateFeedback сохраняет новое обращение (статус new).
+func (s *Store) CreateFeedback(ctx context.Context, f *models.Feedback) error {
+	ctx, span := s.startOp(ctx, "CreateFeedback")
+	defer span.End()
+
+	now := time.Now()
+	f.Status, f.CreatedAt, f.UpdatedAt = models.FeedbackNew, now, now
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO feedback (ticket, category, location, text, contact, status, created_at, updated_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $7) RETURNING id`,
+		f.Ticket, f.Category, f.Location, f.Text, f.Contact, f.Status, now,
+	).Scan(&f.ID)
+	if err != nil {
+		logError(ctx, "CreateFeedback", err)
+	}
+	return err
+}
+
+// GetFeedbackByID — обращение для модератора.
+func (s *Store) GetFeedbackByID(ctx context.Context, id int) (*models.Feedback, error) {
+	ctx, span := s.startOp(ctx, "GetFeedbackByID")
+	defer span.End()
+
+	var f models.Feedback
+	err := scanFeedback(s.db.QueryRowContext(ctx, `SELECT `+feedbackColumns+` FROM feedback WHERE id = $1`, id), &f)
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		logError(ctx, "GetFeedbackByID", err)
+		return nil, err
+	}
+	return &f, nil
+}
+
+// GetFeedbackByTicket — обращение по номеру, который получил автор.
+func (s *Store) GetFeedbackByTicket(ctx context.Context, ticket string) (*models.Feedback, error) {
+	ctx, span := s.startOp(ctx, "GetFeedbackByTicket")
+	defer span.End()
+
+	var f models.Feedback
+	err := scanFeedback(s.db.QueryRowContext(ctx, `SELECT `+feedbackColumns+` FROM feedback WHERE ticket = $1`, ticket), &f)
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		logError(ctx, "GetFeedbackByTicket", err)
+		return nil, err
+	}
+	return &f, nil
+}
+
+// GetFeedback — обращения со статусом status (пустой — все), новые первыми; offset — сколько пропустить.
+// Очередь модерации — status = new, публичная лента — approved.
+func (s *Store) GetFeedback(ctx context.Context, status string, limit, offset int) ([]models.Feedback, error) {
+	ctx, span := s.startOp(ctx, "GetFeedback")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+feedbackColumns+`
+		FROM feedback
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		logError(ctx, "GetFeedback query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.Feedback{}
+	for rows.Next() {
+		var f models.Feedback
+		if err := scanFeedback(rows, &f); err != nil {
+			logError(ctx, "GetFeedback scan", err)
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, rows.Err()
+}
+
+// ModerateFeedback одобряет или отклоняет обращение (решение можно пересмотреть:
+// одобренное — снять с публикации, отклонённое — одобрить). reason сохраняется для отклонённых.
+func (s *Store) ModerateFeedback(ctx context.Context, id int, status, reason string, by int) (*models.Feedback, error) {
+	ctx, span := s.startOp(ctx, "ModerateFeedback")
+	defer span.End()
+
+	if status != models.FeedbackRejected {
+		reason = ""
+	}
+	var f models.Feedback
+	var was string
+	err := scanFeedback(s.db.QueryRowContext(ctx, `
+		WITH old AS (SELECT id AS old_id, status AS old_status FROM feedback WHERE id = $1 FOR UPDATE)
+		UPDATE feedback
+		SET status = $2, reject_reason = $3, moderated_by = $4, moderated_at = $5, updated_at = $5
+		FROM old
+		WHERE id = old_id
+		RETURNING `+feedbackColumns+`, old_status`,
+		id, status, reason, by, time.Now(),
+	), &f, &was)
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		logError(ctx, "ModerateFeedback", err)
+		return nil, err
+	}
+	// В публичный поток событий попадает только то, что видно в публичной ленте.
+	switch {
+	case f.Status == models.FeedbackApproved:
+		s.emit(ctx, events.FeedbackPublished, events.Ref{ID: f.ID})
+	case was == models.FeedbackApproved:
+		s.emit(ctx, events.FeedbackWithdrawn, events.Ref{ID: f.ID})
+	}
+	return &f, nil
+}
+
+// RespondFeedback сохраняет ответ ЦОДД; у одобренного обращения он сразу публикуется.
+func (s *Store) RespondFeedback(ctx context.Context, id int, response string, by int) (*models.Feedback, error) {
+	ctx, span := s.startOp(ctx, "RespondFeedback")
+	defer span.End()
+
+	var f models.Feedback
+	err := scanFeedback(s.db.QueryRowContext(ctx, `
+		UPDATE feedback
+		SET response = $2, responded_by = $3, responded_at = $4, updated_at = $4
+		WHERE id = $1
+		RETURNING `+feedbackColumns,
+		id, response, by, time.Now(),
+	), &f)
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		logError(ctx, "RespondFeedback", err)
+		return nil, err
+	}
+	if f.Status == models.FeedbackApproved {
+		s.emit(ctx, events.FeedbackPublished, events.Ref{ID: f.ID})
+	}
+	return &f, nil
+}
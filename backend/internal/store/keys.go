@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"backend/internal/models"
+)
+
+// signingKeysLock — ключ advisory-блокировки ротации, чтобы реплики не создали ключи одновременно.
+const signingKeysLock = 7_100_038
+
+// GetSigningKeys — ключи, которые ещё годятся для проверки: действующие
+// и заменённые не раньше retiredAfter.
+func (s *Store) GetSigningKeys(ctx context.Context, retiredAfter time.Time) ([]models.SigningKey, error) {
+	ctx, span := s.startOp(ctx, "GetSigningKeys")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kid, algorithm, private_key, not_before, retired_at, created_at
+		FROM signing_keys
+		WHERE retired_at IS NULL OR retired_at > $1
+		ORDER BY not_before DESC
+	`, retiredAfter)
+	if err != nil {
+		logError(ctx, "GetSigningKeys query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.SigningKey
+	for rows.Next() {
+		var k models.SigningKey
+		if err := rows.Scan(&k.KID, &k.Algorithm, &k.PrivateKey, &k.NotBefore, &k.RetiredAt, &k.CreatedAt); err != nil {
+			logError(ctx, "GetSigningKeys scan", err)
+			return nil, err
+		}
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+// RotateSigningKey добавляет новый ключ, если ротация ещё нужна: нет ключа того же алгоритма
+// с not_before позже dueBefore (его могла только что создать другая реплика).
+// Прежние ключи получают retired_at = key.NotBefore, ключи, заменённые раньше purgeBefore, удаляются.
+// Возвращает false, если ключ не понадобился.
+func (s *Store) RotateSigningKey(ctx context.Context, key *models.SigningKey, dueBefore, purgeBefore time.Time) (bool, error) {
+	ctx, span := s.startOp(ctx, "RotateSigningKey")
+	defer span.End()
+
+	added := false
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeysLock); err != nil {
+			return err
+		}
+
+		var fresh bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM signing_keys WHERE not_before > $1 AND algorithm = $2)`,
+			dueBefore, key.Algorithm,
+		).Scan(&fresh); err != nil {
+			return err
+		}
+		if fresh {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE signing_keys SET retired_at = $1 WHERE retired_at IS NULL`, key.NotBefore,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM signing_keys WHERE retired_at < $1`, purgeBefore,
+		); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO signing_keys (kid, algorithm, private_key, not_before)
+             VALUES ($1, $2, $3, $4) RETURNING created_at`,
+			key.KID, key.Algorithm, key.PrivateKey, key.NotBefore,
+		).Scan(&key.CreatedAt); err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	if err != nil {
+		logError(ctx, "RotateSigningKey", err)
+		return false, err
+	}
+	return added, nil
+}
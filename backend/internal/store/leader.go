@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"backend/internal/logging"
+)
+
+// TelegramBotLock — ключ advisory-блокировки Telegram-бота: getUpdates должна вызывать одна реплика.
+const TelegramBotLock = 7_100_047
+
+// RunExclusive выполняет run только на одной реплике — той, что держит advisory-блокировку lockID.
+// Остальные раз в retry пытаются её взять и подхватывают работу, если держатель остановился
+// или потерял соединение с БД (блокировка сессии снимается вместе с ним). Возвращается, когда
+// отменён ctx; run получает контекст, который отменяется и при потере блокировки.
+func (s *Store) RunExclusive(ctx context.Context, name string, lockID int64, retry time.Duration, run func(context.Context)) {
+	log := logging.FromContext(ctx).With("job", name)
+	t := time.NewTicker(retry)
+	defer t.Stop()
+	for {
+		if err := s.runLocked(ctx, log, lockID, retry, run); err != nil && ctx.Err() == nil {
+			log.Warn("Exclusive job lock lost or unavailable", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// runLocked берёт блокировку без ожидания и, если получилось, выполняет run, пока жива сессия.
+func (s *Store) runLocked(ctx context.Context, log *slog.Logger, lockID int64, check time.Duration, run func(context.Context)) error {
+	// Блокировка сессии: держится, пока открыто это соединение.
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lockID).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID)
+	log.Info("Exclusive job started on this replica")
+	defer log.Info("Exclusive job stopped on this replica")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(runCtx)
+	}()
+
+	t := time.NewTicker(check)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-t.C:
+			// Без соединения блокировки уже нет, и её может взять другая реплика.
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				cancel()
+				<-done
+				return err
+			}
+		}
+	}
+}
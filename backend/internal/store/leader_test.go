@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunExclusiveFailover(t *testing.T) {
+	first, second := testStore(t), testStore(t) // разные пулы соединений — как две реплики
+	const lockID = TelegramBotLock + 1000       // не мешать боту, если он запущен на той же базе
+	const retry = 50 * time.Millisecond
+
+	started := make(chan string, 2)
+	job := func(replica string) func(context.Context) {
+		return func(ctx context.Context) {
+			started <- replica
+			<-ctx.Done()
+		}
+	}
+
+	ctxFirst, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	go first.RunExclusive(ctxFirst, "test", lockID, retry, job("first"))
+	if got := <-started; got != "first" {
+		t.Fatalf("started on %s, want first", got)
+	}
+
+	ctxSecond, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.RunExclusive(ctxSecond, "test", lockID, retry, job("second"))
+	select {
+	case got := <-started:
+		t.Fatalf("job started on %s while the first replica holds the lock", got)
+	case <-time.After(10 * retry):
+	}
+
+	// Первая реплика остановилась — вторая подхватывает работу.
+	stopFirst()
+	select {
+	case got := <-started:
+		if got != "second" {
+			t.Errorf("started on %s, want second", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second replica did not take over")
+	}
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"backend/internal/models"
+)
+
+// SetTOTPSecret сохраняет новый (ещё не подтверждённый) секрет TOTP.
+// При уже включённой 2FA возвращает ErrMFAAlreadyEnabled: сначала её нужно выключить.
+func (s *Store) SetTOTPSecret(ctx context.Context, userID int, secret string) error {
+	ctx, span := s.startOp(ctx, "SetTOTPSecret")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET totp_secret = $1, totp_last_step = 0, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND NOT totp_enabled`,
+		secret, userID,
+	)
+	if err != nil {
+		logError(ctx, "SetTOTPSecret", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableTOTP включает 2FA и заменяет коды восстановления.
+func (s *Store) EnableTOTP(ctx context.Context, userID int, codeHashes []string) error {
+	ctx, span := s.startOp(ctx, "EnableTOTP")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET totp_enabled = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, userID,
+		); err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+	if err != nil {
+		logError(ctx, "EnableTOTP", err)
+	}
+	return err
+}
+
+// DisableTOTP выключает 2FA, удаляя секрет и коды восстановления.
+func (s *Store) DisableTOTP(ctx context.Context, userID int) error {
+	ctx, span := s.startOp(ctx, "DisableTOTP")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET totp_enabled = false, totp_secret = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, userID,
+		); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+		return err
+	})
+	if err != nil {
+		logError(ctx, "DisableTOTP", err)
+	}
+	return err
+}
+
+// ReplaceRecoveryCodes выдаёт новый набор кодов восстановления взамен старого.
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	ctx, span := s.startOp(ctx, "ReplaceRecoveryCodes")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+	if err != nil {
+		logError(ctx, "ReplaceRecoveryCodes", err)
+	}
+	return err
+}
+
+// UseRecoveryCode гасит неиспользованный код восстановления; false — такого кода нет.
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	ctx, span := s.startOp(ctx, "UseRecoveryCode")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+         WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash,
+	)
+	if err != nil {
+		logError(ctx, "UseRecoveryCode", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// UseTOTPStep запоминает шаг принятого кода TOTP; false — этот или более поздний шаг
+// уже использован (повтор кода, в том числе параллельным запросом).
+func (s *Store) UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	ctx, span := s.startOp(ctx, "UseTOTPStep")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2`,
+		userID, step,
+	)
+	if err != nil {
+		logError(ctx, "UseTOTPStep", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetMFAPolicies — политика 2FA по всем ролям.
+func (s *Store) GetMFAPolicies(ctx context.Context) ([]models.MFAPolicy, error) {
+	ctx, span := s.startOp(ctx, "GetMFAPolicies")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT role, required FROM mfa_policy ORDER BY role`)
+	if err != nil {
+		logError(ctx, "GetMFAPolicies query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.MFAPolicy
+	for rows.Next() {
+		var p models.MFAPolicy
+		if err := rows.Scan(&p.Role, &p.Required); err != nil {
+			logError(ctx, "GetMFAPolicies scan", err)
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// SetMFAPolicy включает или выключает обязательную 2FA для роли.
+func (s *Store) SetMFAPolicy(ctx context.Context, p *models.MFAPolicy) error {
+	ctx, span := s.startOp(ctx, "SetMFAPolicy")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO mfa_policy (role, required, updated_at) VALUES ($1, $2, CURRENT_TIMESTAMP)
+         ON CONFLICT (role) DO UPDATE SET required = EXCLUDED.required, updated_at = EXCLUDED.updated_at`,
+		p.Role, p.Required,
+	)
+	if err != nil {
+		logError(ctx, "SetMFAPolicy", err)
+	}
+	return err
+}
+
+// MFARequired сообщает, обязательна ли 2FA для роли.
+func (s *Store) MFARequired(ctx context.Context, role string) (bool, error) {
+	ctx, span := s.startOp(ctx, "MFARequired")
+	defer span.End()
+
+	var required bool
+	err := s.db.QueryRowContext(ctx, `SELECT required FROM mfa_policy WHERE role = $1`, role).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		logError(ctx, "MFARequired", err)
+		return false, err
+	}
+	return required, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/mfa"
+	"backend/internal/models"
+)
+
+func TestRecoveryCodeSingleUse(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	user := &models.User{Email: fmt.Sprintf("mfa-%d@smolensk.example", time.Now().UnixNano()), Password: "hash", Role: "editor", IsActive: true}
+	if err := s.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	codes, hashes, err := mfa.NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnableTOTP(ctx, user.ID, hashes); err != nil {
+		t.Fatal(err)
+	}
+
+	// Код вводится в другом регистре — хэш нормализованного значения тот же.
+	hash := mfa.HashRecoveryCode(" " + codes[0] + " ")
+	if ok, err := s.UseRecoveryCode(ctx, user.ID, hash); err != nil || !ok {
+		t.Fatalf("first use = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := s.UseRecoveryCode(ctx, user.ID, hash); err != nil || ok {
+		t.Errorf("second use = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	// Новый набор отменяет старые коды.
+	if err := s.ReplaceRecoveryCodes(ctx, user.ID, []string{mfa.HashRecoveryCode("aaaaa-bbbbb")}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s.UseRecoveryCode(ctx, user.ID, hashes[1]); ok {
+		t.Error("code from the replaced set accepted")
+	}
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	user := &models.User{Email: fmt.Sprintf("totp-%d@smolensk.example", time.Now().UnixNano()), Password: "hash", Role: "editor", IsActive: true}
+	if err := s.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		step int64
+		want bool
+	}{
+		{100, true},
+		{100, false}, // повтор того же кода
+		{99, false},  // более старый код
+		{101, true},
+	}
+	for _, tt := range tests {
+		if ok, err := s.UseTOTPStep(ctx, user.ID, tt.step); err != nil || ok != tt.want {
+			t.Errorf("UseTOTPStep(%d) = (%v, %v), want (%v, nil)", tt.step, ok, err, tt.want)
+		}
+	}
+
+	// Новый секрет начинает отсчёт шагов заново.
+	if err := s.SetTOTPSecret(ctx, user.ID, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.UseTOTPStep(ctx, user.ID, 50); err != nil || !ok {
+		t.Errorf("after new secret: UseTOTPStep(50) = (%v, %v), want (true, nil)", ok, err)
+	}
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrateLockID — ключ pg_advisory_lock: реплики, стартующие одновременно, применяют миграции по очереди.
+const migrateLockID = 0x736d6f6c // "smol"
+
+// Migration — один файл NNN_name.up.sql.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// LoadMigrations читает миграции из fsys по порядку версий. Версии должны идти подряд
+// с 1 и заканчиваться на SchemaVersion — иначе код и файлы разошлись.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	var res []Migration
+	for _, f := range files {
+		num, _, ok := strings.Cut(path.Base(f), "_")
+		v, err := strconv.Atoi(num)
+		if !ok || err != nil || v < 1 {
+			return nil, fmt.Errorf("migration %s: file name must be NNN_name.up.sql", f)
+		}
+		body, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Migration{Version: v, Name: strings.TrimSuffix(path.Base(f), ".up.sql"), SQL: string(body)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	for i, m := range res {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %s: expected version %d", m.Name, i+1)
+		}
+	}
+	if len(res) != SchemaVersion {
+		return nil, fmt.Errorf("found %d migrations, but SchemaVersion is %d", len(res), SchemaVersion)
+	}
+	return res, nil
+}
+
+// Migrate применяет ещё не применённые миграции из fsys, каждую в своей транзакции,
+// и возвращает их версии. Применённой считается версия не выше MAX(version) в schema_migrations.
+func (s *Store) Migrate(ctx context.Context, fsys fs.FS) ([]int, error) {
+	list, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Блокировка сессии: все запросы — через одно соединение.
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrateLockID); err != nil {
+		return nil, fmt.Errorf("lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrateLockID)
+
+	current, err := appliedVersion(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+
+	var applied []int
+	for _, m := range list {
+		if m.Version <= current {
+			continue
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, err
+		}
+		// Миграции с 003 сами отмечают себя в schema_migrations; вторая вставка — для 001 и 002.
+		_, err = tx.ExecContext(ctx, m.SQL)
+		if err == nil && m.Version >= 3 {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT (version) DO NOTHING`, m.Version)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return applied, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		slog.Info("Migration applied", "version", m.Version, "name", m.Name)
+		applied = append(applied, m.Version)
+	}
+	return applied, nil
+}
+
+// appliedVersion — последняя применённая миграция. schema_migrations появилась в 003:
+// если её нет, а users есть, база создана из 001 (и, возможно, 002, она повторяема) — версия 1.
+func appliedVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var hasVersions, hasUsers bool
+	if err := conn.QueryRowContext(ctx,
+		`SELECT to_regclass('public.schema_migrations') IS NOT NULL, to_regclass('public.users') IS NOT NULL`,
+	).Scan(&hasVersions, &hasUsers); err != nil {
+		return 0, err
+	}
+	switch {
+	case hasVersions:
+		var v sql.NullInt64
+		err := conn.QueryRowContext(ctx, `SELECT MAX(version) FROM public.schema_migrations`).Scan(&v)
+		return int(v.Int64), err
+	case hasUsers:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
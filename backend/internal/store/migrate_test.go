@@ -0,0 +1,59 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"backend/migrations"
+)
+
+func TestLoadMigrationsEmbedded(t *testing.T) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := list[len(list)-1]; last.Version != SchemaVersion {
+		t.Errorf("last migration %s, want version %d", last.Name, SchemaVersion)
+	}
+}
+
+// migrationFS — миграции 1..n, кроме пропущенных версий skip.
+func migrationFS(n int, skip ...int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+next:
+	for v := 1; v <= n; v++ {
+		for _, s := range skip {
+			if v == s {
+				continue next
+			}
+		}
+		fsys[fmt.Sprintf("%03d_step.up.sql", v)] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	}
+	return fsys
+}
+
+func TestLoadMigrationsRejects(t *testing.T) {
+	badName := migrationFS(SchemaVersion)
+	badName["init.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	duplicate := migrationFS(SchemaVersion)
+	duplicate["002_again.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"gap", migrationFS(SchemaVersion+1, 2)},
+		{"behind SchemaVersion", migrationFS(SchemaVersion - 1)},
+		{"ahead of SchemaVersion", migrationFS(SchemaVersion + 1)},
+		{"bad name", badName},
+		{"duplicate version", duplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tt.fsys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"backend/internal/apperr"
+	"backend/internal/models"
+)
+
+// ErrEmailTaken — email из OIDC-провайдера уже занят другим пользователем.
+var ErrEmailTaken = apperr.Conflict("Email is already used by another account")
+
+// UpsertOIDCUser находит или создаёт пользователя, вошедшего через OIDC (JIT-provisioning).
+// Поиск идёт по (issuer, subject); если такого нет, а email подтверждён провайдером,
+// к нему привязывается существующая локальная учётная запись с тем же email.
+// Email и роль при каждом входе берутся из провайдера: он источник правды о группах.
+func (s *Store) UpsertOIDCUser(ctx context.Context, issuer, subject, email string, emailVerified bool, role string) (*models.User, error) {
+	ctx, span := s.startOp(ctx, "UpsertOIDCUser")
+	defer span.End()
+
+	user := &models.User{}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var id int
+		err := tx.QueryRowContext(ctx,
+			`SELECT id FROM users WHERE oidc_issuer = $1 AND oidc_subject = $2 FOR UPDATE`,
+			issuer, subject,
+		).Scan(&id)
+		if err == sql.ErrNoRows && emailVerified {
+			err = tx.QueryRowContext(ctx,
+				`SELECT id FROM users WHERE lower(email) = lower($1) AND oidc_subject IS NULL FOR UPDATE`,
+				email,
+			).Scan(&id)
+		}
+
+		switch {
+		case err == nil:
+			return scanUser(tx.QueryRowContext(ctx,
+				`UPDATE users SET email = $1, role = $2, oidc_issuer = $3, oidc_subject = $4, updated_at = CURRENT_TIMESTAMP
+                 WHERE id = $5 RETURNING `+userColumns,
+				email, role, issuer, subject, id,
+			), user)
+		case err == sql.ErrNoRows:
+			return scanUser(tx.QueryRowContext(ctx,
+				`INSERT INTO users (email, password, role, is_active, oidc_issuer, oidc_subject)
+                 VALUES ($1, '', $2, true, $3, $4) RETURNING `+userColumns,
+				email, role, issuer, subject,
+			), user)
+		default:
+			return err
+		}
+	})
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		logError(ctx, "UpsertOIDCUser", err)
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestUpsertOIDCUser(t *testing.T) {
+	s := testStore(t)
+	ctx := context.Background()
+	// Уникальные email и subject: тест можно повторять на той же базе.
+	run := time.Now().UnixNano()
+	email := func(name string) string { return fmt.Sprintf("%s-%d@smolensk.example", name, run) }
+	subject := func(name string) string { return fmt.Sprintf("%s-%d", name, run) }
+	const issuer = "https://idp.smolensk.example"
+
+	local := &models.User{Email: email("local"), Password: "hash", Role: "editor", IsActive: true}
+	if err := s.CreateUser(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+
+	// Подтверждённый email совпал с локальной учётной записью — она привязывается, роль берётся у провайдера.
+	linked, err := s.UpsertOIDCUser(ctx, issuer, subject("local"), local.Email, true, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.ID != local.ID || linked.Role != "admin" {
+		t.Errorf("linked user = id %d role %q, want id %d role admin", linked.ID, linked.Role, local.ID)
+	}
+
+	// Следующий вход находит пользователя по (issuer, subject), даже если email у провайдера сменился.
+	renamed, err := s.UpsertOIDCUser(ctx, issuer, subject("local"), email("renamed"), true, "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.ID != local.ID || renamed.Email != email("renamed") || renamed.Role != "editor" {
+		t.Errorf("returning user = %+v, want id %d with the new email and role", renamed, local.ID)
+	}
+
+	// Нового email нет в базе — создаётся новый пользователь без пароля.
+	created, err := s.UpsertOIDCUser(ctx, issuer, subject("new"), email("new"), true, "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == local.ID || created.Password != "" {
+		t.Errorf("new user = %+v, want a separate account without a password", created)
+	}
+
+	// Неподтверждённый email не привязывает чужую учётную запись.
+	other := &models.User{Email: email("other"), Password: "hash", Role: "editor", IsActive: true}
+	if err := s.CreateUser(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpsertOIDCUser(ctx, issuer, subject("unverified"), other.Email, false, "admin"); err != ErrEmailTaken {
+		t.Errorf("unverified email: err = %v, want ErrEmailTaken", err)
+	}
+
+	// Уже привязанная учётная запись не переходит к другому subject с тем же email.
+	if _, err := s.UpsertOIDCUser(ctx, issuer, subject("intruder"), email("renamed"), true, "admin"); err != ErrEmailTaken {
+		t.Errorf("second subject: err = %v, want ErrEmailTaken", err)
+	}
+	u, err := s.GetUserByID(ctx, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Role != "editor" {
+		t.Errorf("unlinked user role changed to %q", u.Role)
+	}
+}
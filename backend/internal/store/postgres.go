@@ -1,27 +1,58 @@
 package store
 
 import (
+    "context"
     "database/sql"
+    "errors"
     "fmt"
-    "log"
+    "log/slog"
+    "strings"
     "time"
 
     _ "github.com/lib/pq"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
     "backend/config"
+    "backend/internal/apperr"
+    "backend/internal/events"
+    "backend/internal/logging"
+    "backend/internal/metrics"
     "backend/internal/models"
+    "backend/internal/tracing"
 )
 
 type Store struct {
-    db *sql.DB
+    db           *sql.DB
+    queryTimeout time.Duration
+
+    // События об изменениях (см. events.go): шина и режим рассылки через NOTIFY.
+    dsn      string
+    events   Publisher
+    pgNotify bool
 }
 
-func NewStore(cfg *config.Config) (*Store, error) {
-    dsn := fmt.Sprintf(
-        "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-        cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
-    )
+// ErrVersionConflict — запись существует, но её версия не совпала с ожидаемой
+// (кто-то успел изменить её раньше). Отдаётся клиенту как 412.
+var ErrVersionConflict = apperr.New(apperr.KindPreconditionFailed, "Resource was modified by another user")
+
+// Ошибки "не найдено" по сущностям — store возвращает их вместо sql.ErrNoRows.
+var (
+    ErrUserNotFound         = apperr.NotFound("User not found")
+    ErrFineNotFound         = apperr.NotFound("Fine not found")
+    ErrTrafficLightNotFound = apperr.NotFound("Traffic light not found")
+    ErrNewsNotFound         = apperr.NotFound("News not found")
+    ErrServiceNotFound      = apperr.NotFound("Service not found")
+    ErrTeamMemberNotFound   = apperr.NotFound("Team member not found")
+    ErrProjectNotFound      = apperr.NotFound("Project not found")
+    ErrVacancyNotFound      = apperr.NotFound("Vacancy not found")
+)
+
+// ErrMFAAlreadyEnabled — новый секрет TOTP нельзя выдать, пока 2FA включена.
+var ErrMFAAlreadyEnabled = apperr.Conflict("Two-factor authentication is already enabled")
 
-    db, err := sql.Open("postgres", dsn)
+func NewStore(cfg *config.Config) (*Store, error) {
+    conn := dsn(cfg.DB)
+    db, err := sql.Open("postgres", conn)
     if err != nil {
         return nil, err
     }
@@ -30,61 +61,253 @@ func NewStore(cfg *config.Config) (*Store, error) {
     }
 
     // Опционально ограничить пул
-    db.SetMaxOpenConns(10)
-    db.SetMaxIdleConns(10)
-    db.SetConnMaxLifetime(30 * time.Minute)
+    db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+    db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+    db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+
+    slog.Info("Connected to PostgreSQL", "sslmode", cfg.DB.SSLMode)
+    return &Store{db: db, queryTimeout: cfg.DB.QueryTimeout, dsn: conn, pgNotify: cfg.Events.PGNotify}, nil
+}
 
-    log.Println("Connected to PostgreSQL")
-    return &Store{db: db}, nil
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// dsn собирает строку подключения lib/pq; значения в кавычках, чтобы пароль
+// с пробелами или кавычками не ломал разбор.
+func dsn(db config.DBConfig) string {
+    params := []string{
+        "host", db.Host,
+        "port", db.Port,
+        "user", db.User,
+        "password", db.Password,
+        "dbname", db.Name,
+        "sslmode", db.SSLMode,
+    }
+    if db.SSLRootCert != "" {
+        params = append(params, "sslrootcert", db.SSLRootCert)
+    }
+    var b strings.Builder
+    for i := 0; i < len(params); i += 2 {
+        if i > 0 {
+            b.WriteByte(' ')
+        }
+        fmt.Fprintf(&b, "%s='%s'", params[i], dsnEscaper.Replace(params[i+1]))
+    }
+    return b.String()
 }
 
 func (s *Store) Close() error  { return s.db.Close() }
 func (s *Store) GetDB() *sql.DB { return s.db }
 
-// Users
-func (s *Store) GetUserByEmail(email string) (*models.User, error) {
-    user := &models.User{}
+// SchemaVersion — номер последней миграции, под которую написан этот код.
+// Увеличивается вместе с каждым новым файлом в migrations/.
+const SchemaVersion = 16
 
-    query := `
-        SELECT id, email, password, role, created_at, updated_at 
-        FROM users 
-        WHERE email = $1
-    `
-    log.Printf("GetUserByEmail: searching for email '%s'", email)
+// Ping проверяет доступность БД.
+func (s *Store) Ping(ctx context.Context) error {
+    return s.db.PingContext(ctx)
+}
+
+// MigrationVersion — максимальная применённая версия из schema_migrations.
+func (s *Store) MigrationVersion(ctx context.Context) (int, error) {
+    var v sql.NullInt64
+    if err := s.db.QueryRowContext(ctx, `SELECT MAX(version) FROM public.schema_migrations`).Scan(&v); err != nil {
+        return 0, err
+    }
+    return int(v.Int64), nil
+}
+
+// opSpan — спан операции store; End дополнительно пишет длительность в метрики
+// и снимает таймаут запроса.
+type opSpan struct {
+    trace.Span
+    op     string
+    start  time.Time
+    cancel context.CancelFunc
+}
+
+func (s opSpan) End(opts ...trace.SpanEndOption) {
+    s.cancel()
+    metrics.StoreDuration.WithLabelValues(s.op).Observe(time.Since(s.start).Seconds())
+    s.Span.End(opts...)
+}
+
+// startOp начинает операцию store: ограничивает её queryTimeout (поверх контекста запроса,
+// который отменяется, если клиент ушёл) и открывает клиентский спан.
+func (s *Store) startOp(ctx context.Context, op string) (context.Context, opSpan) {
+    cancel := context.CancelFunc(func() {})
+    if s.queryTimeout > 0 {
+        ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+    }
+    ctx, span := tracing.Start(ctx, "store."+op,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(attribute.String("db.system", "postgresql")),
+    )
+    return ctx, opSpan{Span: span, op: op, start: time.Now(), cancel: cancel}
+}
+
+// logError пишет ошибку SQL в логгер запроса и отмечает её в спане.
+// Отмена запроса клиентом — не ошибка БД, такие случаи пишутся в debug.
+func logError(ctx context.Context, op string, err error) {
+    if errors.Is(err, context.Canceled) {
+        logging.FromContext(ctx).Debug("store: "+op+" canceled", "err", err)
+        return
+    }
+    logging.FromContext(ctx).Error("store: "+op+" failed", "err", err)
+    tracing.RecordError(ctx, err)
+}
+
+// versionConflictOrMissing вызывается, когда UPDATE ... WHERE id AND version не задел ни одной строки:
+// если запись есть — это конфликт версий, иначе notFound.
+func (s *Store) versionConflictOrMissing(ctx context.Context, table string, id int, notFound error) error {
+    var exists bool
+    if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM public.`+table+` WHERE id=$1)`, id).Scan(&exists); err != nil {
+        return err
+    }
+    if exists {
+        return ErrVersionConflict
+    }
+    return notFound
+}
+
+// deleteByID удаляет запись и возвращает notFound, если удалять было нечего.
+func (s *Store) deleteByID(ctx context.Context, table string, id int, notFound error) error {
+    res, err := s.db.ExecContext(ctx, `DELETE FROM public.`+table+` WHERE id=$1`, id)
+    if err != nil {
+        return err
+    }
+    if n, err := res.RowsAffected(); err == nil && n == 0 {
+        return notFound
+    }
+    return nil
+}
+
+// inTx выполняет fn в транзакции: коммит при nil, иначе откат.
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    if err := fn(tx); err != nil {
+        _ = tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
+
+// nullIfEmpty превращает пустую строку в NULL для необязательных колонок.
+func nullIfEmpty(v string) interface{} {
+    if v == "" {
+        return nil
+    }
+    return v
+}
 
-    err := s.db.QueryRow(query, email).Scan(
+// column — пара "колонка = значение" для частичного UPDATE.
+type column struct {
+    name  string
+    value interface{}
+}
+
+// updateColumns обновляет только переданные колонки записи id, если её версия равна version.
+// Возвращает новую версию; ErrVersionConflict/notFound — как у versionConflictOrMissing.
+// Имена колонок приходят только из кода store, не от клиента.
+func (s *Store) updateColumns(ctx context.Context, table string, id, version int, cols []column, notFound error) (int, error) {
+    set := []string{"updated_at=$3", "version=version+1"}
+    args := []interface{}{id, version, time.Now()}
+    for _, c := range cols {
+        args = append(args, c.value)
+        set = append(set, fmt.Sprintf("%s=$%d", c.name, len(args)))
+    }
+    query := `UPDATE public.` + table + ` SET ` + strings.Join(set, ", ") + ` WHERE id=$1 AND version=$2 RETURNING version`
+
+    var newVersion int
+    err := s.db.QueryRowContext(ctx, query, args...).Scan(&newVersion)
+    if err == sql.ErrNoRows {
+        return 0, s.versionConflictOrMissing(ctx, table, id, notFound)
+    }
+    if err != nil {
+        return 0, err
+    }
+    return newVersion, nil
+}
+
+// Users
+// userColumns — колонки users в порядке scanUser.
+const userColumns = `id, email, password, role, created_at, updated_at, failed_logins, locked_until, totp_secret, totp_enabled, totp_last_step`
+
+func scanUser(row *sql.Row, user *models.User) error {
+    var totpSecret sql.NullString
+    if err := row.Scan(
         &user.ID,
         &user.Email,
         &user.Password,
         &user.Role,
         &user.CreatedAt,
         &user.UpdatedAt,
-    )
+        &user.FailedLogins,
+        &user.LockedUntil,
+        &totpSecret,
+        &user.TOTPEnabled,
+        &user.TOTPLastStep,
+    ); err != nil {
+        return err
+    }
+    user.TOTPSecret = totpSecret.String
+    user.IsActive = true
+    return nil
+}
 
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+    ctx, span := s.startOp(ctx, "GetUserByEmail")
+    defer span.End()
+
+    user := &models.User{}
+
+    query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+    logger := logging.FromContext(ctx)
+    logger.Debug("GetUserByEmail: searching", "email", email)
+
+    err := scanUser(s.db.QueryRowContext(ctx, query, email), user)
     if err != nil {
         if err == sql.ErrNoRows {
-            log.Printf("GetUserByEmail: user '%s' not found", email)
-            return nil, err
+            logger.Debug("GetUserByEmail: not found", "email", email)
+            return nil, ErrUserNotFound
         }
-        log.Printf("GetUserByEmail error: %v", err)
+        logError(ctx, "GetUserByEmail", err)
         return nil, err
     }
 
-    user.IsActive = true
+    logger.Debug("GetUserByEmail: found", "user_id", user.ID, "role", user.Role)
 
-    log.Printf("GetUserByEmail: found user ID=%d, email=%s, role=%s",
-        user.ID, user.Email, user.Role)
+    return user, nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+    ctx, span := s.startOp(ctx, "GetUserByID")
+    defer span.End()
 
+    user := &models.User{}
+    err := scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id), user)
+    if err == sql.ErrNoRows {
+        return nil, ErrUserNotFound
+    }
+    if err != nil {
+        logError(ctx, "GetUserByID", err)
+        return nil, err
+    }
     return user, nil
 }
 
-func (s *Store) CreateUser(user *models.User) error {
+func (s *Store) CreateUser(ctx context.Context, user *models.User) error {
+    ctx, span := s.startOp(ctx, "CreateUser")
+    defer span.End()
+
     query := `
         INSERT INTO users (email, password, role, is_active) 
         VALUES ($1, $2, $3, $4) 
         RETURNING id, created_at, updated_at
     `
-    return s.db.QueryRow(
+    return s.db.QueryRowContext(ctx,
         query,
         user.Email,
         user.Password,
@@ -93,37 +316,91 @@ func (s *Store) CreateUser(user *models.User) error {
     ).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 }
 
-func (s *Store) UpdateUserPassword(userID int, hashedPassword string) error {
+func (s *Store) UpdateUserPassword(ctx context.Context, userID int, hashedPassword string) error {
+    ctx, span := s.startOp(ctx, "UpdateUserPassword")
+    defer span.End()
+
     query := `
         UPDATE users 
         SET password = $1, updated_at = CURRENT_TIMESTAMP 
         WHERE id = $2
     `
-    _, err := s.db.Exec(query, hashedPassword, userID)
+    _, err := s.db.ExecContext(ctx, query, hashedPassword, userID)
     return err
 }
 
+// RecordLoginFailure увеличивает счётчик неудачных входов и возвращает его новое значение.
+func (s *Store) RecordLoginFailure(ctx context.Context, userID int) (int, error) {
+    ctx, span := s.startOp(ctx, "RecordLoginFailure")
+    defer span.End()
+
+    var failures int
+    err := s.db.QueryRowContext(ctx,
+        `UPDATE users SET failed_logins = failed_logins + 1 WHERE id = $1 RETURNING failed_logins`,
+        userID,
+    ).Scan(&failures)
+    if err == sql.ErrNoRows {
+        return 0, ErrUserNotFound
+    }
+    if err != nil {
+        logError(ctx, "RecordLoginFailure", err)
+        return 0, err
+    }
+    return failures, nil
+}
+
+// LockUser блокирует вход пользователя до until.
+func (s *Store) LockUser(ctx context.Context, userID int, until time.Time) error {
+    ctx, span := s.startOp(ctx, "LockUser")
+    defer span.End()
+
+    if _, err := s.db.ExecContext(ctx, `UPDATE users SET locked_until = $1 WHERE id = $2`, until, userID); err != nil {
+        logError(ctx, "LockUser", err)
+        return err
+    }
+    return nil
+}
+
+// ClearLockout сбрасывает счётчик неудачных входов и снимает блокировку.
+func (s *Store) ClearLockout(ctx context.Context, userID int) error {
+    ctx, span := s.startOp(ctx, "ClearLockout")
+    defer span.End()
+
+    res, err := s.db.ExecContext(ctx, `UPDATE users SET failed_logins = 0, locked_until = NULL WHERE id = $1`, userID)
+    if err != nil {
+        logError(ctx, "ClearLockout", err)
+        return err
+    }
+    if n, err := res.RowsAffected(); err == nil && n == 0 {
+        return ErrUserNotFound
+    }
+    return nil
+}
+
 // Fines (оставляем time.Time)
-func (s *Store) GetFines() ([]models.Fine, error) {
+func (s *Store) GetFines(ctx context.Context) ([]models.Fine, error) {
+    ctx, span := s.startOp(ctx, "GetFines")
+    defer span.End()
+
     query := `
-        SELECT id, date, violations_total, orders_total, fines_amount_total, collected_amount_total,
+        SELECT id, date, violations_total, orders_total, fines_amount_total, collected_amount_total, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.fines
         ORDER BY date DESC
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetFines query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetFines query", err); return nil, err }
     defer rows.Close()
 
     var out []models.Fine
     for rows.Next() {
         var f models.Fine
         if err := rows.Scan(
-            &f.ID, &f.Date, &f.ViolationsTotal, &f.OrdersTotal, &f.FinesAmountTotal, &f.CollectedAmountTotal,
+            &f.ID, &f.Date, &f.ViolationsTotal, &f.OrdersTotal, &f.FinesAmountTotal, &f.CollectedAmountTotal, &f.Version,
             &f.CreatedAt, &f.UpdatedAt,
         ); err != nil {
-            log.Printf("GetFines scan err: %v", err)
+            logError(ctx, "GetFines scan", err)
             return nil, err
         }
         out = append(out, f)
@@ -131,7 +408,37 @@ func (s *Store) GetFines() ([]models.Fine, error) {
     return out, nil
 }
 
-func (s *Store) CreateFine(f *models.Fine) error {
+func (s *Store) GetFineByID(ctx context.Context, id int) (*models.Fine, error) {
+    ctx, span := s.startOp(ctx, "GetFineByID")
+    defer span.End()
+
+    query := `
+        SELECT id, date, violations_total, orders_total, fines_amount_total, collected_amount_total, version,
+               COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
+               COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
+        FROM public.fines
+        WHERE id = $1
+    `
+    var f models.Fine
+    err := s.db.QueryRowContext(ctx, query, id).Scan(
+        &f.ID, &f.Date, &f.ViolationsTotal, &f.OrdersTotal, &f.FinesAmountTotal, &f.CollectedAmountTotal, &f.Version,
+        &f.CreatedAt, &f.UpdatedAt,
+    )
+    if err != nil {
+        if err == sql.ErrNoRows {
+            logging.FromContext(ctx).Debug("GetFineByID: not found", "id", id)
+            return nil, ErrFineNotFound
+        }
+        logError(ctx, "GetFineByID", err)
+        return nil, err
+    }
+    return &f, nil
+}
+
+func (s *Store) CreateFine(ctx context.Context, f *models.Fine) error {
+    ctx, span := s.startOp(ctx, "CreateFine")
+    defer span.End()
+
     query := `
         INSERT INTO public.fines (date, violations_total, orders_total, fines_amount_total, collected_amount_total, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6,$7)
@@ -140,37 +447,68 @@ func (s *Store) CreateFine(f *models.Fine) error {
     now := time.Now()
     f.CreatedAt = now
     f.UpdatedAt = now
-    if err := s.db.QueryRow(query, f.Date, f.ViolationsTotal, f.OrdersTotal, f.FinesAmountTotal, f.CollectedAmountTotal, f.CreatedAt, f.UpdatedAt).Scan(&f.ID); err != nil {
-        log.Printf("CreateFine err: %v", err)
+    f.Version = 1
+    if err := s.db.QueryRowContext(ctx, query, f.Date, f.ViolationsTotal, f.OrdersTotal, f.FinesAmountTotal, f.CollectedAmountTotal, f.CreatedAt, f.UpdatedAt).Scan(&f.ID); err != nil {
+        logError(ctx, "CreateFine", err)
         return err
     }
+    s.emit(ctx, events.FineCreated, events.Ref{ID: f.ID, Version: f.Version})
+    s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "fines"})
     return nil
 }
 
-func (s *Store) UpdateFine(id int, f *models.Fine) error {
-    query := `
-        UPDATE public.fines
-        SET date=$2, violations_total=$3, orders_total=$4, fines_amount_total=$5, collected_amount_total=$6, updated_at=$7
-        WHERE id=$1
-    `
-    f.UpdatedAt = time.Now()
-    if _, err := s.db.Exec(query, id, f.Date, f.ViolationsTotal, f.OrdersTotal, f.FinesAmountTotal, f.CollectedAmountTotal, f.UpdatedAt); err != nil {
-        log.Printf("UpdateFine err: %v", err)
-        return err
+// PatchFine обновляет только переданные (не nil) поля, если версия записи равна version.
+func (s *Store) PatchFine(ctx context.Context, id, version int, p *models.UpdateFineRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchFine")
+    defer span.End()
+
+    var cols []column
+    if p.Date != nil {
+        cols = append(cols, column{"date", *p.Date})
     }
-    return nil
+    if p.ViolationsTotal != nil {
+        cols = append(cols, column{"violations_total", *p.ViolationsTotal})
+    }
+    if p.OrdersTotal != nil {
+        cols = append(cols, column{"orders_total", *p.OrdersTotal})
+    }
+    if p.FinesAmountTotal != nil {
+        cols = append(cols, column{"fines_amount_total", *p.FinesAmountTotal})
+    }
+    if p.CollectedAmountTotal != nil {
+        cols = append(cols, column{"collected_amount_total", *p.CollectedAmountTotal})
+    }
+    v, err := s.updateColumns(ctx, "fines", id, version, cols, ErrFineNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchFine", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.FineUpdated, events.Ref{ID: id, Version: v})
+        s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "fines"})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteFine(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.fines WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteFine err: %v", err)
-        return err
+func (s *Store) DeleteFine(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteFine")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "fines", id, ErrFineNotFound)
+    if err != nil && err != ErrFineNotFound {
+        logError(ctx, "DeleteFine", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.FineDeleted, events.Ref{ID: id})
+        s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "fines"})
+    }
+    return err
 }
 
 // Evacuations (оставляем time.Time)
-func (s *Store) GetEvacuations() ([]models.Evacuation, error) {
+func (s *Store) GetEvacuations(ctx context.Context) ([]models.Evacuation, error) {
+    ctx, span := s.startOp(ctx, "GetEvacuations")
+    defer span.End()
+
     query := `
         SELECT id, date, evacuators_count, trips_count, evacuations_count, fine_lot_income,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
@@ -178,8 +516,8 @@ func (s *Store) GetEvacuations() ([]models.Evacuation, error) {
         FROM public.evacuations
         ORDER BY date DESC
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetEvacuations query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetEvacuations query", err); return nil, err }
     defer rows.Close()
 
     var out []models.Evacuation
@@ -188,7 +526,7 @@ func (s *Store) GetEvacuations() ([]models.Evacuation, error) {
         if err := rows.Scan(
             &e.ID, &e.Date, &e.EvacuatorsCount, &e.TripsCount, &e.EvacuationsCount, &e.FineLotIncome, &e.CreatedAt, &e.UpdatedAt,
         ); err != nil {
-            log.Printf("GetEvacuations scan err: %v", err)
+            logError(ctx, "GetEvacuations scan", err)
             return nil, err
         }
         out = append(out, e)
@@ -196,7 +534,10 @@ func (s *Store) GetEvacuations() ([]models.Evacuation, error) {
     return out, nil
 }
 
-func (s *Store) CreateEvacuation(e *models.Evacuation) error {
+func (s *Store) CreateEvacuation(ctx context.Context, e *models.Evacuation) error {
+    ctx, span := s.startOp(ctx, "CreateEvacuation")
+    defer span.End()
+
     query := `
         INSERT INTO public.evacuations (date, evacuators_count, trips_count, evacuations_count, fine_lot_income, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6,$7)
@@ -205,15 +546,20 @@ func (s *Store) CreateEvacuation(e *models.Evacuation) error {
     now := time.Now()
     e.CreatedAt = now
     e.UpdatedAt = now
-    if err := s.db.QueryRow(query, e.Date, e.EvacuatorsCount, e.TripsCount, e.EvacuationsCount, e.FineLotIncome, e.CreatedAt, e.UpdatedAt).Scan(&e.ID); err != nil {
-        log.Printf("CreateEvacuation err: %v", err)
+    if err := s.db.QueryRowContext(ctx, query, e.Date, e.EvacuatorsCount, e.TripsCount, e.EvacuationsCount, e.FineLotIncome, e.CreatedAt, e.UpdatedAt).Scan(&e.ID); err != nil {
+        logError(ctx, "CreateEvacuation", err)
         return err
     }
+    s.emit(ctx, events.EvacuationCreated, events.Ref{ID: e.ID})
+    s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "evacuations"})
     return nil
 }
 
 // Evacuation routes (оставляем time.Time)
-func (s *Store) GetEvacuationRoutes() ([]models.EvacuationRoute, error) {
+func (s *Store) GetEvacuationRoutes(ctx context.Context) ([]models.EvacuationRoute, error) {
+    ctx, span := s.startOp(ctx, "GetEvacuationRoutes")
+    defer span.End()
+
     query := `
         SELECT id, year, month, route,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
@@ -221,15 +567,15 @@ func (s *Store) GetEvacuationRoutes() ([]models.EvacuationRoute, error) {
         FROM public.evacuation_routes
         ORDER BY year DESC, month
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetEvacuationRoutes query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetEvacuationRoutes query", err); return nil, err }
     defer rows.Close()
 
     var out []models.EvacuationRoute
     for rows.Next() {
         var r models.EvacuationRoute
         if err := rows.Scan(&r.ID, &r.Year, &r.Month, &r.Route, &r.CreatedAt, &r.UpdatedAt); err != nil {
-            log.Printf("GetEvacuationRoutes scan err: %v", err)
+            logError(ctx, "GetEvacuationRoutes scan", err)
             return nil, err
         }
         out = append(out, r)
@@ -237,7 +583,10 @@ func (s *Store) GetEvacuationRoutes() ([]models.EvacuationRoute, error) {
     return out, nil
 }
 
-func (s *Store) CreateEvacuationRoute(r *models.EvacuationRoute) error {
+func (s *Store) CreateEvacuationRoute(ctx context.Context, r *models.EvacuationRoute) error {
+    ctx, span := s.startOp(ctx, "CreateEvacuationRoute")
+    defer span.End()
+
     query := `
         INSERT INTO public.evacuation_routes (year, month, route, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5)
@@ -246,31 +595,35 @@ func (s *Store) CreateEvacuationRoute(r *models.EvacuationRoute) error {
     now := time.Now()
     r.CreatedAt = now
     r.UpdatedAt = now
-    if err := s.db.QueryRow(query, r.Year, r.Month, r.Route, r.CreatedAt, r.UpdatedAt).Scan(&r.ID); err != nil {
-        log.Printf("CreateEvacuationRoute err: %v", err)
+    if err := s.db.QueryRowContext(ctx, query, r.Year, r.Month, r.Route, r.CreatedAt, r.UpdatedAt).Scan(&r.ID); err != nil {
+        logError(ctx, "CreateEvacuationRoute", err)
         return err
     }
+    s.emit(ctx, events.EvacuationRouteCreated, events.RouteRef{ID: r.ID, Year: r.Year, Month: r.Month, Route: r.Route})
     return nil
 }
 
 // Traffic lights (оставляем time.Time)
-func (s *Store) GetTrafficLights() ([]models.TrafficLight, error) {
+func (s *Store) GetTrafficLights(ctx context.Context) ([]models.TrafficLight, error) {
+    ctx, span := s.startOp(ctx, "GetTrafficLights")
+    defer span.End()
+
     query := `
-        SELECT id, address, light_type, install_year, status,
+        SELECT id, address, light_type, install_year, status, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.traffic_lights
         ORDER BY install_year DESC
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetTrafficLights query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetTrafficLights query", err); return nil, err }
     defer rows.Close()
 
     var out []models.TrafficLight
     for rows.Next() {
         var t models.TrafficLight
-        if err := rows.Scan(&t.ID, &t.Address, &t.LightType, &t.InstallYear, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
-            log.Printf("GetTrafficLights scan err: %v", err)
+        if err := rows.Scan(&t.ID, &t.Address, &t.LightType, &t.InstallYear, &t.Status, &t.Version, &t.CreatedAt, &t.UpdatedAt); err != nil {
+            logError(ctx, "GetTrafficLights scan", err)
             return nil, err
         }
         out = append(out, t)
@@ -278,7 +631,34 @@ func (s *Store) GetTrafficLights() ([]models.TrafficLight, error) {
     return out, nil
 }
 
-func (s *Store) CreateTrafficLight(t *models.TrafficLight) error {
+func (s *Store) GetTrafficLightByID(ctx context.Context, id int) (*models.TrafficLight, error) {
+    ctx, span := s.startOp(ctx, "GetTrafficLightByID")
+    defer span.End()
+
+    query := `
+        SELECT id, address, light_type, install_year, status, version,
+               COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
+               COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
+        FROM public.traffic_lights
+        WHERE id = $1
+    `
+    var t models.TrafficLight
+    err := s.db.QueryRowContext(ctx, query, id).Scan(&t.ID, &t.Address, &t.LightType, &t.InstallYear, &t.Status, &t.Version, &t.CreatedAt, &t.UpdatedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            logging.FromContext(ctx).Debug("GetTrafficLightByID: not found", "id", id)
+            return nil, ErrTrafficLightNotFound
+        }
+        logError(ctx, "GetTrafficLightByID", err)
+        return nil, err
+    }
+    return &t, nil
+}
+
+func (s *Store) CreateTrafficLight(ctx context.Context, t *models.TrafficLight) error {
+    ctx, span := s.startOp(ctx, "CreateTrafficLight")
+    defer span.End()
+
     query := `
         INSERT INTO public.traffic_lights (address, light_type, install_year, status, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6)
@@ -287,53 +667,104 @@ func (s *Store) CreateTrafficLight(t *models.TrafficLight) error {
     now := time.Now()
     t.CreatedAt = now
     t.UpdatedAt = now
-    if err := s.db.QueryRow(query, t.Address, t.LightType, t.InstallYear, t.Status, t.CreatedAt, t.UpdatedAt).Scan(&t.ID); err != nil {
-        log.Printf("CreateTrafficLight err: %v", err)
+    t.Version = 1
+    if err := s.db.QueryRowContext(ctx, query, t.Address, t.LightType, t.InstallYear, t.Status, t.CreatedAt, t.UpdatedAt).Scan(&t.ID); err != nil {
+        logError(ctx, "CreateTrafficLight", err)
         return err
     }
+    s.emit(ctx, events.TrafficLightCreated, events.Ref{ID: t.ID, Version: t.Version})
+    s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "traffic_lights"})
     return nil
 }
 
-func (s *Store) UpdateTrafficLight(id int, t *models.TrafficLight) error {
-    query := `
-        UPDATE public.traffic_lights
-        SET address=$2, light_type=$3, install_year=$4, status=$5, updated_at=$6
-        WHERE id=$1
-    `
-    t.UpdatedAt = time.Now()
-    if _, err := s.db.Exec(query, id, t.Address, t.LightType, t.InstallYear, t.Status, t.UpdatedAt); err != nil {
-        log.Printf("UpdateTrafficLight err: %v", err)
-        return err
+// PatchTrafficLight обновляет только переданные (не nil) поля, если версия записи равна version.
+func (s *Store) PatchTrafficLight(ctx context.Context, id, version int, p *models.UpdateTrafficLightRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchTrafficLight")
+    defer span.End()
+
+    var cols []column
+    if p.Address != nil {
+        cols = append(cols, column{"address", *p.Address})
     }
-    return nil
+    if p.LightType != nil {
+        cols = append(cols, column{"light_type", *p.LightType})
+    }
+    if p.InstallYear != nil {
+        cols = append(cols, column{"install_year", *p.InstallYear})
+    }
+    if p.Status != nil {
+        cols = append(cols, column{"status", *p.Status})
+    }
+
+    // Прежний статус — для события traffic_light.status_changed. Он относится к версии version,
+    // только если прочитанная версия совпала: иначе UPDATE ниже всё равно получит конфликт.
+    var old struct {
+        status, address string
+        version         int
+    }
+    if p.Status != nil && s.events != nil {
+        err := s.db.QueryRowContext(ctx, `SELECT status, address, version FROM public.traffic_lights WHERE id=$1`, id).
+            Scan(&old.status, &old.address, &old.version)
+        if err != nil && err != sql.ErrNoRows {
+            logError(ctx, "PatchTrafficLight", err)
+            return 0, err
+        }
+    }
+
+    v, err := s.updateColumns(ctx, "traffic_lights", id, version, cols, ErrTrafficLightNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchTrafficLight", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.TrafficLightUpdated, events.Ref{ID: id, Version: v})
+        if p.Status != nil && old.version == version && old.status != *p.Status {
+            address := old.address
+            if p.Address != nil {
+                address = *p.Address
+            }
+            s.emit(ctx, events.TrafficLightStatusChanged, events.StatusChange{ID: id, Address: address, From: old.status, To: *p.Status})
+        }
+        s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "traffic_lights"})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteTrafficLight(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.traffic_lights WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteTrafficLight err: %v", err)
-        return err
+func (s *Store) DeleteTrafficLight(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteTrafficLight")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "traffic_lights", id, ErrTrafficLightNotFound)
+    if err != nil && err != ErrTrafficLightNotFound {
+        logError(ctx, "DeleteTrafficLight", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.TrafficLightDeleted, events.Ref{ID: id})
+        s.emit(ctx, events.StatsUpdated, events.StatsSource{Source: "traffic_lights"})
+    }
+    return err
 }
 
 // News (оставляем time.Time)
-func (s *Store) GetNews() ([]models.News, error) {
+func (s *Store) GetNews(ctx context.Context) ([]models.News, error) {
+    ctx, span := s.startOp(ctx, "GetNews")
+    defer span.End()
+
     query := `
-        SELECT id, title, content, tag, date,
+        SELECT id, title, content, tag, date, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.news
         ORDER BY date DESC
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetNews query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetNews query", err); return nil, err }
     defer rows.Close()
 
     var out []models.News
     for rows.Next() {
         var n models.News
-        if err := rows.Scan(&n.ID, &n.Title, &n.Content, &n.Tag, &n.Date, &n.CreatedAt, &n.UpdatedAt); err != nil {
-            log.Printf("GetNews scan err: %v", err)
+        if err := rows.Scan(&n.ID, &n.Title, &n.Content, &n.Tag, &n.Date, &n.Version, &n.CreatedAt, &n.UpdatedAt); err != nil {
+            logError(ctx, "GetNews scan", err)
             return nil, err
         }
         out = append(out, n)
@@ -341,30 +772,57 @@ func (s *Store) GetNews() ([]models.News, error) {
     return out, nil
 }
 
-func (s *Store) GetNewsByID(id int) (*models.News, error) {
+func (s *Store) GetNewsByID(ctx context.Context, id int) (*models.News, error) {
+    ctx, span := s.startOp(ctx, "GetNewsByID")
+    defer span.End()
+
     query := `
-        SELECT id, title, content, tag, date,
+        SELECT id, title, content, tag, date, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.news
         WHERE id = $1
     `
     var n models.News
-    err := s.db.QueryRow(query, id).Scan(
-        &n.ID, &n.Title, &n.Content, &n.Tag, &n.Date, &n.CreatedAt, &n.UpdatedAt,
+    err := s.db.QueryRowContext(ctx, query, id).Scan(
+        &n.ID, &n.Title, &n.Content, &n.Tag, &n.Date, &n.Version, &n.CreatedAt, &n.UpdatedAt,
     )
     if err != nil {
         if err == sql.ErrNoRows {
-            log.Printf("GetNewsByID: news with id=%d not found", id)
-        } else {
-            log.Printf("GetNewsByID err: %v", err)
+            logging.FromContext(ctx).Debug("GetNewsByID: not found", "id", id)
+            return nil, ErrNewsNotFound
         }
+        logError(ctx, "GetNewsByID", err)
         return nil, err
     }
     return &n, nil
 }
 
-func (s *Store) CreateNews(n *models.News) error {
+// GetNewsTags — теги, которые встречаются в новостях, по алфавиту.
+func (s *Store) GetNewsTags(ctx context.Context) ([]string, error) {
+    ctx, span := s.startOp(ctx, "GetNewsTags")
+    defer span.End()
+
+    rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT tag FROM public.news WHERE tag <> '' ORDER BY tag`)
+    if err != nil { logError(ctx, "GetNewsTags query", err); return nil, err }
+    defer rows.Close()
+
+    tags := []string{}
+    for rows.Next() {
+        var t string
+        if err := rows.Scan(&t); err != nil {
+            logError(ctx, "GetNewsTags scan", err)
+            return nil, err
+        }
+        tags = append(tags, t)
+    }
+    return tags, rows.Err()
+}
+
+func (s *Store) CreateNews(ctx context.Context, n *models.News) error {
+    ctx, span := s.startOp(ctx, "CreateNews")
+    defer span.End()
+
     query := `
         INSERT INTO public.news (title, content, tag, date, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6)
@@ -374,53 +832,75 @@ func (s *Store) CreateNews(n *models.News) error {
     n.Date = now
     n.CreatedAt = now
     n.UpdatedAt = now
-    if err := s.db.QueryRow(query, n.Title, n.Content, n.Tag, n.Date, n.CreatedAt, n.UpdatedAt).Scan(&n.ID); err != nil {
-        log.Printf("CreateNews err: %v", err)
+    n.Version = 1
+    if err := s.db.QueryRowContext(ctx, query, n.Title, n.Content, n.Tag, n.Date, n.CreatedAt, n.UpdatedAt).Scan(&n.ID); err != nil {
+        logError(ctx, "CreateNews", err)
         return err
     }
+    s.emit(ctx, events.NewsCreated, events.NewsRef{ID: n.ID, Title: n.Title, Tag: n.Tag})
     return nil
 }
 
-func (s *Store) UpdateNews(id int, n *models.News) error {
-    query := `
-        UPDATE public.news
-        SET title=$2, content=$3, tag=$4, updated_at=$5
-        WHERE id=$1
-    `
-    n.UpdatedAt = time.Now()
-    if _, err := s.db.Exec(query, id, n.Title, n.Content, n.Tag, n.UpdatedAt); err != nil {
-        log.Printf("UpdateNews err: %v", err)
-        return err
+// PatchNews обновляет только переданные (не nil) поля, если версия записи равна version.
+func (s *Store) PatchNews(ctx context.Context, id, version int, p *models.UpdateNewsRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchNews")
+    defer span.End()
+
+    var cols []column
+    if p.Title != nil {
+        cols = append(cols, column{"title", *p.Title})
     }
-    return nil
+    if p.Content != nil {
+        cols = append(cols, column{"content", *p.Content})
+    }
+    if p.Tag != nil {
+        cols = append(cols, column{"tag", *p.Tag})
+    }
+    v, err := s.updateColumns(ctx, "news", id, version, cols, ErrNewsNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchNews", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.NewsUpdated, events.Ref{ID: id, Version: v})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteNews(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.news WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteNews err: %v", err)
-        return err
+func (s *Store) DeleteNews(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteNews")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "news", id, ErrNewsNotFound)
+    if err != nil && err != ErrNewsNotFound {
+        logError(ctx, "DeleteNews", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.NewsDeleted, events.Ref{ID: id})
+    }
+    return err
 }
 
 // Services (оставляем time.Time)
-func (s *Store) GetServices() ([]models.Service, error) {
+func (s *Store) GetServices(ctx context.Context) ([]models.Service, error) {
+    ctx, span := s.startOp(ctx, "GetServices")
+    defer span.End()
+
     query := `
         SELECT id, title, description, price, category,
-               COALESCE(icon_url, '') AS icon_url,
+               COALESCE(icon_url, '') AS icon_url, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.services
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetServices query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetServices query", err); return nil, err }
     defer rows.Close()
 
     var out []models.Service
     for rows.Next() {
         var srv models.Service
-        if err := rows.Scan(&srv.ID, &srv.Title, &srv.Description, &srv.Price, &srv.Category, &srv.IconURL, &srv.CreatedAt, &srv.UpdatedAt); err != nil {
-            log.Printf("GetServices scan err: %v", err)
+        if err := rows.Scan(&srv.ID, &srv.Title, &srv.Description, &srv.Price, &srv.Category, &srv.IconURL, &srv.Version, &srv.CreatedAt, &srv.UpdatedAt); err != nil {
+            logError(ctx, "GetServices scan", err)
             return nil, err
         }
         out = append(out, srv)
@@ -428,31 +908,37 @@ func (s *Store) GetServices() ([]models.Service, error) {
     return out, nil
 }
 
-func (s *Store) GetServiceByID(id int) (*models.Service, error) {
+func (s *Store) GetServiceByID(ctx context.Context, id int) (*models.Service, error) {
+    ctx, span := s.startOp(ctx, "GetServiceByID")
+    defer span.End()
+
     query := `
         SELECT id, title, description, price, category,
-               COALESCE(icon_url, '') AS icon_url,
+               COALESCE(icon_url, '') AS icon_url, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.services
         WHERE id = $1
     `
     var srv models.Service
-    err := s.db.QueryRow(query, id).Scan(
-        &srv.ID, &srv.Title, &srv.Description, &srv.Price, &srv.Category, &srv.IconURL, &srv.CreatedAt, &srv.UpdatedAt,
+    err := s.db.QueryRowContext(ctx, query, id).Scan(
+        &srv.ID, &srv.Title, &srv.Description, &srv.Price, &srv.Category, &srv.IconURL, &srv.Version, &srv.CreatedAt, &srv.UpdatedAt,
     )
     if err != nil {
         if err == sql.ErrNoRows {
-            log.Printf("GetServiceByID: service with id=%d not found", id)
-        } else {
-            log.Printf("GetServiceByID err: %v", err)
+            logging.FromContext(ctx).Debug("GetServiceByID: not found", "id", id)
+            return nil, ErrServiceNotFound
         }
+        logError(ctx, "GetServiceByID", err)
         return nil, err
     }
     return &srv, nil
 }
 
-func (s *Store) CreateService(srv *models.Service) error {
+func (s *Store) CreateService(ctx context.Context, srv *models.Service) error {
+    ctx, span := s.startOp(ctx, "CreateService")
+    defer span.End()
+
     query := `
         INSERT INTO public.services (title, description, price, category, icon_url, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6,$7)
@@ -461,54 +947,83 @@ func (s *Store) CreateService(srv *models.Service) error {
     now := time.Now()
     srv.CreatedAt = now
     srv.UpdatedAt = now
-    if err := s.db.QueryRow(query, srv.Title, srv.Description, srv.Price, srv.Category, srv.IconURL, srv.CreatedAt, srv.UpdatedAt).Scan(&srv.ID); err != nil {
-        log.Printf("CreateService err: %v", err)
+    srv.Version = 1
+    if err := s.db.QueryRowContext(ctx, query, srv.Title, srv.Description, srv.Price, srv.Category, srv.IconURL, srv.CreatedAt, srv.UpdatedAt).Scan(&srv.ID); err != nil {
+        logError(ctx, "CreateService", err)
         return err
     }
+    s.emit(ctx, events.ServiceCreated, events.Ref{ID: srv.ID, Version: srv.Version})
     return nil
 }
 
-func (s *Store) UpdateService(id int, srv *models.Service) error {
-    query := `
-        UPDATE public.services
-        SET title=$2, description=$3, price=$4, category=$5, icon_url=$6, updated_at=$7
-        WHERE id=$1
-    `
-    srv.UpdatedAt = time.Now()
-    if _, err := s.db.Exec(query, id, srv.Title, srv.Description, srv.Price, srv.Category, srv.IconURL, srv.UpdatedAt); err != nil {
-        log.Printf("UpdateService err: %v", err)
-        return err
+// PatchService обновляет только переданные (не nil) поля, если версия записи равна version.
+// Пустой icon_url сохраняется как NULL.
+func (s *Store) PatchService(ctx context.Context, id, version int, p *models.UpdateServiceRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchService")
+    defer span.End()
+
+    var cols []column
+    if p.Title != nil {
+        cols = append(cols, column{"title", *p.Title})
     }
-    return nil
+    if p.Description != nil {
+        cols = append(cols, column{"description", *p.Description})
+    }
+    if p.Price != nil {
+        cols = append(cols, column{"price", *p.Price})
+    }
+    if p.Category != nil {
+        cols = append(cols, column{"category", *p.Category})
+    }
+    if p.IconURL != nil {
+        cols = append(cols, column{"icon_url", nullIfEmpty(*p.IconURL)})
+    }
+    v, err := s.updateColumns(ctx, "services", id, version, cols, ErrServiceNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchService", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.ServiceUpdated, events.Ref{ID: id, Version: v})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteService(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.services WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteService err: %v", err)
-        return err
+func (s *Store) DeleteService(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteService")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "services", id, ErrServiceNotFound)
+    if err != nil && err != ErrServiceNotFound {
+        logError(ctx, "DeleteService", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.ServiceDeleted, events.Ref{ID: id})
+    }
+    return err
 }
 
 // Team (реализуем создание и обновление; предполагаем timestamps как *time.Time)
-func (s *Store) GetTeam() ([]models.TeamMember, error) {
+func (s *Store) GetTeam(ctx context.Context) ([]models.TeamMember, error) {
+    ctx, span := s.startOp(ctx, "GetTeam")
+    defer span.End()
+
     query := `
         SELECT id, name, position, experience,
-               photo_url,
+               photo_url, version,
                created_at,
                updated_at
         FROM public.team
         ORDER BY id
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetTeam query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetTeam query", err); return nil, err }
     defer rows.Close()
 
     var out []models.TeamMember
     for rows.Next() {
         var m models.TeamMember
-        if err := rows.Scan(&m.ID, &m.Name, &m.Position, &m.Experience, &m.PhotoURL, &m.CreatedAt, &m.UpdatedAt); err != nil {
-            log.Printf("GetTeam scan err: %v", err)
+        if err := rows.Scan(&m.ID, &m.Name, &m.Position, &m.Experience, &m.PhotoURL, &m.Version, &m.CreatedAt, &m.UpdatedAt); err != nil {
+            logError(ctx, "GetTeam scan", err)
             return nil, err
         }
         out = append(out, m)
@@ -516,31 +1031,37 @@ func (s *Store) GetTeam() ([]models.TeamMember, error) {
     return out, nil
 }
 
-func (s *Store) GetTeamMemberByID(id int) (*models.TeamMember, error) {
+func (s *Store) GetTeamMemberByID(ctx context.Context, id int) (*models.TeamMember, error) {
+    ctx, span := s.startOp(ctx, "GetTeamMemberByID")
+    defer span.End()
+
     query := `
         SELECT id, name, position, experience,
-               photo_url,
+               photo_url, version,
                created_at,
                updated_at
         FROM public.team
         WHERE id = $1
     `
     var m models.TeamMember
-    err := s.db.QueryRow(query, id).Scan(
-        &m.ID, &m.Name, &m.Position, &m.Experience, &m.PhotoURL, &m.CreatedAt, &m.UpdatedAt,
+    err := s.db.QueryRowContext(ctx, query, id).Scan(
+        &m.ID, &m.Name, &m.Position, &m.Experience, &m.PhotoURL, &m.Version, &m.CreatedAt, &m.UpdatedAt,
     )
     if err != nil {
         if err == sql.ErrNoRows {
-            log.Printf("GetTeamMemberByID: team member with id=%d not found", id)
-        } else {
-            log.Printf("GetTeamMemberByID err: %v", err)
+            logging.FromContext(ctx).Debug("GetTeamMemberByID: not found", "id", id)
+            return nil, ErrTeamMemberNotFound
         }
+        logError(ctx, "GetTeamMemberByID", err)
         return nil, err
     }
     return &m, nil
 }
 
-func (s *Store) CreateTeamMember(m *models.TeamMember) error {
+func (s *Store) CreateTeamMember(ctx context.Context, m *models.TeamMember) error {
+    ctx, span := s.startOp(ctx, "CreateTeamMember")
+    defer span.End()
+
     query := `
         INSERT INTO public.team (name, position, experience, photo_url, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
@@ -550,74 +1071,79 @@ func (s *Store) CreateTeamMember(m *models.TeamMember) error {
     // если в модели *time.Time:
     m.CreatedAt = &now
     m.UpdatedAt = &now
+    m.Version = 1
 
-    if err := s.db.QueryRow(query, m.Name, m.Position, m.Experience, m.PhotoURL, m.CreatedAt, m.UpdatedAt).Scan(&m.ID); err != nil {
-        log.Printf("CreateTeamMember err: %v", err)
+    if err := s.db.QueryRowContext(ctx, query, m.Name, m.Position, m.Experience, m.PhotoURL, m.CreatedAt, m.UpdatedAt).Scan(&m.ID); err != nil {
+        logError(ctx, "CreateTeamMember", err)
         return err
     }
+    s.emit(ctx, events.TeamMemberCreated, events.Ref{ID: m.ID, Version: m.Version})
     return nil
 }
 
-func (s *Store) UpdateTeam(id int, m *models.TeamMember) (int64, error) {
-    query := `
-        UPDATE public.team
-        SET name=$2, position=$3, experience=$4, photo_url=$5, updated_at=$6
-        WHERE id=$1
-    `
-    now := time.Now()
-    // если в модели *time.Time:
-    m.UpdatedAt = &now
+// PatchTeamMember обновляет только переданные (не nil) поля, если версия записи равна version.
+// Пустой photo_url сохраняется как NULL.
+func (s *Store) PatchTeamMember(ctx context.Context, id, version int, p *models.UpdateTeamMemberRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchTeamMember")
+    defer span.End()
 
-    res, err := s.db.Exec(query, id, m.Name, m.Position, m.Experience, m.PhotoURL, m.UpdatedAt)
-    if err != nil {
-        log.Printf("UpdateTeam err: %v", err)
-        return 0, err
+    var cols []column
+    if p.Name != nil {
+        cols = append(cols, column{"name", *p.Name})
     }
-    n, _ := res.RowsAffected()
-    if n == 0 {
-        log.Printf("UpdateTeam: team member id=%d not found", id)
+    if p.Position != nil {
+        cols = append(cols, column{"position", *p.Position})
     }
-    return n, nil
-}
-
-func (s *Store) DeleteTeam(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.team WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteTeam err: %v", err)
-        return err
+    if p.Experience != nil {
+        cols = append(cols, column{"experience", *p.Experience})
     }
-    return nil
+    if p.PhotoURL != nil {
+        cols = append(cols, column{"photo_url", nullIfEmpty(*p.PhotoURL)})
+    }
+    v, err := s.updateColumns(ctx, "team", id, version, cols, ErrTeamMemberNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchTeamMember", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.TeamMemberUpdated, events.Ref{ID: id, Version: v})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteTeamByID(id int) (int64, error) {
-    res, err := s.db.Exec(`DELETE FROM public.team WHERE id=$1`, id)
-    if err != nil {
-        log.Printf("DeleteTeamByID err: %v", err)
-        return 0, err
+func (s *Store) DeleteTeam(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteTeam")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "team", id, ErrTeamMemberNotFound)
+    if err != nil && err != ErrTeamMemberNotFound {
+        logError(ctx, "DeleteTeam", err)
     }
-    n, _ := res.RowsAffected()
-    if n == 0 {
-        log.Printf("DeleteTeamByID: team member id=%d not found", id)
+    if err == nil {
+        s.emit(ctx, events.TeamMemberDeleted, events.Ref{ID: id})
     }
-    return n, nil
+    return err
 }
 
 // Projects
-func (s *Store) GetProjects() ([]models.Project, error) {
+func (s *Store) GetProjects(ctx context.Context) ([]models.Project, error) {
+    ctx, span := s.startOp(ctx, "GetProjects")
+    defer span.End()
+
     query := `
-        SELECT id, title, description, category, status,
+        SELECT id, title, description, category, status, version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.projects
     `
-    rows, err := s.db.Query(query)
-    if err != nil { log.Printf("GetProjects query err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, query)
+    if err != nil { logError(ctx, "GetProjects query", err); return nil, err }
     defer rows.Close()
 
     var out []models.Project
     for rows.Next() {
         var p models.Project
-        if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Category, &p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
-            log.Printf("GetProjects scan err: %v", err)
+        if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Category, &p.Status, &p.Version, &p.CreatedAt, &p.UpdatedAt); err != nil {
+            logError(ctx, "GetProjects scan", err)
             return nil, err
         }
         out = append(out, p)
@@ -625,8 +1151,35 @@ func (s *Store) GetProjects() ([]models.Project, error) {
     return out, nil
 }
 
+func (s *Store) GetProjectByID(ctx context.Context, id int) (*models.Project, error) {
+    ctx, span := s.startOp(ctx, "GetProjectByID")
+    defer span.End()
+
+    query := `
+        SELECT id, title, description, category, status, version,
+               COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
+               COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
+        FROM public.projects
+        WHERE id = $1
+    `
+    var p models.Project
+    err := s.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Title, &p.Description, &p.Category, &p.Status, &p.Version, &p.CreatedAt, &p.UpdatedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            logging.FromContext(ctx).Debug("GetProjectByID: not found", "id", id)
+            return nil, ErrProjectNotFound
+        }
+        logError(ctx, "GetProjectByID", err)
+        return nil, err
+    }
+    return &p, nil
+}
+
 // CreateProject
-func (s *Store) CreateProject(p *models.Project) error {
+func (s *Store) CreateProject(ctx context.Context, p *models.Project) error {
+    ctx, span := s.startOp(ctx, "CreateProject")
+    defer span.End()
+
     query := `
         INSERT INTO public.projects (title, description, category, status, created_at, updated_at)
         VALUES ($1,$2,$3,$4,$5,$6)
@@ -635,39 +1188,63 @@ func (s *Store) CreateProject(p *models.Project) error {
     now := time.Now()
     p.CreatedAt = now
     p.UpdatedAt = now
-    if err := s.db.QueryRow(query, p.Title, p.Description, p.Category, p.Status, p.CreatedAt, p.UpdatedAt).Scan(&p.ID); err != nil {
-        log.Printf("CreateProject err: %v", err)
+    p.Version = 1
+    if err := s.db.QueryRowContext(ctx, query, p.Title, p.Description, p.Category, p.Status, p.CreatedAt, p.UpdatedAt).Scan(&p.ID); err != nil {
+        logError(ctx, "CreateProject", err)
         return err
     }
+    s.emit(ctx, events.ProjectCreated, events.Ref{ID: p.ID, Version: p.Version})
     return nil
 }
 
-// UpdateProject
-func (s *Store) UpdateProject(id int, p *models.Project) error {
-    query := `
-        UPDATE public.projects
-        SET title=$2, description=$3, category=$4, status=$5, updated_at=$6
-        WHERE id=$1
-    `
-    p.UpdatedAt = time.Now()
-    if _, err := s.db.Exec(query, id, p.Title, p.Description, p.Category, p.Status, p.UpdatedAt); err != nil {
-        log.Printf("UpdateProject err: %v", err)
-        return err
+// PatchProject обновляет только переданные (не nil) поля, если версия записи равна version.
+func (s *Store) PatchProject(ctx context.Context, id, version int, p *models.UpdateProjectRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchProject")
+    defer span.End()
+
+    var cols []column
+    if p.Title != nil {
+        cols = append(cols, column{"title", *p.Title})
     }
-    return nil
+    if p.Description != nil {
+        cols = append(cols, column{"description", *p.Description})
+    }
+    if p.Category != nil {
+        cols = append(cols, column{"category", *p.Category})
+    }
+    if p.Status != nil {
+        cols = append(cols, column{"status", *p.Status})
+    }
+    v, err := s.updateColumns(ctx, "projects", id, version, cols, ErrProjectNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchProject", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.ProjectUpdated, events.Ref{ID: id, Version: v})
+    }
+    return v, err
 }
 
 // DeleteProject
-func (s *Store) DeleteProject(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.projects WHERE id=$1`, id); err != nil {
-        log.Printf("DeleteProject err: %v", err)
-        return err
+func (s *Store) DeleteProject(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteProject")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "projects", id, ErrProjectNotFound)
+    if err != nil && err != ErrProjectNotFound {
+        logError(ctx, "DeleteProject", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.ProjectDeleted, events.Ref{ID: id})
+    }
+    return err
 }
 
 // Stats
-func (s *Store) GetStats() (map[string]interface{}, error) {
+func (s *Store) GetStats(ctx context.Context) (map[string]interface{}, error) {
+    ctx, span := s.startOp(ctx, "GetStats")
+    defer span.End()
+
     stats := make(map[string]interface{})
 
     // fines last
@@ -676,7 +1253,7 @@ func (s *Store) GetStats() (map[string]interface{}, error) {
         SELECT violations_total, orders_total, fines_amount_total, collected_amount_total
         FROM public.fines ORDER BY date DESC LIMIT 1
     `
-    if err := s.db.QueryRow(fq).Scan(&f.ViolationsTotal, &f.OrdersTotal, &f.FinesAmountTotal, &f.CollectedAmountTotal); err == nil {
+    if err := s.db.QueryRowContext(ctx, fq).Scan(&f.ViolationsTotal, &f.OrdersTotal, &f.FinesAmountTotal, &f.CollectedAmountTotal); err == nil {
         stats["violations_total"] = f.ViolationsTotal
         stats["orders_total"] = f.OrdersTotal
         stats["fines_amount_total"] = f.FinesAmountTotal
@@ -689,7 +1266,7 @@ func (s *Store) GetStats() (map[string]interface{}, error) {
         SELECT evacuators_count, trips_count, evacuations_count, fine_lot_income
         FROM public.evacuations ORDER BY date DESC LIMIT 1
     `
-    if err := s.db.QueryRow(eq).Scan(&e.EvacuatorsCount, &e.TripsCount, &e.EvacuationsCount, &e.FineLotIncome); err == nil {
+    if err := s.db.QueryRowContext(ctx, eq).Scan(&e.EvacuatorsCount, &e.TripsCount, &e.EvacuationsCount, &e.FineLotIncome); err == nil {
         stats["evacuators_count"] = e.EvacuatorsCount
         stats["trips_count"] = e.TripsCount
         stats["evacuations_count"] = e.EvacuationsCount
@@ -698,21 +1275,37 @@ func (s *Store) GetStats() (map[string]interface{}, error) {
 
     // traffic lights count
     var tlActive int
-    if err := s.db.QueryRow(`SELECT COUNT(*) FROM public.traffic_lights WHERE status='active'`).Scan(&tlActive); err == nil {
+    if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM public.traffic_lights WHERE status='active'`).Scan(&tlActive); err == nil {
         stats["traffic_lights_active"] = tlActive
     }
 
     return stats, nil
 }
 
+// CountActiveTrafficLights — число светофоров в статусе active (для метрик).
+func (s *Store) CountActiveTrafficLights(ctx context.Context) (int, error) {
+    ctx, span := s.startOp(ctx, "CountActiveTrafficLights")
+    defer span.End()
+
+    var n int
+    if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM public.traffic_lights WHERE status='active'`).Scan(&n); err != nil {
+        logError(ctx, "CountActiveTrafficLights", err)
+        return 0, err
+    }
+    return n, nil
+}
+
 // Traffic
-func (s *Store) GetTraffic() (map[string]interface{}, error) {
+func (s *Store) GetTraffic(ctx context.Context) (map[string]interface{}, error) {
+    ctx, span := s.startOp(ctx, "GetTraffic")
+    defer span.End()
+
     res := make(map[string]interface{})
 
     // by type
     typeQuery := `SELECT light_type, COUNT(*) FROM public.traffic_lights GROUP BY light_type`
-    rows, err := s.db.Query(typeQuery)
-    if err != nil { log.Printf("GetTraffic types err: %v", err); return nil, err }
+    rows, err := s.db.QueryContext(ctx, typeQuery)
+    if err != nil { logError(ctx, "GetTraffic types", err); return nil, err }
     defer rows.Close()
 
     byType := make(map[string]int)
@@ -720,7 +1313,7 @@ func (s *Store) GetTraffic() (map[string]interface{}, error) {
         var lt string
         var c int
         if err := rows.Scan(&lt, &c); err != nil {
-            log.Printf("GetTraffic types scan err: %v", err)
+            logError(ctx, "GetTraffic types scan", err)
             continue
         }
         byType[lt] = c
@@ -729,7 +1322,7 @@ func (s *Store) GetTraffic() (map[string]interface{}, error) {
 
     // by year
     yearQuery := `SELECT install_year, COUNT(*) FROM public.traffic_lights GROUP BY install_year ORDER BY install_year DESC`
-    rows, err = s.db.Query(yearQuery)
+    rows, err = s.db.QueryContext(ctx, yearQuery)
     if err != nil {
         return res, nil
     }
@@ -739,7 +1332,7 @@ func (s *Store) GetTraffic() (map[string]interface{}, error) {
     for rows.Next() {
         var y, c int
         if err := rows.Scan(&y, &c); err != nil {
-            log.Printf("GetTraffic years scan err: %v", err)
+            logError(ctx, "GetTraffic years scan", err)
             continue
         }
         byYear[y] = c
@@ -750,20 +1343,24 @@ func (s *Store) GetTraffic() (map[string]interface{}, error) {
 }
 
 // Vacancies
-func (s *Store) GetVacancies() ([]models.Vacancy, error) {
+func (s *Store) GetVacancies(ctx context.Context) ([]models.Vacancy, error) {
+    ctx, span := s.startOp(ctx, "GetVacancies")
+    defer span.End()
+
     query := `
         SELECT id,
                position,
                experience,
                salary,
+               version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.vacancies
         ORDER BY id DESC
     `
-    rows, err := s.db.Query(query)
+    rows, err := s.db.QueryContext(ctx, query)
     if err != nil {
-        log.Printf("GetVacancies query err: %v", err)
+        logError(ctx, "GetVacancies query", err)
         return nil, err
     }
     defer rows.Close()
@@ -771,8 +1368,8 @@ func (s *Store) GetVacancies() ([]models.Vacancy, error) {
     var out []models.Vacancy
     for rows.Next() {
         var v models.Vacancy
-        if err := rows.Scan(&v.ID, &v.Position, &v.Experience, &v.Salary, &v.CreatedAt, &v.UpdatedAt); err != nil {
-            log.Printf("GetVacancies scan err: %v", err)
+        if err := rows.Scan(&v.ID, &v.Position, &v.Experience, &v.Salary, &v.Version, &v.CreatedAt, &v.UpdatedAt); err != nil {
+            logError(ctx, "GetVacancies scan", err)
             return nil, err
         }
         out = append(out, v)
@@ -780,30 +1377,37 @@ func (s *Store) GetVacancies() ([]models.Vacancy, error) {
     return out, nil
 }
 
-func (s *Store) GetVacancyByID(id int) (*models.Vacancy, error) {
+func (s *Store) GetVacancyByID(ctx context.Context, id int) (*models.Vacancy, error) {
+    ctx, span := s.startOp(ctx, "GetVacancyByID")
+    defer span.End()
+
     query := `
         SELECT id,
                position,
                experience,
                salary,
+               version,
                COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at,
                COALESCE(updated_at, CURRENT_TIMESTAMP) AS updated_at
         FROM public.vacancies
         WHERE id = $1
     `
     var v models.Vacancy
-    if err := s.db.QueryRow(query, id).Scan(&v.ID, &v.Position, &v.Experience, &v.Salary, &v.CreatedAt, &v.UpdatedAt); err != nil {
+    if err := s.db.QueryRowContext(ctx, query, id).Scan(&v.ID, &v.Position, &v.Experience, &v.Salary, &v.Version, &v.CreatedAt, &v.UpdatedAt); err != nil {
         if err == sql.ErrNoRows {
-            log.Printf("GetVacancyByID: vacancy id=%d not found", id)
-        } else {
-            log.Printf("GetVacancyByID err: %v", err)
+            logging.FromContext(ctx).Debug("GetVacancyByID: not found", "id", id)
+            return nil, ErrVacancyNotFound
         }
+        logError(ctx, "GetVacancyByID", err)
         return nil, err
     }
     return &v, nil
 }
 
-func (s *Store) CreateVacancy(v *models.Vacancy) error {
+func (s *Store) CreateVacancy(ctx context.Context, v *models.Vacancy) error {
+    ctx, span := s.startOp(ctx, "CreateVacancy")
+    defer span.End()
+
     query := `
         INSERT INTO public.vacancies (position, experience, salary, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5)
@@ -814,37 +1418,51 @@ func (s *Store) CreateVacancy(v *models.Vacancy) error {
     // Если у тебя *time.Time — устанавливаем адреса значений:
     v.CreatedAt = &now
     v.UpdatedAt = &now
+    v.Version = 1
 
-    if err := s.db.QueryRow(query, v.Position, v.Experience, v.Salary, v.CreatedAt, v.UpdatedAt).Scan(&v.ID); err != nil {
-        log.Printf("CreateVacancy err: %v", err)
+    if err := s.db.QueryRowContext(ctx, query, v.Position, v.Experience, v.Salary, v.CreatedAt, v.UpdatedAt).Scan(&v.ID); err != nil {
+        logError(ctx, "CreateVacancy", err)
         return err
     }
+    s.emit(ctx, events.VacancyCreated, events.Ref{ID: v.ID, Version: v.Version})
     return nil
 }
 
-func (s *Store) UpdateVacancy(id int, v *models.Vacancy) error {
-    query := `
-        UPDATE public.vacancies
-        SET position = $2,
-            experience = $3,
-            salary = $4,
-            updated_at = $5
-        WHERE id = $1
-    `
-    now := time.Now()
-    v.UpdatedAt = &now
+// PatchVacancy обновляет только переданные (не nil) поля, если версия записи равна version.
+func (s *Store) PatchVacancy(ctx context.Context, id, version int, p *models.UpdateVacancyRequest) (int, error) {
+    ctx, span := s.startOp(ctx, "PatchVacancy")
+    defer span.End()
 
-    if _, err := s.db.Exec(query, id, v.Position, v.Experience, v.Salary, v.UpdatedAt); err != nil {
-        log.Printf("UpdateVacancy err: %v", err)
-        return err
+    var cols []column
+    if p.Position != nil {
+        cols = append(cols, column{"position", *p.Position})
     }
-    return nil
+    if p.Experience != nil {
+        cols = append(cols, column{"experience", *p.Experience})
+    }
+    if p.Salary != nil {
+        cols = append(cols, column{"salary", *p.Salary})
+    }
+    v, err := s.updateColumns(ctx, "vacancies", id, version, cols, ErrVacancyNotFound)
+    if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+        logError(ctx, "PatchVacancy", err)
+    }
+    if err == nil {
+        s.emit(ctx, events.VacancyUpdated, events.Ref{ID: id, Version: v})
+    }
+    return v, err
 }
 
-func (s *Store) DeleteVacancy(id int) error {
-    if _, err := s.db.Exec(`DELETE FROM public.vacancies WHERE id = $1`, id); err != nil {
-        log.Printf("DeleteVacancy err: %v", err)
-        return err
+func (s *Store) DeleteVacancy(ctx context.Context, id int) error {
+    ctx, span := s.startOp(ctx, "DeleteVacancy")
+    defer span.End()
+
+    err := s.deleteByID(ctx, "vacancies", id, ErrVacancyNotFound)
+    if err != nil && err != ErrVacancyNotFound {
+        logError(ctx, "DeleteVacancy", err)
     }
-    return nil
+    if err == nil {
+        s.emit(ctx, events.VacancyDeleted, events.Ref{ID: id})
+    }
+    return err
 }
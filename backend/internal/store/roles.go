@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"backend/internal/apperr"
+	"backend/internal/models"
+)
+
+var (
+	ErrRoleNotFound      = apperr.NotFound("Role not found")
+	ErrRoleExists        = apperr.Conflict("Role already exists")
+	ErrRoleInUse         = apperr.Conflict("Role is assigned to users")
+	ErrUnknownPermission = apperr.BadRequest("Unknown permission")
+)
+
+// GetRoleMatrix — все роли и их права (для rbac.Cache).
+func (s *Store) GetRoleMatrix(ctx context.Context) (map[string][]string, error) {
+	ctx, span := s.startOp(ctx, "GetRoleMatrix")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.name, rp.permission
+		FROM public.roles r
+		LEFT JOIN public.role_permissions rp ON rp.role = r.name
+	`)
+	if err != nil {
+		logError(ctx, "GetRoleMatrix query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matrix := make(map[string][]string)
+	for rows.Next() {
+		var role string
+		var perm sql.NullString
+		if err := rows.Scan(&role, &perm); err != nil {
+			logError(ctx, "GetRoleMatrix scan", err)
+			return nil, err
+		}
+		if _, ok := matrix[role]; !ok {
+			matrix[role] = []string{}
+		}
+		if perm.Valid {
+			matrix[role] = append(matrix[role], perm.String)
+		}
+	}
+	return matrix, rows.Err()
+}
+
+// GetRoles — роли с описаниями и правами.
+func (s *Store) GetRoles(ctx context.Context) ([]models.Role, error) {
+	ctx, span := s.startOp(ctx, "GetRoles")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.name, r.description,
+		       COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM public.roles r
+		LEFT JOIN public.role_permissions rp ON rp.role = r.name
+		GROUP BY r.name, r.description
+		ORDER BY r.name
+	`)
+	if err != nil {
+		logError(ctx, "GetRoles query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Role
+	for rows.Next() {
+		var r models.Role
+		if err := rows.Scan(&r.Name, &r.Description, pq.Array(&r.Permissions)); err != nil {
+			logError(ctx, "GetRoles scan", err)
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+// GetPermissions — все известные права.
+func (s *Store) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	ctx, span := s.startOp(ctx, "GetPermissions")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT name, description FROM public.permissions ORDER BY name`)
+	if err != nil {
+		logError(ctx, "GetPermissions query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			logError(ctx, "GetPermissions scan", err)
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// CreateRole создаёт роль с набором прав.
+func (s *Store) CreateRole(ctx context.Context, r *models.Role) error {
+	ctx, span := s.startOp(ctx, "CreateRole")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO public.roles (name, description) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`,
+			r.Name, r.Description,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return ErrRoleExists
+		}
+		return replaceRolePermissions(ctx, tx, r.Name, r.Permissions)
+	})
+	if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+		logError(ctx, "CreateRole", err)
+	}
+	return err
+}
+
+// SetRolePermissions заменяет набор прав роли.
+func (s *Store) SetRolePermissions(ctx context.Context, role string, perms []string) error {
+	ctx, span := s.startOp(ctx, "SetRolePermissions")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM public.roles WHERE name = $1)`, role,
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoleNotFound
+		}
+		return replaceRolePermissions(ctx, tx, role, perms)
+	})
+	if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+		logError(ctx, "SetRolePermissions", err)
+	}
+	return err
+}
+
+// DeleteRole удаляет роль, если она никому не назначена.
+func (s *Store) DeleteRole(ctx context.Context, role string) error {
+	ctx, span := s.startOp(ctx, "DeleteRole")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var inUse bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM public.users WHERE role = $1)`, role,
+		).Scan(&inUse); err != nil {
+			return err
+		}
+		if inUse {
+			return ErrRoleInUse
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM public.roles WHERE name = $1`, role)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return ErrRoleNotFound
+		}
+		return nil
+	})
+	if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+		logError(ctx, "DeleteRole", err)
+	}
+	return err
+}
+
+func replaceRolePermissions(ctx context.Context, tx *sql.Tx, role string, perms []string) error {
+	var known int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM public.permissions WHERE name = ANY($1)`, pq.Array(perms),
+	).Scan(&known); err != nil {
+		return err
+	}
+	if known != len(uniqueStrings(perms)) {
+		return ErrUnknownPermission
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.role_permissions WHERE role = $1`, role); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO public.role_permissions (role, permission) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`,
+		role, pq.Array(perms),
+	)
+	return err
+}
+
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
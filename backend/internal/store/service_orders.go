@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/apperr"
+	"backend/internal/models"
+)
+
+var (
+	ErrOrderNotFound = apperr.NotFound("Order not found")
+	// ErrOrderClosed — заказ уже выполнен или отменён, менять его нельзя.
+	ErrOrderClosed = apperr.Conflict("Order is already done or cancelled")
+)
+
+const serviceOrderColumns = `id, number, service_id, service_title, service_category, price,
+	customer_name, phone, email, comment, status, assigned_to, created_at, updated_at`
+
+func scanServiceOrder(row interface{ Scan(...any) error }, o *models.ServiceOrder) error {
+	return row.Scan(&o.ID, &o.Number, &o.ServiceID, &o.ServiceTitle, &o.ServiceCategory, &o.Price,
+		&o.CustomerName, &o.Phone, &o.Email, &o.Comment, &o.Status, &o.AssignedTo, &o.CreatedAt, &o.UpdatedAt)
+}
+
+// CreateServiceOrder оформляет заказ услуги o.ServiceID: название, категория и цена
+// копируются из каталога в той же операции. ErrServiceNotFound — услуги нет.
+func (s *Store) CreateServiceOrder(ctx context.Context, o *models.ServiceOrder) error {
+	ctx, span := s.startOp(ctx, "CreateServiceOrder")
+	defer span.End()
+
+	now := time.Now()
+	o.Status, o.CreatedAt, o.UpdatedAt = models.OrderNew, now, now
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO service_orders (number, service_id, service_title, service_category, price,
+                                     customer_name, phone, email, comment, status, created_at, updated_at)
+         SELECT $1, id, title, category, price, $3, $4, $5, $6, $7, $8, $8
+         FROM services WHERE id = $2
+         RETURNING id, service_title, service_category, price`,
+		o.Number, *o.ServiceID, o.CustomerName, o.Phone, o.Email, o.Comment, o.Status, now,
+	).Scan(&o.ID, &o.ServiceTitle, &o.ServiceCategory, &o.Price)
+	if err == sql.ErrNoRows {
+		return ErrServiceNotFound
+	}
+	if err != nil {
+		logError(ctx, "CreateServiceOrder", err)
+	}
+	return err
+}
+
+// GetServiceOrderByID — заказ для сотрудника.
+func (s *Store) GetServiceOrderByID(ctx context.Context, id int) (*models.ServiceOrder, error) {
+	ctx, span := s.startOp(ctx, "GetServiceOrderByID")
+	defer span.End()
+
+	var o models.ServiceOrder
+	err := scanServiceOrder(s.db.QueryRowContext(ctx, `SELECT `+serviceOrderColumns+` FROM service_orders WHERE id = $1`, id), &o)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		logError(ctx, "GetServiceOrderByID", err)
+		return nil, err
+	}
+	return &o, nil
+}
+
+// GetServiceOrderByNumber — заказ по номеру, который получил заказчик.
+func (s *Store) GetServiceOrderByNumber(ctx context.Context, number string) (*models.ServiceOrder, error) {
+	ctx, span := s.startOp(ctx, "GetServiceOrderByNumber")
+	defer span.End()
+
+	var o models.ServiceOrder
+	err := scanServiceOrder(s.db.QueryRowContext(ctx, `SELECT `+serviceOrderColumns+` FROM service_orders WHERE number = $1`, number), &o)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		logError(ctx, "GetServiceOrderByNumber", err)
+		return nil, err
+	}
+	return &o, nil
+}
+
+// orderFilterSQL собирает WHERE по фильтру; условия — только из кода, значения — параметрами.
+func orderFilterSQL(f *models.ServiceOrderFilter) (string, []any) {
+	var where []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if f.Status != "" {
+		add("status = ?", f.Status)
+	}
+	if f.Category != "" {
+		add("lower(service_category) = lower(?)", f.Category)
+	}
+	if f.ServiceID != 0 {
+		add("service_id = ?", f.ServiceID)
+	}
+	if f.Unassigned {
+		where = append(where, "assigned_to IS NULL")
+	} else if f.AssignedTo != 0 {
+		add("assigned_to = ?", f.AssignedTo)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < ?", f.To)
+	}
+	if len(where) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(where, " AND "), args
+}
+
+// GetServiceOrders — заказы по фильтру, новые первыми.
+func (s *Store) GetServiceOrders(ctx context.Context, f *models.ServiceOrderFilter, limit int) ([]models.ServiceOrder, error) {
+	ctx, span := s.startOp(ctx, "GetServiceOrders")
+	defer span.End()
+
+	where, args := orderFilterSQL(f)
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+serviceOrderColumns+`
+		FROM service_orders
+		`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		logError(ctx, "GetServiceOrders query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.ServiceOrder{}
+	for rows.Next() {
+		var o models.ServiceOrder
+		if err := scanServiceOrder(rows, &o); err != nil {
+			logError(ctx, "GetServiceOrders scan", err)
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, rows.Err()
+}
+
+// GetServiceOrderTotals — итоги по категориям услуг для того же фильтра (без лимита):
+// число заказов и сумма по ценам на момент заказа без отменённых.
+func (s *Store) GetServiceOrderTotals(ctx context.Context, f *models.ServiceOrderFilter) ([]models.OrderTotals, error) {
+	ctx, span := s.startOp(ctx, "GetServiceOrderTotals")
+	defer span.End()
+
+	where, args := orderFilterSQL(f)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT service_category, COUNT(*), COALESCE(SUM(price) FILTER (WHERE status <> 'cancelled'), 0)
+		FROM service_orders
+		`+where+`
+		GROUP BY service_category
+		ORDER BY service_category`, args...)
+	if err != nil {
+		logError(ctx, "GetServiceOrderTotals query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.OrderTotals{}
+	for rows.Next() {
+		var t models.OrderTotals
+		if err := rows.Scan(&t.Category, &t.Count, &t.Amount); err != nil {
+			logError(ctx, "GetServiceOrderTotals scan", err)
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// updateOpenOrder блокирует заказ, передаёт его текущий статус в check и, если тот
+// не вернул ошибку, выполняет UPDATE с set (параметр $1 — id, дальше — args).
+func (s *Store) updateOpenOrder(ctx context.Context, id int, check func(status string) error, set string, args ...any) (*models.ServiceOrder, error) {
+	var o models.ServiceOrder
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM service_orders WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+		if err == sql.ErrNoRows {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := check(status); err != nil {
+			return err
+		}
+		return scanServiceOrder(tx.QueryRowContext(ctx,
+			`UPDATE service_orders SET `+set+` WHERE id = $1 RETURNING `+serviceOrderColumns,
+			append([]any{id}, args...)...), &o)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// SetServiceOrderStatus переводит заказ в новый статус по models.CanOrderTransition.
+// ErrOrderClosed — заказ уже выполнен или отменён; недопустимый переход — Conflict.
+func (s *Store) SetServiceOrderStatus(ctx context.Context, id int, status string) (*models.ServiceOrder, error) {
+	ctx, span := s.startOp(ctx, "SetServiceOrderStatus")
+	defer span.End()
+
+	o, err := s.updateOpenOrder(ctx, id, func(from string) error {
+		if from == models.OrderDone || from == models.OrderCancelled {
+			return ErrOrderClosed
+		}
+		if !models.CanOrderTransition(from, status) {
+			return apperr.Conflict("Order cannot change status from "+from+" to "+status).
+				With("from", from).With("to", status)
+		}
+		return nil
+	}, `status = $2, updated_at = $3`, status, time.Now())
+	if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+		logError(ctx, "SetServiceOrderStatus", err)
+	}
+	return o, err
+}
+
+// AssignServiceOrder назначает исполнителя заказа (nil — снять назначение).
+// Закрытые заказы не переназначаются: ErrOrderClosed.
+func (s *Store) AssignServiceOrder(ctx context.Context, id int, userID *int) (*models.ServiceOrder, error) {
+	ctx, span := s.startOp(ctx, "AssignServiceOrder")
+	defer span.End()
+
+	o, err := s.updateOpenOrder(ctx, id, func(status string) error {
+		if status == models.OrderDone || status == models.OrderCancelled {
+			return ErrOrderClosed
+		}
+		return nil
+	}, `assigned_to = $2, updated_at = $3`, userID, time.Now())
+	if err != nil && apperr.KindOf(err) == apperr.KindInternal {
+		logError(ctx, "AssignServiceOrder", err)
+	}
+	return o, err
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"backend/internal/apperr"
+	"backend/internal/models"
+)
+
+// ErrSessionNotFound — сессии нет, она чужая или уже отозвана.
+var ErrSessionNotFound = apperr.NotFound("Session not found")
+
+// CreateSession сохраняет новую сессию.
+func (s *Store) CreateSession(ctx context.Context, sess *models.Session) error {
+	ctx, span := s.startOp(ctx, "CreateSession")
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, user_id, ip, user_agent, created_at, last_seen_at, expires_at)
+         VALUES ($1, $2, $3, $4, $5, $5, $6)`,
+		sess.ID, sess.UserID, sess.IP, sess.UserAgent, sess.CreatedAt, sess.ExpiresAt,
+	)
+	if err != nil {
+		logError(ctx, "CreateSession", err)
+	}
+	return err
+}
+
+// GetUserSessions — действующие (не отозванные и не истёкшие) сессии пользователя, новые первыми.
+func (s *Store) GetUserSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	ctx, span := s.startOp(ctx, "GetUserSessions")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`, userID, time.Now())
+	if err != nil {
+		logError(ctx, "GetUserSessions query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.Session{}
+	for rows.Next() {
+		var sess models.Session
+		if err := rows.Scan(&sess.ID, &sess.UserID, &sess.IP, &sess.UserAgent,
+			&sess.CreatedAt, &sess.LastSeenAt, &sess.ExpiresAt, &sess.RevokedAt); err != nil {
+			logError(ctx, "GetUserSessions scan", err)
+			return nil, err
+		}
+		res = append(res, sess)
+	}
+	return res, rows.Err()
+}
+
+// CheckSession сообщает, действует ли сессия, и обновляет last_seen_at,
+// если с прошлого обновления прошло больше touchInterval (чтобы не писать в БД на каждый запрос).
+func (s *Store) CheckSession(ctx context.Context, id string, touchInterval time.Duration) (bool, error) {
+	ctx, span := s.startOp(ctx, "CheckSession")
+	defer span.End()
+
+	now := time.Now()
+	var active bool
+	var lastSeen time.Time
+	err := s.db.QueryRowContext(ctx,
+		`SELECT revoked_at IS NULL AND expires_at > $2, last_seen_at FROM sessions WHERE id = $1`,
+		id, now,
+	).Scan(&active, &lastSeen)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		logError(ctx, "CheckSession", err)
+		return false, err
+	}
+
+	if active && now.Sub(lastSeen) > touchInterval {
+		if _, err := s.db.ExecContext(ctx,
+			`UPDATE sessions SET last_seen_at = $1 WHERE id = $2`, now, id,
+		); err != nil {
+			// Не повод отказывать в запросе: last_seen_at обновится в следующий раз.
+			logError(ctx, "CheckSession touch", err)
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession отзывает действующую сессию пользователя.
+func (s *Store) RevokeSession(ctx context.Context, userID int, id string) error {
+	ctx, span := s.startOp(ctx, "RevokeSession")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		logError(ctx, "RevokeSession", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions отзывает все действующие сессии пользователя и возвращает их число.
+func (s *Store) RevokeUserSessions(ctx context.Context, userID int) (int, error) {
+	ctx, span := s.startOp(ctx, "RevokeUserSessions")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		logError(ctx, "RevokeUserSessions", err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
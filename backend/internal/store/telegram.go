@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+)
+
+// AddTelegramSubscription подписывает чат; false — такая подписка уже есть.
+func (s *Store) AddTelegramSubscription(ctx context.Context, chatID int64, kind, tag string) (bool, error) {
+	ctx, span := s.startOp(ctx, "AddTelegramSubscription")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO telegram_subscriptions (chat_id, kind, tag, created_at) VALUES ($1, $2, $3, $4)
+         ON CONFLICT (chat_id, kind, lower(tag)) DO NOTHING`,
+		chatID, kind, tag, time.Now(),
+	)
+	if err != nil {
+		logError(ctx, "AddTelegramSubscription", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RemoveTelegramSubscription отписывает чат; false — такой подписки не было.
+func (s *Store) RemoveTelegramSubscription(ctx context.Context, chatID int64, kind, tag string) (bool, error) {
+	ctx, span := s.startOp(ctx, "RemoveTelegramSubscription")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM telegram_subscriptions WHERE chat_id = $1 AND kind = $2 AND lower(tag) = lower($3)`,
+		chatID, kind, tag,
+	)
+	if err != nil {
+		logError(ctx, "RemoveTelegramSubscription", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteTelegramChat удаляет все подписки чата (отписка от всего или бот заблокирован).
+func (s *Store) DeleteTelegramChat(ctx context.Context, chatID int64) (int64, error) {
+	ctx, span := s.startOp(ctx, "DeleteTelegramChat")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM telegram_subscriptions WHERE chat_id = $1`, chatID)
+	if err != nil {
+		logError(ctx, "DeleteTelegramChat", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetTelegramSubscriptions — подписки чата.
+func (s *Store) GetTelegramSubscriptions(ctx context.Context, chatID int64) ([]models.TelegramSubscription, error) {
+	ctx, span := s.startOp(ctx, "GetTelegramSubscriptions")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT chat_id, kind, tag, created_at FROM telegram_subscriptions WHERE chat_id = $1 ORDER BY kind, tag`,
+		chatID,
+	)
+	if err != nil {
+		logError(ctx, "GetTelegramSubscriptions query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.TelegramSubscription
+	for rows.Next() {
+		var sub models.TelegramSubscription
+		if err := rows.Scan(&sub.ChatID, &sub.Kind, &sub.Tag, &sub.CreatedAt); err != nil {
+			logError(ctx, "GetTelegramSubscriptions scan", err)
+			return nil, err
+		}
+		res = append(res, sub)
+	}
+	return res, rows.Err()
+}
+
+// GetTelegramSubscribers — чаты, которым нужно отправить уведомление: для новостей —
+// подписанные на все новости и на её тег (без учёта регистра), для маршрутов tag не важен.
+func (s *Store) GetTelegramSubscribers(ctx context.Context, kind, tag string) ([]int64, error) {
+	ctx, span := s.startOp(ctx, "GetTelegramSubscribers")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT DISTINCT chat_id FROM telegram_subscriptions
+         WHERE kind = $1 AND (tag = '' OR lower(tag) = lower($2))
+         ORDER BY chat_id`,
+		kind, tag,
+	)
+	if err != nil {
+		logError(ctx, "GetTelegramSubscribers query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			logError(ctx, "GetTelegramSubscribers scan", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
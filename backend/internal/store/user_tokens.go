@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"backend/internal/apperr"
+	"backend/internal/models"
+)
+
+// Назначения одноразовых токенов из писем.
+const (
+	TokenPasswordReset = "password_reset"
+	TokenInvite        = "invite"
+)
+
+// ErrInvalidToken — токена нет, он использован или истёк.
+var ErrInvalidToken = apperr.BadRequest("Invalid or expired link")
+
+// CreateUserToken сохраняет хэш нового токена; прежние неиспользованные токены
+// того же назначения перестают действовать.
+func (s *Store) CreateUserToken(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	ctx, span := s.startOp(ctx, "CreateUserToken")
+	defer span.End()
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return insertUserToken(ctx, tx, userID, purpose, tokenHash, expiresAt)
+	})
+	if err != nil {
+		logError(ctx, "CreateUserToken", err)
+	}
+	return err
+}
+
+// CreateInvitedUser заводит приглашённого пользователя без пароля и токен приглашения.
+// Повторное приглашение того, кто ещё не задал пароль, обновляет роль и выдаёт новый токен;
+// если email занят действующей учётной записью — ErrEmailTaken.
+func (s *Store) CreateInvitedUser(ctx context.Context, email, role, tokenHash string, expiresAt time.Time) (*models.User, error) {
+	ctx, span := s.startOp(ctx, "CreateInvitedUser")
+	defer span.End()
+
+	user := &models.User{}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		err := scanUser(tx.QueryRowContext(ctx,
+			`INSERT INTO users (email, password, role, is_active) VALUES ($1, '', $2, true)
+             ON CONFLICT (email) DO UPDATE SET role = EXCLUDED.role, updated_at = CURRENT_TIMESTAMP
+             WHERE users.password = '' AND users.oidc_subject IS NULL
+             RETURNING `+userColumns,
+			email, role,
+		), user)
+		if err == sql.ErrNoRows {
+			return ErrEmailTaken
+		}
+		if err != nil {
+			return err
+		}
+		return insertUserToken(ctx, tx, user.ID, TokenInvite, tokenHash, expiresAt)
+	})
+	if err != nil {
+		if apperr.KindOf(err) == apperr.KindInternal {
+			logError(ctx, "CreateInvitedUser", err)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByToken — владелец действующего токена (без погашения токена).
+func (s *Store) GetUserByToken(ctx context.Context, purpose, tokenHash string) (*models.User, error) {
+	ctx, span := s.startOp(ctx, "GetUserByToken")
+	defer span.End()
+
+	user := &models.User{}
+	err := scanUser(s.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = (
+             SELECT user_id FROM user_tokens
+             WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+         )`,
+		tokenHash, purpose, time.Now(),
+	), user)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		logError(ctx, "GetUserByToken", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetPasswordWithToken гасит токен и задаёт пароль его владельцу. Заодно снимается
+// блокировка входа и отзываются все сессии: тот, кто знал старый пароль, выходит.
+func (s *Store) SetPasswordWithToken(ctx context.Context, purpose, tokenHash, passwordHash string) (int, error) {
+	ctx, span := s.startOp(ctx, "SetPasswordWithToken")
+	defer span.End()
+
+	var userID int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now()
+		err := tx.QueryRowContext(ctx,
+			`UPDATE user_tokens SET used_at = $1
+             WHERE token_hash = $2 AND purpose = $3 AND used_at IS NULL AND expires_at > $1
+             RETURNING user_id`,
+			now, tokenHash, purpose,
+		).Scan(&userID)
+		if err == sql.ErrNoRows {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET password = $1, failed_logins = 0, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+             WHERE id = $2`,
+			passwordHash, userID,
+		); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`, now, userID,
+		)
+		return err
+	})
+	if err != nil {
+		if apperr.KindOf(err) == apperr.KindInternal {
+			logError(ctx, "SetPasswordWithToken", err)
+		}
+		return 0, err
+	}
+	return userID, nil
+}
+
+func insertUserToken(ctx context.Context, tx *sql.Tx, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE user_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL`,
+		time.Now(), userID, purpose,
+	); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)`,
+		userID, purpose, tokenHash, expiresAt,
+	)
+	return err
+}
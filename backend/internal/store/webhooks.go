@@ -0,0 +1,303 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"backend/internal/apperr"
+	"backend/internal/events"
+	"backend/internal/models"
+)
+
+// Ошибки вебхуков.
+var (
+	ErrWebhookNotFound         = apperr.NotFound("Webhook not found")
+	ErrWebhookDeliveryNotFound = apperr.NotFound("Webhook delivery not found")
+)
+
+const webhookColumns = `id, url, events, description, active, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...any) error }, w *models.Webhook) error {
+	return row.Scan(&w.ID, &w.URL, pq.Array(&w.Events), &w.Description, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+}
+
+// GetWebhooks — все подписки (без секретов).
+func (s *Store) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, span := s.startOp(ctx, "GetWebhooks")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		logError(ctx, "GetWebhooks query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.Webhook{}
+	for rows.Next() {
+		var w models.Webhook
+		if err := scanWebhook(rows, &w); err != nil {
+			logError(ctx, "GetWebhooks scan", err)
+			return nil, err
+		}
+		res = append(res, w)
+	}
+	return res, rows.Err()
+}
+
+// GetWebhookByID — подписка без секрета.
+func (s *Store) GetWebhookByID(ctx context.Context, id int) (*models.Webhook, error) {
+	ctx, span := s.startOp(ctx, "GetWebhookByID")
+	defer span.End()
+
+	var w models.Webhook
+	err := scanWebhook(s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id), &w)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		logError(ctx, "GetWebhookByID", err)
+		return nil, err
+	}
+	return &w, nil
+}
+
+// CreateWebhook сохраняет подписку вместе с секретом.
+func (s *Store) CreateWebhook(ctx context.Context, w *models.Webhook) error {
+	ctx, span := s.startOp(ctx, "CreateWebhook")
+	defer span.End()
+
+	now := time.Now()
+	w.CreatedAt, w.UpdatedAt = now, now
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO webhooks (url, secret, events, description, active, created_at, updated_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING id`,
+		w.URL, w.Secret, pq.Array(w.Events), w.Description, w.Active, now,
+	).Scan(&w.ID)
+	if err != nil {
+		logError(ctx, "CreateWebhook", err)
+	}
+	return err
+}
+
+// UpdateWebhook сохраняет адрес, фильтры, описание и активность подписки (секрет не меняется).
+func (s *Store) UpdateWebhook(ctx context.Context, w *models.Webhook) error {
+	ctx, span := s.startOp(ctx, "UpdateWebhook")
+	defer span.End()
+
+	w.UpdatedAt = time.Now()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE webhooks SET url = $2, events = $3, description = $4, active = $5, updated_at = $6 WHERE id = $1`,
+		w.ID, w.URL, pq.Array(w.Events), w.Description, w.Active, w.UpdatedAt,
+	)
+	if err != nil {
+		logError(ctx, "UpdateWebhook", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// SetWebhookSecret заменяет ключ подписи; ожидающие доставки подписываются уже новым.
+func (s *Store) SetWebhookSecret(ctx context.Context, id int, secret string) error {
+	ctx, span := s.startOp(ctx, "SetWebhookSecret")
+	defer span.End()
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE webhooks SET secret = $2, updated_at = $3 WHERE id = $1`, id, secret, time.Now(),
+	)
+	if err != nil {
+		logError(ctx, "SetWebhookSecret", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её журналом доставок.
+func (s *Store) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := s.startOp(ctx, "DeleteWebhook")
+	defer span.End()
+
+	err := s.deleteByID(ctx, "webhooks", id, ErrWebhookNotFound)
+	if err != nil && err != ErrWebhookNotFound {
+		logError(ctx, "DeleteWebhook", err)
+	}
+	return err
+}
+
+// enqueueWebhooks ставит событие в очередь доставки всем активным подпискам с подходящим фильтром.
+// Вызывается из emit на той реплике, где произошла запись, — поэтому ровно один раз на событие.
+func (s *Store) enqueueWebhooks(ctx context.Context, e events.Event, payload []byte) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, events FROM webhooks WHERE active`)
+	if err != nil {
+		logError(ctx, "enqueueWebhooks query", err)
+		return
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var filters []string
+		if err := rows.Scan(&id, pq.Array(&filters)); err != nil {
+			rows.Close()
+			logError(ctx, "enqueueWebhooks scan", err)
+			return
+		}
+		for _, f := range filters {
+			if events.Match(f, e.Type) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at, created_at)
+         SELECT unnest($1::int[]), $2, $3, $4, $5, $5`,
+		pq.Array(ids), e.ID, e.Type, string(payload), time.Now(),
+	); err != nil {
+		logError(ctx, "enqueueWebhooks insert", err)
+	}
+}
+
+// ClaimWebhookDeliveries берёт в работу до limit доставок, время которых подошло, у активных подписок.
+// Взятые доставки откладываются до now+lease: если реплика упадёт посреди отправки,
+// их заберёт кто-то другой. FOR UPDATE SKIP LOCKED — реплики не берут одну доставку дважды.
+func (s *Store) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookJob, error) {
+	ctx, span := s.startOp(ctx, "ClaimWebhookDeliveries")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT d.id FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND w.active
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT $3
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = $2
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.id, d.event_type, d.payload, d.attempts, w.url, w.secret
+	`, now, now.Add(lease), limit)
+	if err != nil {
+		logError(ctx, "ClaimWebhookDeliveries query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.WebhookJob
+	for rows.Next() {
+		var j models.WebhookJob
+		if err := rows.Scan(&j.DeliveryID, &j.EventType, &j.Payload, &j.Attempts, &j.URL, &j.Secret); err != nil {
+			logError(ctx, "ClaimWebhookDeliveries scan", err)
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// RecordWebhookAttempt записывает итог попытки доставки.
+func (s *Store) RecordWebhookAttempt(ctx context.Context, id int64, a models.WebhookAttempt) error {
+	ctx, span := s.startOp(ctx, "RecordWebhookAttempt")
+	defer span.End()
+
+	var responseStatus, nextAttemptAt, finishedAt interface{}
+	if a.ResponseStatus != 0 {
+		responseStatus = a.ResponseStatus
+	}
+	if a.Status == models.DeliveryPending {
+		nextAttemptAt = a.NextAttemptAt
+	} else {
+		finishedAt = a.At
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_attempt_at = $3, next_attempt_at = COALESCE($4, next_attempt_at),
+		    response_status = $5, last_error = $6, finished_at = $7
+		WHERE id = $1
+	`, id, a.Status, a.At, nextAttemptAt, responseStatus, a.Error, finishedAt)
+	if err != nil {
+		logError(ctx, "RecordWebhookAttempt", err)
+	}
+	return err
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_attempt_at, response_status, last_error, replay_of, created_at, finished_at`
+
+func scanDelivery(row interface{ Scan(...any) error }, d *models.WebhookDelivery) error {
+	var payload string
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.LastError, &d.ReplayOf,
+		&d.CreatedAt, &d.FinishedAt); err != nil {
+		return err
+	}
+	d.Payload = []byte(payload)
+	return nil
+}
+
+// GetWebhookDeliveries — журнал доставок подписки, новые первыми; status пустой — все.
+func (s *Store) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	ctx, span := s.startOp(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, webhookID, status, limit)
+	if err != nil {
+		logError(ctx, "GetWebhookDeliveries query", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			logError(ctx, "GetWebhookDeliveries scan", err)
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+// ReplayWebhookDelivery ставит в очередь новую доставку с тем же событием (исходная остаётся в журнале).
+func (s *Store) ReplayWebhookDelivery(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	ctx, span := s.startOp(ctx, "ReplayWebhookDelivery")
+	defer span.End()
+
+	var d models.WebhookDelivery
+	err := scanDelivery(s.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at, created_at, replay_of)
+		SELECT webhook_id, event_id, event_type, payload, $3, $3, id
+		FROM webhook_deliveries WHERE id = $2 AND webhook_id = $1
+		RETURNING `+deliveryColumns,
+		webhookID, deliveryID, time.Now(),
+	), &d)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		logError(ctx, "ReplayWebhookDelivery", err)
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,455 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/events"
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/models"
+)
+
+// sendInterval — пауза между сообщениями рассылки: Telegram допускает около 30 сообщений в секунду.
+const sendInterval = 40 * time.Millisecond
+
+// Задержка повторного getUpdates после ошибки: растёт вдвое до pollRetryMax.
+const (
+	pollRetryBase = time.Second
+	pollRetryMax  = time.Minute
+)
+
+// Store — данные, которые нужны боту.
+type Store interface {
+	AddTelegramSubscription(ctx context.Context, chatID int64, kind, tag string) (bool, error)
+	RemoveTelegramSubscription(ctx context.Context, chatID int64, kind, tag string) (bool, error)
+	DeleteTelegramChat(ctx context.Context, chatID int64) (int64, error)
+	GetTelegramSubscriptions(ctx context.Context, chatID int64) ([]models.TelegramSubscription, error)
+	GetTelegramSubscribers(ctx context.Context, kind, tag string) ([]int64, error)
+	GetNewsTags(ctx context.Context) ([]string, error)
+	GetEvacuationRoutes(ctx context.Context) ([]models.EvacuationRoute, error)
+	GetStats(ctx context.Context) (map[string]interface{}, error)
+}
+
+// Bot отвечает на команды и рассылает уведомления подписчикам.
+type Bot struct {
+	client      Client
+	store       Store
+	bus         *events.Bus
+	appURL      string
+	pollTimeout time.Duration
+}
+
+// NewBot создаёт бота; appURL — адрес сайта для ссылок на новости.
+func NewBot(client Client, store Store, bus *events.Bus, appURL string, pollTimeout time.Duration) *Bot {
+	return &Bot{
+		client:      client,
+		store:       store,
+		bus:         bus,
+		appURL:      strings.TrimRight(appURL, "/"),
+		pollTimeout: pollTimeout,
+	}
+}
+
+// Run принимает команды и рассылает уведомления, пока не отменён ctx.
+func (b *Bot) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.notify(ctx)
+	}()
+	b.poll(ctx)
+	wg.Wait()
+}
+
+// poll получает сообщения через long polling getUpdates.
+func (b *Bot) poll(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	var offset int64
+	retry := pollRetryBase
+	for ctx.Err() == nil {
+		updates, err := b.client.GetUpdates(ctx, offset, b.pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait := retry
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			log.Warn("Telegram getUpdates failed", "err", err, "retry_in", wait)
+			retry = min(retry*2, pollRetryMax)
+			sleep(ctx, wait)
+			continue
+		}
+		retry = pollRetryBase
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message != nil && u.Message.Text != "" {
+				b.handle(ctx, u.Message)
+			}
+		}
+	}
+}
+
+// notify рассылает уведомления о событиях шины. Если шина отключила бота (рассылка
+// не успевала за событиями), пропущенное дочитывается из её истории.
+func (b *Bot) notify(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	var last int64
+	for {
+		sub, missed, complete := b.bus.Subscribe(last)
+		if !complete {
+			log.Warn("Telegram notifications skipped: events are no longer in history", "after_event_id", last)
+		}
+		for _, e := range missed {
+			b.notifyEvent(ctx, e)
+			last = e.ID
+		}
+	read:
+		for {
+			select {
+			case <-ctx.Done():
+				b.bus.Unsubscribe(sub)
+				return
+			case e, ok := <-sub.C:
+				if !ok {
+					break read
+				}
+				b.notifyEvent(ctx, e)
+				last = e.ID
+			}
+		}
+		// Шина закрыта — сервер останавливается; иначе она отключила бота, и он переподписывается.
+		if b.bus.Closed() || !sleep(ctx, time.Second) {
+			return
+		}
+	}
+}
+
+func (b *Bot) notifyEvent(ctx context.Context, e events.Event) {
+	var kind, tag, text string
+	switch e.Type {
+	case events.NewsCreated:
+		var n events.NewsRef
+		if err := json.Unmarshal(e.Data, &n); err != nil {
+			return
+		}
+		kind, tag = models.TelegramNews, n.Tag
+		text = fmt.Sprintf("📰 %s\n#%s\n%s/news/%d", n.Title, strings.ReplaceAll(n.Tag, " ", "_"), b.appURL, n.ID)
+	case events.EvacuationRouteCreated:
+		var r events.RouteRef
+		if err := json.Unmarshal(e.Data, &r); err != nil {
+			return
+		}
+		kind = models.TelegramRoutes
+		text = fmt.Sprintf("🚧 Маршрут эвакуации — %s %d:\n%s", r.Month, r.Year, r.Route)
+	default:
+		return
+	}
+
+	chats, err := b.store.GetTelegramSubscribers(ctx, kind, tag)
+	if err != nil {
+		logging.FromContext(ctx).Error("Telegram subscribers not loaded", "event_id", e.ID, "err", err)
+		return
+	}
+	for i, chatID := range chats {
+		if i > 0 && !sleep(ctx, sendInterval) {
+			return
+		}
+		b.send(ctx, chatID, text, "notification")
+	}
+}
+
+// send отправляет сообщение; при 429 ждёт и повторяет один раз,
+// а чат, который заблокировал бота, лишается подписок.
+func (b *Bot) send(ctx context.Context, chatID int64, text, typ string) {
+	err := b.client.SendMessage(ctx, chatID, text)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 && sleep(ctx, apiErr.RetryAfter) {
+		err = b.client.SendMessage(ctx, chatID, text)
+	}
+	if err == nil {
+		metrics.TelegramMessages.WithLabelValues(typ, "sent").Inc()
+		return
+	}
+	metrics.TelegramMessages.WithLabelValues(typ, "failed").Inc()
+
+	log := logging.FromContext(ctx).With("chat_id", chatID)
+	if IsBlocked(err) {
+		if _, err := b.store.DeleteTelegramChat(ctx, chatID); err == nil {
+			log.Info("Telegram chat unavailable, subscriptions removed")
+		}
+		return
+	}
+	log.Warn("Telegram message not sent", "err", err)
+}
+
+// handle отвечает на сообщение из чата.
+func (b *Bot) handle(ctx context.Context, m *Message) {
+	cmd, args := parseCommand(m.Text)
+	if cmd == "" && m.Chat.Type != "private" {
+		// В группах бот отвечает только на команды.
+		return
+	}
+	reply, err := b.command(ctx, m.Chat.ID, cmd, args)
+	if err != nil {
+		logging.FromContext(ctx).Error("Telegram command failed", "command", cmd, "chat_id", m.Chat.ID, "err", err)
+		reply = "Не получилось выполнить команду, попробуйте позже."
+	}
+	b.send(ctx, m.Chat.ID, reply, "reply")
+}
+
+const helpText = `Бот ЦОДД Смоленска: новости и маршруты эвакуации.
+
+/subscribe news — все новости
+/subscribe news <тег> — новости с тегом
+/subscribe routes — маршруты эвакуации на месяц
+/unsubscribe … — отписаться (так же, как подписка), /unsubscribe all — от всего
+/subscriptions — мои подписки
+/tags — теги новостей
+/routes — маршруты эвакуации на текущий месяц
+/stats — статистика ЦОДД`
+
+func (b *Bot) command(ctx context.Context, chatID int64, cmd, args string) (string, error) {
+	switch cmd {
+	case "/start", "/help":
+		return helpText, nil
+	case "/subscribe":
+		return b.subscribe(ctx, chatID, args)
+	case "/unsubscribe":
+		return b.unsubscribe(ctx, chatID, args)
+	case "/subscriptions":
+		return b.subscriptions(ctx, chatID)
+	case "/tags":
+		tags, err := b.store.GetNewsTags(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(tags) == 0 {
+			return "Новостей с тегами пока нет.", nil
+		}
+		return "Теги новостей:\n" + strings.Join(tags, "\n"), nil
+	case "/routes":
+		return b.routes(ctx)
+	case "/stats":
+		return b.stats(ctx)
+	case "":
+		return "Чтобы узнать, что умеет бот, отправьте /help.", nil
+	default:
+		return "Неизвестная команда. Список команд — /help.", nil
+	}
+}
+
+// parseCommand отделяет команду от аргументов; /cmd@bot_name (команда в группе) — то же, что /cmd.
+func parseCommand(text string) (cmd, args string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", text
+	}
+	cmd, args, _ = strings.Cut(text, " ")
+	cmd, _, _ = strings.Cut(cmd, "@")
+	return strings.ToLower(cmd), strings.TrimSpace(args)
+}
+
+// subscriptionArgs разбирает "news [тег]" или "routes".
+func subscriptionArgs(args string) (kind, tag string, ok bool) {
+	kind, tag, _ = strings.Cut(args, " ")
+	kind, tag = strings.ToLower(kind), strings.TrimSpace(tag)
+	switch kind {
+	case models.TelegramNews:
+		return kind, tag, true
+	case models.TelegramRoutes:
+		return kind, "", tag == ""
+	}
+	return "", "", false
+}
+
+func (b *Bot) subscribe(ctx context.Context, chatID int64, args string) (string, error) {
+	kind, tag, ok := subscriptionArgs(args)
+	if !ok {
+		return "Использование: /subscribe news, /subscribe news <тег> или /subscribe routes. Теги — /tags.", nil
+	}
+	known := true
+	if tag != "" {
+		tags, err := b.store.GetNewsTags(ctx)
+		if err != nil {
+			return "", err
+		}
+		// Тег сохраняется так, как он написан в новостях.
+		known = false
+		for _, t := range tags {
+			if strings.EqualFold(t, tag) {
+				tag, known = t, true
+				break
+			}
+		}
+	}
+	added, err := b.store.AddTelegramSubscription(ctx, chatID, kind, tag)
+	if err != nil {
+		return "", err
+	}
+	what := describe(kind, tag)
+	if !added {
+		return "Вы уже подписаны: " + what + ".", nil
+	}
+	if !known {
+		return "Подписка оформлена: " + what + ". Новостей с таким тегом пока не было — проверьте написание в /tags.", nil
+	}
+	return "Подписка оформлена: " + what + ".", nil
+}
+
+func (b *Bot) unsubscribe(ctx context.Context, chatID int64, args string) (string, error) {
+	if strings.EqualFold(args, "all") {
+		n, err := b.store.DeleteTelegramChat(ctx, chatID)
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			return "У вас нет подписок.", nil
+		}
+		return "Вы отписались от всех уведомлений.", nil
+	}
+	kind, tag, ok := subscriptionArgs(args)
+	if !ok {
+		return "Использование: /unsubscribe news [тег], /unsubscribe routes или /unsubscribe all.", nil
+	}
+	removed, err := b.store.RemoveTelegramSubscription(ctx, chatID, kind, tag)
+	if err != nil {
+		return "", err
+	}
+	if !removed {
+		return "Такой подписки нет. Ваши подписки — /subscriptions.", nil
+	}
+	return "Подписка отменена: " + describe(kind, tag) + ".", nil
+}
+
+func (b *Bot) subscriptions(ctx context.Context, chatID int64) (string, error) {
+	subs, err := b.store.GetTelegramSubscriptions(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	if len(subs) == 0 {
+		return "У вас нет подписок. Подписаться — /subscribe.", nil
+	}
+	lines := make([]string, len(subs))
+	for i, s := range subs {
+		lines[i] = "• " + describe(s.Kind, s.Tag)
+	}
+	return "Ваши подписки:\n" + strings.Join(lines, "\n"), nil
+}
+
+func describe(kind, tag string) string {
+	switch {
+	case kind == models.TelegramRoutes:
+		return "маршруты эвакуации"
+	case tag == "":
+		return "все новости"
+	default:
+		return "новости с тегом «" + tag + "»"
+	}
+}
+
+// months — названия месяцев в том виде, в каком они хранятся в evacuation_routes.
+var months = [...]string{
+	"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+}
+
+func monthIndex(name string) int {
+	for i, m := range months {
+		if strings.EqualFold(m, name) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// routes — маршруты эвакуации на текущий месяц, а если их ещё нет — за последний месяц, где они есть.
+func (b *Bot) routes(ctx context.Context) (string, error) {
+	all, err := b.store.GetEvacuationRoutes(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(all) == 0 {
+		return "Маршруты эвакуации пока не опубликованы.", nil
+	}
+	period := func(r models.EvacuationRoute) int { return r.Year*100 + monthIndex(r.Month) }
+	sort.SliceStable(all, func(i, j int) bool { return period(all[i]) > period(all[j]) })
+
+	// Маршруты на следующие месяцы, опубликованные заранее, не показываются.
+	now := time.Now()
+	current := now.Year()*100 + int(now.Month())
+	var pick int
+	var title string
+	var lines []string
+	for _, r := range all {
+		p := period(r)
+		if p > current {
+			continue
+		}
+		if pick == 0 {
+			pick, title = p, strings.ToLower(r.Month)+" "+strconv.Itoa(r.Year)
+		}
+		if p != pick {
+			break
+		}
+		lines = append(lines, r.Route)
+	}
+	if pick == 0 {
+		return "Маршруты эвакуации пока не опубликованы.", nil
+	}
+	header := "Маршруты эвакуации на " + title + ":"
+	if pick != current {
+		header = "На текущий месяц маршрутов пока нет. Последние — " + title + ":"
+	}
+	return header + "\n\n" + strings.Join(lines, "\n\n"), nil
+}
+
+// stats — сводка из /api/stats.
+func (b *Bot) stats(ctx context.Context) (string, error) {
+	st, err := b.store.GetStats(ctx)
+	if err != nil {
+		return "", err
+	}
+	rows := []struct{ key, label, unit string }{
+		{"violations_total", "Нарушений", ""},
+		{"orders_total", "Постановлений", ""},
+		{"fines_amount_total", "Сумма штрафов", " ₽"},
+		{"collected_amount_total", "Взыскано", " ₽"},
+		{"evacuators_count", "Эвакуаторов", ""},
+		{"trips_count", "Выездов", ""},
+		{"evacuations_count", "Эвакуаций", ""},
+		{"fine_lot_income", "Доход штрафстоянки", " ₽"},
+		{"traffic_lights_active", "Работающих светофоров", ""},
+	}
+	var sb strings.Builder
+	sb.WriteString("Статистика ЦОДД:")
+	for _, r := range rows {
+		if v, ok := st[r.key]; ok {
+			fmt.Fprintf(&sb, "\n%s: %v%s", r.label, v, r.unit)
+		}
+	}
+	return sb.String(), nil
+}
+
+// sleep ждёт d; false — ctx отменён раньше.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,519 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"backend/internal/events"
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/models"
+)
+
+const testToken = "123456:SECRET-test-token"
+
+// fakeStore — подписки и справочные данные в памяти, с той же логикой отбора, что у store.
+type fakeStore struct {
+	mu     sync.Mutex
+	subs   []models.TelegramSubscription
+	tags   []string
+	routes []models.EvacuationRoute
+	stats  map[string]interface{}
+}
+
+func (s *fakeStore) AddTelegramSubscription(_ context.Context, chatID int64, kind, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subs {
+		if sub.ChatID == chatID && sub.Kind == kind && strings.EqualFold(sub.Tag, tag) {
+			return false, nil
+		}
+	}
+	s.subs = append(s.subs, models.TelegramSubscription{ChatID: chatID, Kind: kind, Tag: tag})
+	return true, nil
+}
+
+func (s *fakeStore) RemoveTelegramSubscription(_ context.Context, chatID int64, kind, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.subs {
+		if sub.ChatID == chatID && sub.Kind == kind && strings.EqualFold(sub.Tag, tag) {
+			s.subs = slices.Delete(s.subs, i, i+1)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *fakeStore) DeleteTelegramChat(_ context.Context, chatID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.subs)
+	s.subs = slices.DeleteFunc(s.subs, func(sub models.TelegramSubscription) bool { return sub.ChatID == chatID })
+	return int64(n - len(s.subs)), nil
+}
+
+func (s *fakeStore) GetTelegramSubscriptions(_ context.Context, chatID int64) ([]models.TelegramSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.TelegramSubscription
+	for _, sub := range s.subs {
+		if sub.ChatID == chatID {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeStore) GetTelegramSubscribers(_ context.Context, kind, tag string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []int64
+	for _, sub := range s.subs {
+		if sub.Kind == kind && (sub.Tag == "" || strings.EqualFold(sub.Tag, tag)) && !slices.Contains(ids, sub.ChatID) {
+			ids = append(ids, sub.ChatID)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (s *fakeStore) GetNewsTags(context.Context) ([]string, error) { return s.tags, nil }
+
+func (s *fakeStore) GetEvacuationRoutes(context.Context) ([]models.EvacuationRoute, error) {
+	return slices.Clone(s.routes), nil
+}
+
+func (s *fakeStore) GetStats(context.Context) (map[string]interface{}, error) { return s.stats, nil }
+
+func (s *fakeStore) chatSubs(chatID int64) []models.TelegramSubscription {
+	subs, _ := s.GetTelegramSubscriptions(context.Background(), chatID)
+	return subs
+}
+
+type sentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// fakeTelegram — Bot API на httptest: очередь входящих обновлений, журнал отправленных
+// сообщений и заготовленные ошибки sendMessage для отдельных чатов.
+type fakeTelegram struct {
+	srv     *httptest.Server
+	updates chan Update
+	sent    chan sentMessage
+
+	mu       sync.Mutex
+	updateID int64
+	failures map[int64][]string // chat_id → ответы Bot API с ошибкой, по одному на попытку
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	tg := &fakeTelegram{
+		updates:  make(chan Update, 16),
+		sent:     make(chan sentMessage, 64),
+		failures: make(map[int64][]string),
+	}
+	tg.srv = httptest.NewServer(http.HandlerFunc(tg.serve))
+	t.Cleanup(tg.srv.Close)
+	return tg
+}
+
+func (tg *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+testToken+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		return
+	}
+	switch method {
+	case "getUpdates":
+		var req struct {
+			Timeout int `json:"timeout"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var batch []Update
+		select {
+		case u := <-tg.updates:
+			batch = append(batch, u)
+		case <-time.After(time.Duration(req.Timeout) * time.Second):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": batch})
+	case "sendMessage":
+		var msg sentMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		tg.mu.Lock()
+		var failure string
+		if f := tg.failures[msg.ChatID]; len(f) > 0 {
+			failure, tg.failures[msg.ChatID] = f[0], f[1:]
+		}
+		tg.mu.Unlock()
+		if failure != "" {
+			fmt.Fprint(w, failure)
+			return
+		}
+		tg.sent <- msg
+		fmt.Fprint(w, `{"ok":true,"result":{}}`)
+	default:
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: unknown method"}`)
+	}
+}
+
+// fail заставляет следующие попытки отправить сообщение в чат вернуть ответы replies.
+func (tg *fakeTelegram) fail(chatID int64, replies ...string) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.failures[chatID] = append(tg.failures[chatID], replies...)
+}
+
+// say — сообщение пользователя в чат chatID.
+func (tg *fakeTelegram) say(chatID int64, chatType, text string) {
+	tg.mu.Lock()
+	tg.updateID++
+	id := tg.updateID
+	tg.mu.Unlock()
+	tg.updates <- Update{UpdateID: id, Message: &Message{MessageID: id, Chat: Chat{ID: chatID, Type: chatType}, Text: text}}
+}
+
+// next — следующее сообщение, отправленное ботом.
+func (tg *fakeTelegram) next(t *testing.T) sentMessage {
+	t.Helper()
+	select {
+	case m := <-tg.sent:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot sent nothing")
+		return sentMessage{}
+	}
+}
+
+// quiet проверяет, что бот больше ничего не отправил.
+func (tg *fakeTelegram) quiet(t *testing.T) {
+	t.Helper()
+	select {
+	case m := <-tg.sent:
+		t.Errorf("unexpected message to chat %d: %q", m.ChatID, m.Text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// startBot запускает бота с API на tg и останавливает его в конце теста.
+func startBot(t *testing.T, ctx context.Context, tg *fakeTelegram, st *fakeStore, bus *events.Bus) *Bot {
+	t.Helper()
+	b := NewBot(NewAPIClient(tg.srv.URL, testToken, time.Second), st, bus, "https://smolensk.example/", time.Second)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return b
+}
+
+// waitSubscribers ждёт, пока шина не получит n подписчиков (бот подписывается в своей горутине).
+func waitSubscribers(t *testing.T, n float64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var m dto.Metric
+		if err := metrics.EventSubscribers.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.GetGauge().GetValue() >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("bot did not subscribe to the bus")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func publish(t *testing.T, bus *events.Bus, id int64, typ string, data any) {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(events.Event{ID: id, Type: typ, Data: raw, Time: time.Now()})
+}
+
+func TestBotCommands(t *testing.T) {
+	tg := newFakeTelegram(t)
+	st := &fakeStore{tags: []string{"ДТП", "Ремонт дорог"}}
+	bus := events.NewBus(0)
+	t.Cleanup(bus.Close)
+	startBot(t, context.Background(), tg, st, bus)
+
+	const chat = 42
+	steps := []struct {
+		text string
+		want string
+	}{
+		{"/start", "/subscribe news <тег>"},
+		{"/subscribe news дтп", "Подписка оформлена: новости с тегом «ДТП»."},
+		{"/subscribe news ДТП", "Вы уже подписаны: новости с тегом «ДТП»."},
+		{"/subscribe news погода", "Новостей с таким тегом пока не было"},
+		{"/subscribe routes", "Подписка оформлена: маршруты эвакуации."},
+		{"/subscribe weather", "Использование: /subscribe"},
+		{"/subscriptions", "• новости с тегом «ДТП»\n• новости с тегом «погода»\n• маршруты эвакуации"},
+		{"/unsubscribe news погода", "Подписка отменена: новости с тегом «погода»."},
+		{"/unsubscribe news погода", "Такой подписки нет."},
+		{"/unsubscribe all", "Вы отписались от всех уведомлений."},
+		{"/unsubscribe all", "У вас нет подписок."},
+		{"/tags", "Теги новостей:\nДТП\nРемонт дорог"},
+		{"/nope", "Неизвестная команда"},
+		{"привет", "отправьте /help"},
+	}
+	for _, s := range steps {
+		tg.say(chat, "private", s.text)
+		got := tg.next(t)
+		if got.ChatID != chat || !strings.Contains(got.Text, s.want) {
+			t.Errorf("%s: reply to %d = %q, want it to contain %q", s.text, got.ChatID, got.Text, s.want)
+		}
+	}
+	if subs := st.chatSubs(chat); len(subs) != 0 {
+		t.Errorf("subscriptions after /unsubscribe all = %v", subs)
+	}
+
+	// В группе бот молчит на обычный текст и понимает /команду@имя_бота.
+	const group = -100
+	tg.say(group, "supergroup", "всем привет")
+	tg.say(group, "supergroup", "/subscribe@smol_codd_bot news")
+	if got := tg.next(t); got.ChatID != group || got.Text != "Подписка оформлена: все новости." {
+		t.Errorf("group reply = %d %q", got.ChatID, got.Text)
+	}
+	tg.quiet(t)
+}
+
+func TestBotRoutes(t *testing.T) {
+	now := time.Now()
+	month := func(offset int) (string, int) {
+		d := time.Date(now.Year(), now.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.Local)
+		return months[d.Month()-1], d.Year()
+	}
+	route := func(offset int, text string) models.EvacuationRoute {
+		m, y := month(offset)
+		return models.EvacuationRoute{Year: y, Month: m, Route: text}
+	}
+	curName, curYear := month(0)
+	prevName, prevYear := month(-1)
+	current := strings.ToLower(curName) + " " + strconv.Itoa(curYear)
+	previous := strings.ToLower(prevName) + " " + strconv.Itoa(prevYear)
+
+	tests := []struct {
+		name    string
+		routes  []models.EvacuationRoute
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "current month",
+			routes:  []models.EvacuationRoute{route(-1, "старый"), route(0, "ул. Ленина"), route(1, "будущий"), route(0, "пр-т Гагарина")},
+			want:    []string{"Маршруты эвакуации на " + current + ":", "ул. Ленина", "пр-т Гагарина"},
+			notWant: []string{"старый", "будущий"},
+		},
+		{
+			name:    "falls back to latest past month",
+			routes:  []models.EvacuationRoute{route(-2, "давний"), route(-1, "прошлый"), route(1, "будущий")},
+			want:    []string{"На текущий месяц маршрутов пока нет. Последние — " + previous + ":", "прошлый"},
+			notWant: []string{"давний", "будущий"},
+		},
+		{
+			name:   "only future",
+			routes: []models.EvacuationRoute{route(1, "будущий")},
+			want:   []string{"Маршруты эвакуации пока не опубликованы."},
+		},
+		{
+			name: "none",
+			want: []string{"Маршруты эвакуации пока не опубликованы."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBot(nil, &fakeStore{routes: tt.routes}, nil, "", time.Second)
+			got, err := b.command(context.Background(), 1, "/routes", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("reply %q lacks %q", got, w)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("reply %q contains %q", got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestBotStats(t *testing.T) {
+	tg := newFakeTelegram(t)
+	st := &fakeStore{stats: map[string]interface{}{
+		"violations_total":      1200,
+		"fines_amount_total":    560000,
+		"traffic_lights_active": 87,
+		"unknown_metric":        1,
+	}}
+	bus := events.NewBus(0)
+	t.Cleanup(bus.Close)
+	startBot(t, context.Background(), tg, st, bus)
+
+	tg.say(7, "private", "/stats")
+	got := tg.next(t).Text
+	want := "Статистика ЦОДД:\nНарушений: 1200\nСумма штрафов: 560000 ₽\nРаботающих светофоров: 87"
+	if got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+}
+
+func TestBotNotifications(t *testing.T) {
+	tg := newFakeTelegram(t)
+	st := &fakeStore{}
+	ctx := context.Background()
+	for _, s := range []struct {
+		chat      int64
+		kind, tag string
+	}{
+		{10, models.TelegramNews, ""},
+		{11, models.TelegramNews, "ДТП"},
+		{12, models.TelegramNews, "Ремонт дорог"},
+		{13, models.TelegramRoutes, ""},
+		{14, models.TelegramNews, ""},
+		{14, models.TelegramRoutes, ""},
+		{15, models.TelegramNews, "дтп"},
+	} {
+		st.AddTelegramSubscription(ctx, s.chat, s.kind, s.tag)
+	}
+	// 14 заблокировал бота; 15 упёрся в лимит Telegram, и сообщение уходит со второй попытки.
+	tg.fail(14, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+	tg.fail(15, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+
+	bus := events.NewBus(16)
+	t.Cleanup(bus.Close)
+	var before dto.Metric
+	metrics.EventSubscribers.Write(&before)
+	startBot(t, ctx, tg, st, bus)
+	waitSubscribers(t, before.GetGauge().GetValue()+1)
+
+	publish(t, bus, 1, events.NewsCreated, events.NewsRef{ID: 7, Title: "Перекрыт мост", Tag: "дтп"})
+	news := "📰 Перекрыт мост\n#дтп\nhttps://smolensk.example/news/7"
+	for _, chat := range []int64{10, 11, 15} {
+		if got := tg.next(t); got.ChatID != chat || got.Text != news {
+			t.Errorf("notification = %d %q, want %d %q", got.ChatID, got.Text, chat, news)
+		}
+	}
+	if subs := st.chatSubs(14); len(subs) != 0 {
+		t.Errorf("blocked chat keeps subscriptions: %v", subs)
+	}
+
+	publish(t, bus, 2, events.EvacuationRouteCreated, events.RouteRef{ID: 3, Year: 2026, Month: "Май", Route: "ул. Ленина"})
+	if got := tg.next(t); got.ChatID != 13 || got.Text != "🚧 Маршрут эвакуации — Май 2026:\nул. Ленина" {
+		t.Errorf("route notification = %d %q", got.ChatID, got.Text)
+	}
+
+	// Остальные события бот не рассылает.
+	publish(t, bus, 3, events.NewsUpdated, events.Ref{ID: 7, Version: 2})
+	tg.quiet(t)
+}
+
+// syncBuffer — журнал для логгера, который пишут горутины бота.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBotErrorsDoNotLeakToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc // nil — сервер недоступен
+	}{
+		{"unreachable", nil},
+		{"bad gateway", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>502 Bad Gateway "+r.URL.Path+"</html>")
+		}},
+		{"api error", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"ok":false,"error_code":401,"description":"Unauthorized"}`)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			if tt.handler == nil {
+				srv.Close()
+			} else {
+				defer srv.Close()
+			}
+
+			var logs syncBuffer
+			ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(),
+				slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+			st := &fakeStore{}
+			st.AddTelegramSubscription(ctx, 1, models.TelegramNews, "")
+			bus := events.NewBus(16)
+			defer bus.Close()
+
+			var before dto.Metric
+			metrics.EventSubscribers.Write(&before)
+			b := NewBot(NewAPIClient(srv.URL, testToken, time.Second), st, bus, "https://smolensk.example", time.Second)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				b.Run(ctx)
+			}()
+			waitSubscribers(t, before.GetGauge().GetValue()+1)
+			publish(t, bus, 1, events.NewsCreated, events.NewsRef{ID: 1, Title: "Новость", Tag: "ДТП"})
+
+			deadline := time.Now().Add(5 * time.Second)
+			for !(strings.Contains(logs.String(), "Telegram getUpdates failed") &&
+				strings.Contains(logs.String(), "Telegram message not sent")) {
+				if time.Now().After(deadline) {
+					t.Fatalf("expected errors were not logged:\n%s", logs.String())
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			cancel()
+			<-done
+
+			if out := logs.String(); strings.Contains(out, testToken) || strings.Contains(out, "SECRET") {
+				t.Errorf("token leaked into logs:\n%s", out)
+			}
+		})
+	}
+}
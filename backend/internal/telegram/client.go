@@ -0,0 +1,150 @@
+// Package telegram — бот уведомлений для жителей: подписки на новости по тегам и маршруты
+// эвакуации, рассылка при публикации (через шину событий) и ответы на команды /routes, /stats.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Update — входящее обновление Bot API (бот обрабатывает только сообщения).
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// Message — сообщение в чате.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat — чат, куда бот отвечает и шлёт уведомления.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// Client — методы Bot API, которые нужны боту. В проверках вместо Telegram можно
+// подставить свою реализацию или направить APIClient на локальный сервер.
+type Client interface {
+	// GetUpdates ждёт новые обновления с номера offset не дольше timeout (long polling).
+	GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error)
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// APIError — ошибка, которую вернул Bot API.
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+	// RetryAfter — для 429: через сколько можно повторить запрос.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram %s: %d %s", e.Method, e.Code, e.Description)
+}
+
+// IsBlocked сообщает, что писать в чат больше нельзя: бот заблокирован или удалён из группы.
+func IsBlocked(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
+
+// APIClient — клиент HTTP Bot API.
+type APIClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewAPIClient создаёт клиент для Bot API по адресу baseURL (https://api.telegram.org).
+// Таймаут HTTP на несколько секунд больше pollTimeout, чтобы не обрывать long polling.
+func NewAPIClient(baseURL, token string, pollTimeout time.Duration) *APIClient {
+	return &APIClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+// GetUpdates — метод getUpdates.
+func (c *APIClient) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage — метод sendMessage (простой текст, без разметки).
+func (c *APIClient) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+func (c *APIClient) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram %s: invalid request", method)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// В тексте *url.Error есть адрес запроса, а в нём токен.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("telegram %s: HTTP %d: invalid response: %w", method, resp.StatusCode, err)
+	}
+	if !out.OK {
+		code := out.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &APIError{
+			Method:      method,
+			Code:        code,
+			Description: out.Description,
+			RetryAfter:  time.Duration(out.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(out.Result, result)
+}
@@ -0,0 +1,133 @@
+// Package tlscert — сертификат HTTPS-сервера, который перечитывается с диска
+// при замене файлов (продление Let's Encrypt, ротация секрета в Kubernetes) без перезапуска.
+package tlscert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"backend/internal/logging"
+)
+
+// Reloader отдаёт текущий сертификат через GetCertificate и подменяет его,
+// когда меняются файлы сертификата или ключа.
+type Reloader struct {
+	certFile, keyFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	notAfter time.Time
+	stamp    string
+	// failed — отметка файлов, которые не удалось загрузить: ошибка логируется один раз.
+	failed string
+}
+
+// New загружает сертификат; ошибка, если файлы не читаются или не подходят друг к другу.
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig — настройки сервера: TLS 1.2+, сертификат из Reloader.
+// HTTP/2 http.Server включает сам (ALPN "h2").
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// GetCertificate — для tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// NotAfter — окончание срока действия текущего сертификата.
+func (r *Reloader) NotAfter() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.notAfter
+}
+
+// Run каждые interval проверяет файлы и перечитывает их, если они изменились, пока не отменён ctx.
+// Битые файлы (например, записанные наполовину) логируются; до исправления работает прежний сертификат.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			changed, err := r.reload()
+			if err != nil {
+				logging.FromContext(ctx).Error("TLS certificate reload failed", "err", err)
+				continue
+			}
+			if changed {
+				logging.FromContext(ctx).Info("TLS certificate reloaded", "not_after", r.NotAfter())
+			}
+		}
+	}
+}
+
+// reload перечитывает файлы, если их размер или время изменения не совпадают с загруженными.
+func (r *Reloader) reload() (bool, error) {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	same := stamp == r.stamp || stamp == r.failed
+	r.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	cert, err := loadKeyPair(r.certFile, r.keyFile)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.failed = stamp
+		return false, err
+	}
+	r.cert, r.notAfter, r.stamp, r.failed = cert, cert.Leaf.NotAfter, stamp, ""
+	return true, nil
+}
+
+func loadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse TLS certificate: %w", err)
+	}
+	cert.Leaf = leaf
+	return &cert, nil
+}
+
+// fileStamp — размер и время изменения обоих файлов (os.Stat идёт по симлинкам,
+// поэтому замена ..data в смонтированном секрете тоже заметна).
+func (r *Reloader) fileStamp() (string, error) {
+	var stamp string
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%d:%d;", fi.Size(), fi.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes — bcrypt учитывает только первые 72 байта.
+const maxPasswordBytes = 72
+
+// commonPasswords — самые частые пароли из утечек (в нижнем регистре).
+var commonPasswords = []string{
+	"password", "password1", "password123", "qwerty123", "qwertyuiop", "1q2w3e4r5t",
+	"12345678", "123456789", "1234567890", "11111111", "00000000", "abc12345",
+	"admin123", "admin1234", "editor123", "letmein123", "welcome123", "iloveyou1",
+	"йцукен123", "пароль123", "smolensk123",
+}
+
+// Password — политика сложности пароля: не короче minLen символов, буквы и цифры,
+// не из списка частых паролей и без email (или его части до @).
+func Password(password, email string, minLen int) error {
+	var c checker
+	lower := strings.ToLower(password)
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		hasLetter = hasLetter || unicode.IsLetter(r)
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+
+	c.check(utf8.RuneCountInString(password) >= minLen, "password", "min_length", fmt.Sprintf("Не менее %d символов", minLen))
+	c.check(len(password) <= maxPasswordBytes, "password", "max_length", "Пароль слишком длинный")
+	c.check(hasLetter && hasDigit, "password", "weak", "Пароль должен содержать буквы и цифры")
+	for _, p := range commonPasswords {
+		c.check(lower != p, "password", "common", "Этот пароль слишком распространён")
+	}
+	if local, _, _ := strings.Cut(strings.ToLower(email), "@"); len(local) >= 3 {
+		c.check(!strings.Contains(lower, local), "password", "contains_email", "Пароль не должен содержать email")
+	}
+	return c.err()
+}
@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/pkg"
+)
+
+// Допустимые статусы светофора.
+var trafficLightStatuses = []string{"active", "inactive", "maintenance"}
+
+// Названия месяцев в маршрутах эвакуации (как в исходных данных).
+var routeMonths = []string{
+	"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+}
+
+// minYear — нижняя граница для годов в справочных данных.
+const minYear = 1950
+
+func notFuture(c *checker, t time.Time, field string) {
+	if t.IsZero() {
+		c.fail(field, "required", "Укажите дату")
+		return
+	}
+	c.check(!t.After(time.Now()), field, "not_future", "Дата не может быть в будущем")
+}
+
+func optionalURL(c *checker, value, field string, maxLen int) {
+	if value == "" {
+		return
+	}
+	c.maxLength(value, field, maxLen)
+	if strings.HasPrefix(value, "/") {
+		return
+	}
+	u, err := url.Parse(value)
+	c.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		field, "url", "Укажите ссылку вида https://… или путь от корня сайта")
+}
+
+// Fine проверяет сводку по штрафам: суммы неотрицательны, взыскано не больше начисленного.
+func Fine(f *models.Fine) error {
+	var c checker
+	notFuture(&c, f.Date, "date")
+	c.nonNegative(f.ViolationsTotal, "violations_total")
+	c.nonNegative(f.OrdersTotal, "orders_total")
+	c.nonNegative(f.FinesAmountTotal, "fines_amount_total")
+	c.nonNegative(f.CollectedAmountTotal, "collected_amount_total")
+	c.check(f.OrdersTotal <= f.ViolationsTotal, "orders_total", "lte_field",
+		"Постановлений не может быть больше, чем нарушений")
+	c.check(f.CollectedAmountTotal <= f.FinesAmountTotal, "collected_amount_total", "lte_field",
+		"Взыскано не может быть больше, чем начислено штрафов")
+	return c.err()
+}
+
+// Evacuation проверяет сводку по эвакуациям: эвакуаций не больше, чем выездов.
+func Evacuation(e *models.Evacuation) error {
+	var c checker
+	notFuture(&c, e.Date, "date")
+	c.nonNegative(e.EvacuatorsCount, "evacuators_count")
+	c.nonNegative(e.TripsCount, "trips_count")
+	c.nonNegative(e.EvacuationsCount, "evacuations_count")
+	c.nonNegative(e.FineLotIncome, "fine_lot_income")
+	c.check(e.EvacuationsCount <= e.TripsCount, "evacuations_count", "lte_field",
+		"Эвакуаций не может быть больше, чем выездов")
+	return c.err()
+}
+
+func EvacuationRoute(r *models.EvacuationRoute) error {
+	var c checker
+	c.check(r.Year >= minYear && r.Year <= time.Now().Year()+1, "year", "range",
+		"Год должен быть в пределах от 1950 до следующего года")
+	c.check(pkg.Contains(routeMonths, r.Month), "month", "one_of", "Укажите месяц словом, например «Январь»")
+	c.text(r.Route, "route", 0)
+	return c.err()
+}
+
+func TrafficLight(t *models.TrafficLight) error {
+	var c checker
+	c.text(t.Address, "address", 500)
+	c.text(t.LightType, "light_type", 50)
+	c.check(t.InstallYear >= minYear, "install_year", "min", "Год установки не может быть раньше 1950")
+	c.check(t.InstallYear <= time.Now().Year(), "install_year", "not_future", "Год установки не может быть в будущем")
+	c.check(pkg.Contains(trafficLightStatuses, t.Status), "status", "one_of",
+		"Статус должен быть одним из: active, inactive, maintenance")
+	return c.err()
+}
+
+func News(n *models.News) error {
+	var c checker
+	c.text(n.Title, "title", 255)
+	c.text(n.Content, "content", 0)
+	c.text(n.Tag, "tag", 50)
+	return c.err()
+}
+
+func Service(s *models.Service) error {
+	var c checker
+	c.text(s.Title, "title", 255)
+	c.text(s.Description, "description", 0)
+	c.nonNegative(s.Price, "price")
+	c.text(s.Category, "category", 100)
+	optionalURL(&c, s.IconURL, "icon_url", 255)
+	return c.err()
+}
+
+func TeamMember(m *models.TeamMember) error {
+	var c checker
+	c.text(m.Name, "name", 255)
+	c.text(m.Position, "position", 255)
+	c.text(m.Experience, "experience", 0)
+	if m.PhotoURL != nil {
+		optionalURL(&c, *m.PhotoURL, "photo_url", 255)
+	}
+	return c.err()
+}
+
+func Project(p *models.Project) error {
+	var c checker
+	c.text(p.Title, "title", 255)
+	c.text(p.Description, "description", 0)
+	c.text(p.Category, "category", 100)
+	c.maxLength(p.Status, "status", 50)
+	return c.err()
+}
+
+func Vacancy(v *models.Vacancy) error {
+	var c checker
+	c.text(v.Position, "position", 255)
+	c.text(v.Experience, "experience", 0)
+	c.text(v.Salary, "salary", 100)
+	return c.err()
+}
+
+// Webhook проверяет подписку: адрес http(s) и хотя бы один фильтр из известных событий.
+func Webhook(w *models.Webhook) error {
+	var c checker
+	c.text(w.URL, "url", 500)
+	u, err := url.Parse(w.URL)
+	c.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"url", "url", "Укажите адрес вида https://…")
+	c.check(len(w.Events) > 0, "events", "required", "Укажите хотя бы одно событие")
+	for _, e := range w.Events {
+		c.check(events.ValidPattern(e), "events", "one_of", "Неизвестное событие: "+e)
+	}
+	c.maxLength(w.Description, "description", 255)
+	return c.err()
+}
+
+// Feedback проверяет обращение с сайта.
+func Feedback(f *models.Feedback) error {
+	var c checker
+	c.check(pkg.Contains(models.FeedbackCategories, f.Category), "category", "one_of",
+		"Категория должна быть одной из: complaint, suggestion, question")
+	c.maxLength(f.Location, "location", 255)
+	c.text(f.Text, "text", 5000)
+	c.check(utf8.RuneCountInString(strings.TrimSpace(f.Text)) >= 10, "text", "min_length",
+		"Опишите обращение подробнее, не менее 10 символов")
+	c.maxLength(f.Contact, "contact", 255)
+	return c.err()
+}
+
+// FeedbackRejection проверяет причину отклонения обращения (её видит автор).
+func FeedbackRejection(reason string) error {
+	var c checker
+	c.maxLength(reason, "reason", 1000)
+	return c.err()
+}
+
+// FeedbackResponse проверяет ответ ЦОДД на обращение.
+func FeedbackResponse(response string) error {
+	var c checker
+	c.text(response, "response", 5000)
+	return c.err()
+}
+
+// ServiceOrder проверяет заказ услуги с сайта: имя и телефон для связи обязательны.
+func ServiceOrder(o *models.ServiceOrder) error {
+	var c checker
+	c.text(o.CustomerName, "customer_name", 255)
+	c.text(o.Phone, "phone", 50)
+	if o.Phone != "" {
+		digits := 0
+		for _, r := range o.Phone {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		c.check(digits >= 10 && digits <= 15, "phone", "phone", "Укажите номер телефона, например +7 900 123-45-67")
+	}
+	if o.Email != "" {
+		c.maxLength(o.Email, "email", 255)
+		c.check(strings.Contains(o.Email, "@"), "email", "email", "Укажите email вида name@example.ru")
+	}
+	c.maxLength(o.Comment, "comment", 2000)
+	return c.err()
+}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+// fieldRules — нарушенные правила по полям из ошибки валидации (nil — ошибок нет).
+func fieldRules(t *testing.T, err error) map[string]string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error %T is not validation.Errors: %v", err, err)
+	}
+	rules := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		rules[fe.Field] = fe.Rule
+	}
+	return rules
+}
+
+// ruleCase — результат проверки сущности и ожидаемые нарушения по полям (nil — сущность валидна).
+type ruleCase struct {
+	name string
+	err  error
+	want map[string]string
+}
+
+func runRuleCases(t *testing.T, tests []ruleCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldRules(t, tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("rules = %v, want %v", got, tt.want)
+			}
+			for field, rule := range tt.want {
+				if got[field] != rule {
+					t.Errorf("%s: rule = %q, want %q (all: %v)", field, got[field], rule, got)
+				}
+			}
+		})
+	}
+}
+
+func yesterday() time.Time { return time.Now().AddDate(0, 0, -1) }
+
+func TestFine(t *testing.T) {
+	valid := func() *models.Fine {
+		return &models.Fine{Date: yesterday(), ViolationsTotal: 10, OrdersTotal: 8, FinesAmountTotal: 5000, CollectedAmountTotal: 3000}
+	}
+	with := func(f func(*models.Fine)) *models.Fine { x := valid(); f(x); return x }
+	runRuleCases(t, []ruleCase{
+		{"valid", Fine(valid()), nil},
+		{"no date", Fine(with(func(f *models.Fine) { f.Date = time.Time{} })), map[string]string{"date": "required"}},
+		{"future date", Fine(with(func(f *models.Fine) { f.Date = time.Now().Add(time.Hour) })), map[string]string{"date": "not_future"}},
+		{"negative", Fine(with(func(f *models.Fine) { f.FinesAmountTotal = -1; f.CollectedAmountTotal = -1 })),
+			map[string]string{"fines_amount_total": "min", "collected_amount_total": "min"}},
+		{"orders over violations", Fine(with(func(f *models.Fine) { f.OrdersTotal = 11 })), map[string]string{"orders_total": "lte_field"}},
+		{"collected over fines", Fine(with(func(f *models.Fine) { f.CollectedAmountTotal = 5001 })), map[string]string{"collected_amount_total": "lte_field"}},
+	})
+}
+
+func TestEvacuation(t *testing.T) {
+	runRuleCases(t, []ruleCase{
+		{"valid", Evacuation(&models.Evacuation{Date: yesterday(), EvacuatorsCount: 2, TripsCount: 5, EvacuationsCount: 5}), nil},
+		{"evacuations over trips", Evacuation(&models.Evacuation{Date: yesterday(), TripsCount: 1, EvacuationsCount: 2}),
+			map[string]string{"evacuations_count": "lte_field"}},
+		{"negative income", Evacuation(&models.Evacuation{Date: yesterday(), FineLotIncome: -10}), map[string]string{"fine_lot_income": "min"}},
+	})
+}
+
+func TestEvacuationRoute(t *testing.T) {
+	year := time.Now().Year()
+	runRuleCases(t, []ruleCase{
+		{"valid", EvacuationRoute(&models.EvacuationRoute{Year: year, Month: "Май", Route: "ул. Ленина"}), nil},
+		{"next year", EvacuationRoute(&models.EvacuationRoute{Year: year + 1, Month: "Январь", Route: "ул. Ленина"}), nil},
+		{"too far ahead", EvacuationRoute(&models.EvacuationRoute{Year: year + 2, Month: "Май", Route: "ул. Ленина"}), map[string]string{"year": "range"}},
+		{"too old", EvacuationRoute(&models.EvacuationRoute{Year: 1949, Month: "Май", Route: "ул. Ленина"}), map[string]string{"year": "range"}},
+		{"month as number", EvacuationRoute(&models.EvacuationRoute{Year: year, Month: "05", Route: "ул. Ленина"}), map[string]string{"month": "one_of"}},
+		{"no route", EvacuationRoute(&models.EvacuationRoute{Year: year, Month: "Май"}), map[string]string{"route": "required"}},
+	})
+}
+
+func TestTrafficLight(t *testing.T) {
+	year := time.Now().Year()
+	runRuleCases(t, []ruleCase{
+		{"valid", TrafficLight(&models.TrafficLight{Address: "пр-т Гагарина, 1", LightType: "LED", InstallYear: 2015, Status: "active"}), nil},
+		{"unknown status", TrafficLight(&models.TrafficLight{Address: "a", LightType: "LED", InstallYear: 2015, Status: "broken"}), map[string]string{"status": "one_of"}},
+		{"future year", TrafficLight(&models.TrafficLight{Address: "a", LightType: "LED", InstallYear: year + 1, Status: "active"}), map[string]string{"install_year": "not_future"}},
+		{"old year", TrafficLight(&models.TrafficLight{Address: "a", LightType: "LED", InstallYear: 1900, Status: "active"}), map[string]string{"install_year": "min"}},
+		{"long address", TrafficLight(&models.TrafficLight{Address: strings.Repeat("д", 501), LightType: "LED", InstallYear: 2015, Status: "maintenance"}),
+			map[string]string{"address": "max_length"}},
+	})
+}
+
+func TestContentRules(t *testing.T) {
+	photo := func(s string) *string { return &s }
+	runRuleCases(t, []ruleCase{
+		{"news valid", News(&models.News{Title: "Ремонт", Content: "Текст", Tag: "Дороги"}), nil},
+		{"news empty", News(&models.News{}), map[string]string{"title": "required", "content": "required", "tag": "required"}},
+		{"news long tag", News(&models.News{Title: "t", Content: "c", Tag: strings.Repeat("т", 51)}), map[string]string{"tag": "max_length"}},
+		{"service valid", Service(&models.Service{Title: "Автопомощь", Description: "d", Price: 0, Category: "Авто", IconURL: "/icons/car.svg"}), nil},
+		{"service negative price", Service(&models.Service{Title: "t", Description: "d", Price: -1, Category: "c"}), map[string]string{"price": "min"}},
+		{"service bad icon", Service(&models.Service{Title: "t", Description: "d", Category: "c", IconURL: "javascript:alert(1)"}), map[string]string{"icon_url": "url"}},
+		{"service ftp icon", Service(&models.Service{Title: "t", Description: "d", Category: "c", IconURL: "ftp://example.ru/i.png"}), map[string]string{"icon_url": "url"}},
+		{"team valid", TeamMember(&models.TeamMember{Name: "Иванов", Position: "Инженер", Experience: "5 лет", PhotoURL: photo("https://cdn.example.ru/1.jpg")}), nil},
+		{"team bad photo", TeamMember(&models.TeamMember{Name: "n", Position: "p", Experience: "e", PhotoURL: photo("not a url")}), map[string]string{"photo_url": "url"}},
+		{"project valid", Project(&models.Project{Title: "t", Description: "d", Category: "c"}), nil},
+		{"project long status", Project(&models.Project{Title: "t", Description: "d", Category: "c", Status: strings.Repeat("s", 51)}), map[string]string{"status": "max_length"}},
+		{"vacancy valid", Vacancy(&models.Vacancy{Position: "Go", Experience: "3 года", Salary: "по договорённости"}), nil},
+		{"vacancy empty", Vacancy(&models.Vacancy{}), map[string]string{"position": "required", "experience": "required", "salary": "required"}},
+	})
+}
+
+func TestPassword(t *testing.T) {
+	runRuleCases(t, []ruleCase{
+		{"valid", Password("Smolensk-road-2024", "editor@smolensk.ru", 10), nil},
+		{"short", Password("abc123", "", 10), map[string]string{"password": "min_length"}},
+		{"no digits", Password("onlyletterslong", "", 10), map[string]string{"password": "weak"}},
+		{"common", Password("Password123", "", 10), map[string]string{"password": "common"}},
+		{"contains email", Password("ivanov-2024-pass", "Ivanov@smolensk.ru", 10), map[string]string{"password": "contains_email"}},
+		{"short email part ignored", Password("ab-road-2024x", "ab@smolensk.ru", 10), nil},
+		{"over bcrypt limit", Password(strings.Repeat("a1", 37), "", 10), map[string]string{"password": "max_length"}},
+	})
+}
+
+func TestWebhookURL(t *testing.T) {
+	hook := func(u string) *models.Webhook { return &models.Webhook{URL: u, Events: []string{"*"}} }
+	runRuleCases(t, []ruleCase{
+		{"https", Webhook(hook("https://partner.example/hook")), nil},
+		{"http with port", Webhook(hook("http://10.0.0.5:8080/hook")), nil},
+		{"empty", Webhook(hook("")), map[string]string{"url": "required"}},
+		{"no scheme", Webhook(hook("partner.example/hook")), map[string]string{"url": "url"}},
+		{"other scheme", Webhook(hook("ftp://partner.example")), map[string]string{"url": "url"}},
+		{"no host", Webhook(hook("https:///hook")), map[string]string{"url": "url"}},
+		{"long description", Webhook(&models.Webhook{URL: "https://p.example", Events: []string{"*"}, Description: strings.Repeat("d", 256)}),
+			map[string]string{"description": "max_length"}},
+	})
+}
+
+func TestWebhookEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []string
+		rule   string
+	}{
+		{"exact type", []string{"news.created"}, ""},
+		{"feedback published", []string{"feedback.published"}, ""},
+		{"feedback withdrawn", []string{"feedback.withdrawn"}, ""},
+		{"feedback mask", []string{"feedback.*"}, ""},
+		{"all", []string{"*"}, ""},
+		{"unknown type", []string{"feedback.created"}, "one_of"},
+		{"unknown mask", []string{"nope.*"}, "one_of"},
+		{"empty", nil, "required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := fieldRules(t, Webhook(&models.Webhook{URL: "https://partner.example/hook", Events: tt.events}))
+			if rules["events"] != tt.rule {
+				t.Errorf("events rule = %q, want %q (all: %v)", rules["events"], tt.rule, rules)
+			}
+		})
+	}
+}
+
+func TestFeedbackRules(t *testing.T) {
+	runRuleCases(t, []ruleCase{
+		{"valid", Feedback(&models.Feedback{Category: models.FeedbackComplaint, Text: "Светофор не работает"}), nil},
+		{"unknown category", Feedback(&models.Feedback{Category: "praise", Text: "Светофор не работает"}), map[string]string{"category": "one_of"}},
+		{"short text", Feedback(&models.Feedback{Category: models.FeedbackQuestion, Text: "  Почему?  "}), map[string]string{"text": "min_length"}},
+		{"no text", Feedback(&models.Feedback{Category: models.FeedbackQuestion}), map[string]string{"text": "required"}},
+		{"long text", Feedback(&models.Feedback{Category: models.FeedbackSuggestion, Text: strings.Repeat("т", 5001)}), map[string]string{"text": "max_length"}},
+		{"long contact", Feedback(&models.Feedback{Category: models.FeedbackSuggestion, Text: "Поставьте светофор", Contact: strings.Repeat("c", 256)}),
+			map[string]string{"contact": "max_length"}},
+		{"rejection without reason", FeedbackRejection(""), nil},
+		{"long rejection", FeedbackRejection(strings.Repeat("r", 1001)), map[string]string{"reason": "max_length"}},
+		{"empty response", FeedbackResponse(" "), map[string]string{"response": "required"}},
+		{"response", FeedbackResponse("Светофор починили"), nil},
+	})
+}
+
+func TestServiceOrder(t *testing.T) {
+	order := func(phone, email string) *models.ServiceOrder {
+		return &models.ServiceOrder{CustomerName: "Иван Иванов", Phone: phone, Email: email}
+	}
+	runRuleCases(t, []ruleCase{
+		{"valid", ServiceOrder(order("+7 900 123-45-67", "ivanov@example.ru")), nil},
+		{"no email", ServiceOrder(order("89001234567", "")), nil},
+		{"no phone", ServiceOrder(order("", "")), map[string]string{"phone": "required"}},
+		{"short phone", ServiceOrder(order("123-45", "")), map[string]string{"phone": "phone"}},
+		{"long phone", ServiceOrder(order("+7 900 123 45 67 89 01 23", "")), map[string]string{"phone": "phone"}},
+		{"phone without digits", ServiceOrder(order("позвоните мне", "")), map[string]string{"phone": "phone"}},
+		{"bad email", ServiceOrder(order("89001234567", "ivanov.example.ru")), map[string]string{"email": "email"}},
+		{"no name", ServiceOrder(&models.ServiceOrder{Phone: "89001234567"}), map[string]string{"customer_name": "required"}},
+		{"long comment", ServiceOrder(&models.ServiceOrder{CustomerName: "n", Phone: "89001234567", Comment: strings.Repeat("к", 2001)}),
+			map[string]string{"comment": "max_length"}},
+	})
+}
@@ -0,0 +1,73 @@
+// Package validation — бизнес-правила для сущностей сверх `binding:"required"`.
+// Ошибки собираются по полям, чтобы админка могла подсветить все неверные значения сразу.
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError — нарушение одного правила для одного поля.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors — все нарушения, найденные в сущности.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+": "+fe.Rule)
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+// checker накапливает ошибки; для каждого поля сохраняется только первая.
+type checker struct {
+	errs Errors
+}
+
+func (c *checker) fail(field, rule, message string) {
+	for _, fe := range c.errs {
+		if fe.Field == field {
+			return
+		}
+	}
+	c.errs = append(c.errs, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+func (c *checker) check(ok bool, field, rule, message string) {
+	if !ok {
+		c.fail(field, rule, message)
+	}
+}
+
+// text проверяет обязательную строку с ограничением длины в символах (как VARCHAR в схеме).
+func (c *checker) text(value, field string, maxLen int) {
+	if strings.TrimSpace(value) == "" {
+		c.fail(field, "required", "Поле обязательно для заполнения")
+		return
+	}
+	c.maxLength(value, field, maxLen)
+}
+
+func (c *checker) maxLength(value, field string, maxLen int) {
+	if maxLen > 0 && utf8.RuneCountInString(value) > maxLen {
+		c.fail(field, "max_length", fmt.Sprintf("Не более %d символов", maxLen))
+	}
+}
+
+func (c *checker) nonNegative(value int, field string) {
+	c.check(value >= 0, field, "min", "Значение не может быть отрицательным")
+}
+
+func (c *checker) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
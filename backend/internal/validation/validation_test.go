@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckerKeepsFirstErrorPerField(t *testing.T) {
+	var c checker
+	c.text("", "title", 10)
+	c.check(false, "title", "other", "не попадёт: у поля уже есть ошибка")
+	c.maxLength("слишком длинно", "tag", 5)
+	c.nonNegative(-1, "price")
+	c.check(true, "ok", "never", "")
+
+	err := c.err()
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("err = %T, want Errors", err)
+	}
+	want := Errors{
+		{Field: "title", Rule: "required", Message: "Поле обязательно для заполнения"},
+		{Field: "tag", Rule: "max_length", Message: "Не более 5 символов"},
+		{Field: "price", Rule: "min", Message: "Значение не может быть отрицательным"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errors = %+v, want %+v", errs, want)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("errors[%d] = %+v, want %+v", i, errs[i], want[i])
+		}
+	}
+	if got := err.Error(); got != "validation failed: title: required, tag: max_length, price: min" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestCheckerNoErrors(t *testing.T) {
+	var c checker
+	c.text("Новость", "title", 10)
+	c.maxLength("", "tag", 5)
+	c.maxLength(strings.Repeat("я", 5), "tag", 5) // длина в символах, не в байтах
+	c.maxLength(strings.Repeat("x", 10_000), "content", 0)
+	c.nonNegative(0, "price")
+	// nil, а не пустой Errors: иначе err != nil сработает на валидной сущности.
+	if err := c.err(); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestCheckerTextWhitespace(t *testing.T) {
+	var c checker
+	c.text(" \t\n", "title", 10)
+	if rules := fieldRules(t, c.err()); rules["title"] != "required" {
+		t.Errorf("rules = %v, want title required", rules)
+	}
+}
+
+func TestFieldErrorJSON(t *testing.T) {
+	b, err := json.Marshal(Errors{{Field: "date", Rule: "not_future", Message: "Дата не может быть в будущем"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != `[{"field":"date","rule":"not_future","message":"Дата не может быть в будущем"}]` {
+		t.Errorf("JSON = %s", got)
+	}
+}
@@ -0,0 +1,185 @@
+// Package webhook — доставка событий партнёрам: очередь в БД (webhook_deliveries),
+// подпись тела HMAC-SHA256, повторы с экспоненциальной задержкой.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/config"
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/models"
+)
+
+// Заголовки запроса к партнёру.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// batchSize — сколько доставок берётся из очереди за раз; они отправляются параллельно.
+const batchSize = 20
+
+// maxErrorLen — сколько текста ошибки или ответа партнёра сохраняется в журнале.
+const maxErrorLen = 500
+
+// Queue — очередь доставок в БД.
+type Queue interface {
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookJob, error)
+	RecordWebhookAttempt(ctx context.Context, id int64, a models.WebhookAttempt) error
+}
+
+// Dispatcher отправляет доставки из очереди.
+type Dispatcher struct {
+	queue  Queue
+	cfg    config.WebhooksConfig
+	client *http.Client
+}
+
+// NewDispatcher создаёт отправителя. Редиректы не выполняются: ответ 3xx — неудачная попытка.
+func NewDispatcher(queue Queue, cfg config.WebhooksConfig) *Dispatcher {
+	return &Dispatcher{
+		queue: queue,
+		cfg:   cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Sign — подпись тела: "sha256=" + hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+// Метка времени входит в подпись, чтобы перехваченный запрос нельзя было повторить позже.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run каждые interval отправляет доставки, время которых подошло, пока не отменён ctx.
+// Если очередь отдала полную пачку, следующая берётся сразу.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for ctx.Err() == nil && d.dispatch(ctx) == batchSize {
+			}
+		}
+	}
+}
+
+// dispatch отправляет одну пачку и возвращает её размер.
+func (d *Dispatcher) dispatch(ctx context.Context) int {
+	// Пока доставка в работе, другие реплики её не берут; запас на случай медленной записи итога.
+	lease := 2*d.cfg.Timeout + time.Minute
+	jobs, err := d.queue.ClaimWebhookDeliveries(ctx, time.Now(), lease, batchSize)
+	if err != nil {
+		logging.FromContext(ctx).Error("Webhook queue read failed", "err", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, job)
+		}()
+	}
+	wg.Wait()
+	return len(jobs)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, job models.WebhookJob) {
+	log := logging.FromContext(ctx).With("delivery_id", job.DeliveryID, "event", job.EventType)
+	status, err := d.send(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		// Сервер останавливается: попытка не засчитывается, доставку заберут после lease.
+		return
+	}
+
+	a := models.WebhookAttempt{At: time.Now(), Status: models.DeliveryDelivered, ResponseStatus: status}
+	result := "delivered"
+	if err != nil {
+		a.Error = truncate(err.Error())
+		attempts := job.Attempts + 1
+		if attempts >= d.cfg.MaxAttempts {
+			a.Status, result = models.DeliveryFailed, "failed"
+			log.Warn("Webhook delivery failed", "attempts", attempts, "err", err)
+		} else {
+			a.Status, result = models.DeliveryPending, "retry"
+			a.NextAttemptAt = a.At.Add(d.backoff(attempts))
+			log.Info("Webhook delivery will be retried", "attempts", attempts, "next_attempt_at", a.NextAttemptAt, "err", err)
+		}
+	}
+	metrics.WebhookDeliveries.WithLabelValues(result).Inc()
+
+	// Итог записывается и при остановке сервера, иначе доставленное событие отправится ещё раз.
+	if err := d.queue.RecordWebhookAttempt(context.WithoutCancel(ctx), job.DeliveryID, a); err != nil {
+		log.Error("Webhook attempt not recorded", "err", err)
+	}
+}
+
+// send отправляет запрос; ошибка — нет ответа или статус не 2xx.
+func (d *Dispatcher) send(ctx context.Context, job models.WebhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "smolathon-webhooks/1")
+	req.Header.Set(HeaderEvent, job.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.DeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, ts, job.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLen))
+	return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// backoff — задержка перед следующей попыткой после attempts неудачных.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempts && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.BackoffMax)
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxErrorLen {
+		return string(r[:maxErrorLen])
+	}
+	return s
+}
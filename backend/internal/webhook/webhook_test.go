@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+)
+
+func TestSign(t *testing.T) {
+	// Вектор посчитан независимо: HMAC-SHA256("partner-secret", "1700000000.<body>").
+	body := []byte(`{"id":1,"type":"news.created"}`)
+	const want = "sha256=3a02c09d6791c504798436ede4692541befe082afd0bbedb2302e427568edabc"
+	if got := Sign("partner-secret", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("partner-secret", 1700000001, body) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if Sign("other-secret", 1700000000, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, config.WebhooksConfig{BackoffBase: 30 * time.Second, BackoffMax: 10 * time.Minute})
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// memQueue — очередь, которая один раз отдаёт jobs и запоминает итоги попыток.
+type memQueue struct {
+	mu       sync.Mutex
+	jobs     []models.WebhookJob
+	attempts map[int64]models.WebhookAttempt
+}
+
+func (q *memQueue) ClaimWebhookDeliveries(_ context.Context, _ time.Time, _ time.Duration, limit int) ([]models.WebhookJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(limit, len(q.jobs))
+	jobs := q.jobs[:n]
+	q.jobs = q.jobs[n:]
+	return jobs, nil
+}
+
+func (q *memQueue) RecordWebhookAttempt(_ context.Context, id int64, a models.WebhookAttempt) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.attempts[id] = a
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	const secret = "partner-secret"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if r.Header.Get(HeaderSignature) != Sign(secret, ts, body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/down" {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := config.WebhooksConfig{Timeout: 5 * time.Second, MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour}
+	q := &memQueue{
+		attempts: make(map[int64]models.WebhookAttempt),
+		jobs: []models.WebhookJob{
+			{DeliveryID: 1, EventType: "news.created", Payload: []byte(`{"id":1}`), URL: srv.URL + "/ok", Secret: secret},
+			{DeliveryID: 2, EventType: "news.created", Payload: []byte(`{"id":2}`), Attempts: 1, URL: srv.URL + "/down", Secret: secret},
+			{DeliveryID: 3, EventType: "news.created", Payload: []byte(`{"id":3}`), Attempts: cfg.MaxAttempts - 1, URL: srv.URL + "/down", Secret: secret},
+		},
+	}
+
+	before := time.Now()
+	if n := NewDispatcher(q, cfg).dispatch(context.Background()); n != 3 {
+		t.Fatalf("dispatched %d jobs, want 3", n)
+	}
+
+	if a := q.attempts[1]; a.Status != models.DeliveryDelivered || a.ResponseStatus != http.StatusNoContent || a.Error != "" {
+		t.Errorf("2xx: attempt = %+v, want delivered with 204", a)
+	}
+
+	a := q.attempts[2]
+	if a.Status != models.DeliveryPending || a.ResponseStatus != http.StatusServiceUnavailable || a.Error == "" {
+		t.Errorf("5xx: attempt = %+v, want pending with 503 and an error", a)
+	}
+	// Вторая неудача подряд — задержка 2 * BackoffBase.
+	if wait := a.NextAttemptAt.Sub(before); wait < 2*time.Minute || wait > 2*time.Minute+time.Second {
+		t.Errorf("5xx: next attempt in %v, want ~2m", wait)
+	}
+
+	if a := q.attempts[3]; a.Status != models.DeliveryFailed || !a.NextAttemptAt.IsZero() {
+		t.Errorf("last attempt: attempt = %+v, want failed without a next attempt", a)
+	}
+}
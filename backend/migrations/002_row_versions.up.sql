@@ -0,0 +1,10 @@
+-- Версии строк для оптимистической блокировки (ETag / If-Match).
+-- Каждое успешное обновление увеличивает version на единицу.
+
+ALTER TABLE news           ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE services       ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE fines          ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE traffic_lights ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE team           ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE projects       ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE vacancies      ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
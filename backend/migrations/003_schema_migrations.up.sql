@@ -0,0 +1,11 @@
+-- Учёт применённых миграций: /readyz сверяет максимальную версию
+-- с store.SchemaVersion, чтобы не пускать трафик на неподготовленную схему.
+-- Каждая следующая миграция добавляет сюда свою строку.
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schema_migrations (version) VALUES (1), (2), (3)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,7 @@
+-- Защита от перебора паролей: счётчик неудачных входов подряд
+-- и время, до которого учётная запись заблокирована.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_logins INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
+
+INSERT INTO schema_migrations (version) VALUES (4) ON CONFLICT (version) DO NOTHING;
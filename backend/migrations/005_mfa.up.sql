@@ -0,0 +1,27 @@
+-- Двухфакторная аутентификация (TOTP).
+-- Секрет хранится до подтверждения кода; totp_enabled включается только после него.
+-- Коды восстановления хранятся как SHA-256, каждый одноразовый.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret  TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Политика: для каких ролей 2FA обязательна.
+CREATE TABLE IF NOT EXISTS mfa_policy (
+    role       VARCHAR(20) PRIMARY KEY,
+    required   BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO mfa_policy (role, required) VALUES ('admin', false), ('editor', false)
+ON CONFLICT (role) DO NOTHING;
+
+INSERT INTO schema_migrations (version) VALUES (5) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,61 @@
+-- Матрица прав: роли, права и связь между ними.
+-- Вместо жёсткой проверки admin/editor маршруты требуют конкретное право.
+
+CREATE TABLE IF NOT EXISTS roles (
+    name        VARCHAR(20) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name        VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role       VARCHAR(20) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL REFERENCES permissions(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin',  'Администратор'),
+    ('editor', 'Редактор')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('dashboard:read',       'Чтение данных в админке'),
+    ('news:write',           'Новости'),
+    ('services:write',       'Услуги'),
+    ('team:write',           'Команда'),
+    ('projects:write',       'Проекты'),
+    ('vacancies:write',      'Вакансии'),
+    ('fines:write',          'Штрафы'),
+    ('evacuations:write',    'Эвакуация'),
+    ('traffic_lights:write', 'Светофоры'),
+    ('users:manage',         'Пользователи: блокировки'),
+    ('security:manage',      'Политика 2FA'),
+    ('roles:manage',         'Роли и права')
+ON CONFLICT (name) DO NOTHING;
+
+-- admin — все права; editor — работа с контентом и данными, как раньше.
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'editor', name FROM permissions
+WHERE name NOT IN ('users:manage', 'security:manage', 'roles:manage')
+ON CONFLICT DO NOTHING;
+
+-- Роль пользователя теперь любая из таблицы roles.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+ALTER TABLE mfa_policy DROP CONSTRAINT IF EXISTS mfa_policy_role_fkey;
+ALTER TABLE mfa_policy ADD CONSTRAINT mfa_policy_role_fkey
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+INSERT INTO schema_migrations (version) VALUES (6) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,10 @@
+-- Вход через OIDC-провайдер: пользователь привязан к паре (issuer, subject).
+-- У таких пользователей пароль пустой, поэтому войти по паролю они не могут.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_issuer  TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_oidc_identity_idx
+    ON users (oidc_issuer, oidc_subject) WHERE oidc_subject IS NOT NULL;
+
+INSERT INTO schema_migrations (version) VALUES (7) ON CONFLICT (version) DO NOTHING;
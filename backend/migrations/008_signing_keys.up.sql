@@ -0,0 +1,16 @@
+-- Ключи подписи JWT (RS256/EdDSA) с ротацией.
+-- Новый ключ публикуется в JWKS заранее (not_before в будущем) и начинает подписывать
+-- с not_before; предыдущий получает retired_at и остаётся в JWKS на время перекрытия.
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid         VARCHAR(64) PRIMARY KEY,
+    algorithm   VARCHAR(10) NOT NULL,
+    private_key BYTEA NOT NULL,
+    not_before  TIMESTAMP NOT NULL,
+    retired_at  TIMESTAMP NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS signing_keys_not_before_idx ON signing_keys (not_before DESC);
+
+INSERT INTO schema_migrations (version) VALUES (8) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,17 @@
+-- Сессии: каждый выданный токен доступа ссылается на строку здесь (claim sid).
+-- Отозванная сессия перестаёт приниматься сразу, не дожидаясь истечения токена.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id           VARCHAR(32) PRIMARY KEY,
+    user_id      INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip           VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent   VARCHAR(255) NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    expires_at   TIMESTAMP NOT NULL,
+    revoked_at   TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id, expires_at);
+
+INSERT INTO schema_migrations (version) VALUES (9) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,16 @@
+-- Одноразовые токены из писем: сброс пароля и приглашение нового сотрудника.
+-- Хранится только SHA-256 токена; использованный или истёкший токен не принимается.
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose    VARCHAR(20) NOT NULL CHECK (purpose IN ('password_reset', 'invite')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_idx ON user_tokens (user_id, purpose);
+
+INSERT INTO schema_migrations (version) VALUES (10) ON CONFLICT (version) DO NOTHING;
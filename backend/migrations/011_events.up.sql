@@ -0,0 +1,5 @@
+-- Номера событий /api/events: одна последовательность на все реплики,
+-- чтобы клиент мог продолжить поток по Last-Event-ID на любой из них.
+CREATE SEQUENCE IF NOT EXISTS events_id_seq;
+
+INSERT INTO schema_migrations (version) VALUES (11) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,44 @@
+-- Вебхуки: подписки партнёров на события и очередь доставок (она же журнал).
+-- Секрет хранится открыто: им подписывается каждое тело запроса (HMAC-SHA256).
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id          SERIAL PRIMARY KEY,
+    url         VARCHAR(500) NOT NULL,
+    secret      VARCHAR(100) NOT NULL,
+    events      TEXT[] NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id        BIGINT NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_attempt_at TIMESTAMP NULL,
+    response_status INTEGER NULL,
+    last_error      TEXT NOT NULL DEFAULT '',
+    replay_of       BIGINT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at     TIMESTAMP NULL
+);
+
+-- Очередь: ожидающие доставки по времени следующей попытки.
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+-- Журнал подписки: новые первыми.
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id DESC);
+
+INSERT INTO permissions (name, description) VALUES
+    ('webhooks:manage', 'Вебхуки для партнёров')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'webhooks:manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO schema_migrations (version) VALUES (12) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,16 @@
+-- Подписки жителей в Telegram-боте: новости (все или по тегу) и маршруты эвакуации.
+
+CREATE TABLE IF NOT EXISTS telegram_subscriptions (
+    chat_id    BIGINT NOT NULL,
+    kind       VARCHAR(10) NOT NULL CHECK (kind IN ('news', 'routes')),
+    -- Тег новостей; пустой — все новости (для routes всегда пустой).
+    tag        VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Теги сравниваются без учёта регистра: «Дороги» и «дороги» — одна подписка.
+CREATE UNIQUE INDEX IF NOT EXISTS telegram_subscriptions_uniq ON telegram_subscriptions (chat_id, kind, lower(tag));
+-- Рассылка: подписчики на вид и тег.
+CREATE INDEX IF NOT EXISTS telegram_subscriptions_kind_idx ON telegram_subscriptions (kind, lower(tag));
+
+INSERT INTO schema_migrations (version) VALUES (13) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,35 @@
+-- Обращения жителей (жалобы, предложения, вопросы) и их модерация.
+-- Обращение видно публично только после одобрения; контакт автора не публикуется никогда.
+
+CREATE TABLE IF NOT EXISTS feedback (
+    id            SERIAL PRIMARY KEY,
+    -- Номер для отслеживания статуса; случайный, чтобы по нему нельзя было перебрать чужие обращения.
+    ticket        VARCHAR(20) NOT NULL UNIQUE,
+    category      VARCHAR(20) NOT NULL CHECK (category IN ('complaint', 'suggestion', 'question')),
+    location      VARCHAR(255) NOT NULL DEFAULT '',
+    text          TEXT NOT NULL,
+    contact       VARCHAR(255) NOT NULL DEFAULT '',
+    status        VARCHAR(10) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'approved', 'rejected')),
+    reject_reason TEXT NOT NULL DEFAULT '',
+    moderated_by  INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+    moderated_at  TIMESTAMP NULL,
+    response      TEXT NOT NULL DEFAULT '',
+    responded_by  INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+    responded_at  TIMESTAMP NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Очередь модерации и публичная лента: по статусу, новые первыми.
+CREATE INDEX IF NOT EXISTS feedback_status_idx ON feedback (status, created_at DESC);
+
+INSERT INTO permissions (name, description) VALUES
+    ('feedback:moderate', 'Модерация обращений')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin',  'feedback:moderate'),
+    ('editor', 'feedback:moderate')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO schema_migrations (version) VALUES (14) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,35 @@
+-- Заказы платных услуг от жителей.
+-- Название, категория и цена услуги копируются в заказ: правка или удаление услуги
+-- в каталоге не меняет уже оформленные заказы и итоги по ним.
+
+CREATE TABLE IF NOT EXISTS service_orders (
+    id               SERIAL PRIMARY KEY,
+    -- Номер для отслеживания статуса; случайный, как у обращений.
+    number           VARCHAR(20) NOT NULL UNIQUE,
+    service_id       INTEGER NULL REFERENCES services(id) ON DELETE SET NULL,
+    service_title    VARCHAR(255) NOT NULL,
+    service_category VARCHAR(100) NOT NULL,
+    price            INTEGER NOT NULL CHECK (price >= 0),
+    customer_name    VARCHAR(255) NOT NULL,
+    phone            VARCHAR(50) NOT NULL,
+    email            VARCHAR(255) NOT NULL DEFAULT '',
+    comment          TEXT NOT NULL DEFAULT '',
+    status           VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'done', 'cancelled')),
+    assigned_to      INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Список в админке: фильтр по статусу, новые первыми.
+CREATE INDEX IF NOT EXISTS service_orders_status_idx ON service_orders (status, created_at DESC);
+CREATE INDEX IF NOT EXISTS service_orders_assigned_idx ON service_orders (assigned_to) WHERE assigned_to IS NOT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('orders:manage', 'Заказы платных услуг')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'orders:manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO schema_migrations (version) VALUES (15) ON CONFLICT (version) DO NOTHING;
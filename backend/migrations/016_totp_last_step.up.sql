@@ -0,0 +1,6 @@
+-- Защита от повторного использования кода TOTP: номер последнего принятого
+-- 30-секундного шага. Код принимается, только если его шаг больше сохранённого.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+INSERT INTO schema_migrations (version) VALUES (16) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,10 @@
+// Package migrations — SQL-миграции схемы, встроенные в бинарник: их применяет
+// store.Migrate при старте сервера или командой `server migrate`.
+package migrations
+
+import "embed"
+
+// FS — файлы NNN_name.up.sql.
+//
+//go:embed *.up.sql
+var FS embed.FS